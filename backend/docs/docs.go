@@ -28,9 +28,51 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/auth/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Shows whether two-factor authentication is enabled, and how many recovery codes are left.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Shows my two-factor authentication.",
+                "responses": {
+                    "200": {
+                        "description": "Status",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/confirm": {
             "post": {
-                "description": "Logins to an account using a username and a password registered with the server.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confirms the secret from setting up with a code from the authenticator. The recovery codes are only shown once. Access tokens are revoked so roles that require two-factor authentication can be added.",
                 "consumes": [
                     "application/json"
                 ],
@@ -40,57 +82,45 @@ const docTemplate = `{
                 "tags": [
                     "authentication"
                 ],
-                "summary": "Logins to an existing account",
+                "summary": "Enables two-factor authentication.",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "credentials",
+                        "description": "Code from the authenticator",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginRequest"
+                            "$ref": "#/definitions/auth.TwoFactorConfirmRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful",
+                        "description": "Enabled, with recovery codes",
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginResponse"
+                            "$ref": "#/definitions/auth.RecoveryCodesResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad username or password format",
+                        "description": "Invalid body, wrong code or not set up",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Wrong password",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Account is not verified",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Account does not exist",
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "421": {
-                        "description": "Account uses oauth but tries to login with password",
+                    "409": {
+                        "description": "Already enabled",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Server couldn't complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -98,49 +128,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
+        "/auth/2fa/disable": {
             "post": {
-                "description": "Logouts, and also invalidates the refresh token. This does not revoke access tokens.",
-                "tags": [
-                    "authentication"
-                ],
-                "summary": "Logouts and invalidates the refresh token if available.",
-                "responses": {
-                    "204": {
-                        "description": "Any request, regardless of authentication status",
-                        "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
-                        }
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     }
-                }
-            }
-        },
-        "/auth/refresh": {
-            "post": {
-                "description": "Uses the provided refresh token cookie to refresh on another short-lived access token.",
-                "tags": [
-                    "authentication"
                 ],
-                "summary": "Refreshs a JWT key pair.",
-                "responses": {
-                    "200": {
-                        "description": "Refreshed successfully",
-                        "schema": {
-                            "$ref": "#/definitions/auth.LoginResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Did not attach refresh token",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Registers a new account with the system using a email-password pair.",
+                "description": "Turns off two-factor authentication and deletes the recovery codes. Takes a code from the authenticator or a recovery code.",
                 "consumes": [
                     "application/json"
                 ],
@@ -150,45 +145,45 @@ const docTemplate = `{
                 "tags": [
                     "authentication"
                 ],
-                "summary": "Registers a new account",
+                "summary": "Disables two-factor authentication.",
                 "parameters": [
                     {
-                        "description": "Register credentials",
-                        "name": "credentials",
+                        "description": "Code",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.RegisterRequest"
+                            "$ref": "#/definitions/auth.TwoFactorCodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User was successfully registered",
+                    "200": {
+                        "description": "Disabled",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Request body is invalid",
+                        "description": "Invalid body or wrong code",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Captcha failed",
+                    "401": {
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "An account with that email already exists",
+                        "description": "Not enabled",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -196,116 +191,78 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/verify": {
+        "/auth/2fa/login": {
             "post": {
-                "description": "Requests the server to send an OTP code for verification.",
+                "description": "Answers the challenge from logging in with a code from the authenticator, or a recovery code. A challenge takes at most 5 codes, and wrong codes across challenges lock the account out of two-factor logins for a while.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "authentication"
                 ],
-                "summary": "Requests an OTP code for verification.",
-                "responses": {
-                    "200": {
-                        "description": "An OTP code has been sent",
-                        "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
-                        }
-                    },
-                    "204": {
-                        "description": "Already verified",
-                        "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "User is unauthenticated",
-                        "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                "summary": "Finishes logging in with two-factor authentication.",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/auth.TwoFactorLoginRequest"
                         }
                     }
-                }
-            }
-        },
-        "/auth/verify/check": {
-            "post": {
-                "description": "Verifies a user's OTP code.",
-                "tags": [
-                    "authentication"
                 ],
-                "summary": "Verifies an OTP code.",
                 "responses": {
                     "200": {
-                        "description": "Verification successfully",
+                        "description": "Login successful",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Failed to verify",
+                        "description": "Invalid body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Not logged in",
+                        "description": "Wrong code, or invalid or expired challenge",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "422": {
-                        "description": "Token is valid but user does not exist",
+                    "403": {
+                        "description": "Account is suspended",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/auth.SuspendedResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "429": {
+                        "description": "Too many wrong codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/categories": {
-            "get": {
-                "description": "This endpoint retrieves all categories in a fully recursive nested list, for easily displaying on the frontend.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "categories"
-                ],
-                "summary": "Retrieves a recursively nested list of categories.",
-                "responses": {
-                    "200": {
-                        "description": "List was successfully fetched",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/categories.CategoryDTO"
-                            }
-                        }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/recovery-codes": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Generates new recovery codes, the old ones stop working. Takes a code from the authenticator or a recovery code.",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,47 +270,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "authentication"
                 ],
-                "summary": "Creates a category",
+                "summary": "Replaces my recovery codes.",
                 "parameters": [
                     {
-                        "description": "New category data",
-                        "name": "data",
+                        "description": "Code",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/categories.PostCategoryBody"
+                            "$ref": "#/definitions/auth.TwoFactorCodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Category was successfully created",
+                    "200": {
+                        "description": "New recovery codes",
                         "schema": {
-                            "$ref": "#/definitions/categories.CategoryDTO"
+                            "$ref": "#/definitions/auth.RecoveryCodesResponse"
                         }
                     },
                     "400": {
-                        "description": "Category body data was invalid",
+                        "description": "Invalid body or wrong code",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Not enough permissions",
+                    "409": {
+                        "description": "Not enabled",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -361,14 +318,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories/{id}": {
+        "/auth/2fa/roles/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a category records by updating it partially",
+                "description": "Changes whether a role requires two-factor authentication. Users without it lose the role from their access tokens until they enable it. The admin has to have it enabled first, and every permission of the role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -376,121 +333,135 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "authentication"
                 ],
-                "summary": "Update a category record",
+                "summary": "Requires two-factor authentication for a role.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Category ID",
+                        "type": "string",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "New updated category data",
-                        "name": "data",
+                        "description": "Whether it's required",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/categories.PutCategoryBody"
+                            "$ref": "#/definitions/auth.PutRoleTwoFactorRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Category was successfully changed, returns old category",
+                        "description": "Changed",
                         "schema": {
-                            "$ref": "#/definitions/categories.CategoryDTO"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Category body data was invalid",
+                        "description": "Invalid body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Not enough permissions",
+                        "description": "Missing the roles.write permission, or a permission of the role",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Category could not be found",
+                        "description": "Role doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The admin doesn't have two-factor authentication",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/2fa/setup": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Soft-deletes a category record by setting a flag.",
+                "description": "Creates a new TOTP secret to add to an authenticator, which replaces any unconfirmed one. It isn't asked for until confirmed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
-                ],
-                "summary": "Soft-delete a category record",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "authentication"
                 ],
+                "summary": "Sets up two-factor authentication.",
                 "responses": {
-                    "204": {
-                        "description": "Category was deleted"
+                    "200": {
+                        "description": "Secret and otpauth URI",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorSetupResponse"
+                        }
                     },
-                    "400": {
-                        "description": "Category body data was invalid",
+                    "401": {
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Already enabled",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Not enough permissions",
+                    "500": {
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/auth/google": {
+            "get": {
+                "description": "Redirects to Google to sign in. Google redirects back to the frontend, which finishes with the callback endpoint.",
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Starts signing in with Google.",
+                "responses": {
+                    "302": {
+                        "description": "Found"
                     },
                     "404": {
-                        "description": "Category could not be found",
+                        "description": "Sign in with Google isn't configured",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -498,69 +469,91 @@ const docTemplate = `{
                 }
             }
         },
-        "/chat": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/auth/google/callback": {
+            "post": {
+                "description": "Exchanges the code Google redirected back with. Accounts are linked by their verified email, or registered if there's none.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Gets all users chat sessions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "chat"
+                    "authentication"
                 ],
-                "summary": "Gets all chat sessions.",
+                "summary": "Finishes signing in with Google.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per Page",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Code and state from the redirect",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.GoogleCallbackRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful query",
+                        "description": "Login successful",
                         "schema": {
-                            "$ref": "#/definitions/chat.ChatSessionResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorChallengeResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid body or state",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Google refused the code or the ID token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The Google account has no verified email, or the account is suspended",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Sign in with Google isn't configured",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The account is linked to another Google account",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong two-factor codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/login": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Creates a new chat session for a product, only callable by the seller or the winner.",
+                "description": "Logins to an account using a username and a password registered with the server. Accounts with two-factor authentication get a challenge to answer at /auth/2fa/login instead.",
                 "consumes": [
                     "application/json"
                 ],
@@ -568,53 +561,71 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "chat"
+                    "authentication"
                 ],
-                "summary": "Creates a new chat session for a product.",
+                "summary": "Logins to an existing account",
                 "parameters": [
                     {
-                        "description": "Body",
-                        "name": "body",
+                        "description": "Login credentials",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/chat.CreateChatSessionRequest"
+                            "$ref": "#/definitions/auth.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created chat session",
+                    "200": {
+                        "description": "Login successful",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Two-factor authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorChallengeResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad username or password format",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Wrong password",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "No authority to create session for the product",
+                        "description": "Account is not verified, or is suspended",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Failed to create chat session",
+                    "404": {
+                        "description": "Account does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "421": {
+                        "description": "Account uses oauth but tries to login with password",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins for the account or from the IP, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "Server couldn't complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -622,152 +633,136 @@ const docTemplate = `{
                 }
             }
         },
-        "/chat/stream": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Establishes a persistent HTTP connection to receive real-time messages and receipts via Server-Sent Events.",
-                "produces": [
-                    "text/event-stream"
-                ],
+        "/auth/logout": {
+            "post": {
+                "description": "Logouts, and also invalidates the refresh token and the access tokens of its session.",
                 "tags": [
-                    "chat"
+                    "authentication"
                 ],
-                "summary": "Opens a SSE stream to the chat channel",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Authentication token",
-                        "name": "token",
-                        "in": "query",
-                        "required": true
+                "summary": "Logouts and invalidates the refresh token if available.",
+                "responses": {
+                    "204": {
+                        "description": "Any request, regardless of authentication status",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
                     }
-                ],
-                "responses": {}
+                }
             }
         },
-        "/chat/{id}": {
+        "/auth/passkeys": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieves a list of chat messages in a channel for history reasons.",
+                "description": "Lists the passkeys of the current user, newest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "chat"
-                ],
-                "summary": "Retrieves a list of chat messages in a channel",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Channel ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per Page",
-                        "name": "per_page",
-                        "in": "query"
-                    }
+                    "authentication"
                 ],
+                "summary": "Lists my passkeys.",
                 "responses": {
                     "200": {
-                        "description": "Successful",
+                        "description": "Passkeys",
                         "schema": {
-                            "$ref": "#/definitions/chat.ChatMessageResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.PasskeyDTO"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "401": {
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
-                    },
-                    "403": {
-                        "description": "Not a chat participant",
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/login/begin": {
+            "post": {
+                "description": "Creates the options for navigator.credentials.get, with binary fields base64url encoded. The challenge is valid for 5 minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Starts logging in with a passkey.",
+                "responses": {
+                    "200": {
+                        "description": "Request options",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/services.PasskeyRequestOptions"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/passkeys/login/finish": {
             "post": {
-                "description": "Posts a chat message to a channel the user may access.",
+                "description": "Verifies the response of navigator.credentials.get and logs in like the password does. Passkeys verify the user themselves, so two-factor authentication isn't asked for.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "chat"
+                    "authentication"
                 ],
-                "summary": "Posts a chat message to a channel.",
+                "summary": "Logs in with a passkey.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Channel ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Credential",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PasskeyLoginRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully posted a message to a channel",
+                    "200": {
+                        "description": "Login successful",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad channel ID, or invalid body",
+                        "description": "Invalid body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid response, challenge, signature or passkey",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "Unknown channel ID",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "413": {
-                        "description": "Image too heavy",
+                        "description": "Account is suspended",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/auth.SuspendedResponse"
                         }
                     },
                     "500": {
@@ -779,217 +774,99 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
-            "get": {
+        "/auth/passkeys/register/begin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates the options for navigator.credentials.create, with binary fields base64url encoded. The challenge is valid for 5 minutes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "others"
+                    "authentication"
                 ],
-                "summary": "Checks health of the server.",
+                "summary": "Starts adding a passkey.",
                 "responses": {
                     "200": {
-                        "description": "Always",
+                        "description": "Creation options",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/services.PasskeyCreationOptions"
                         }
-                    }
-                }
-            }
-        },
-        "/products": {
-            "get": {
-                "description": "Queries from a list of products using a set of keywords, using Full-text Queries or Fuzzy and Similarity queries.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "products"
-                ],
-                "summary": "Queries products using fuzzy matchers and full-text matchers.",
-                "parameters": [
-                    {
-                        "type": "array",
-                        "description": "Search Categories",
-                        "name": "category",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort Type",
-                        "name": "sort",
-                        "in": "query"
                     },
-                    {
-                        "type": "boolean",
-                        "description": "Sort Direction",
-                        "name": "asc",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Search Query",
-                        "name": "query",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per Page",
-                        "name": "per_page",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "401": {
+                        "description": "Unauthenticated",
                         "schema": {
-                            "$ref": "#/definitions/products.GetProductsResponse"
+                            "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/passkeys/register/finish": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Posts a new product up for auctions.",
+                "description": "Verifies the response of navigator.credentials.create and stores the passkey. Only the \"none\" attestation is accepted, and the user has to be verified by the authenticator.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "authentication"
                 ],
-                "summary": "Posts a new product.",
+                "summary": "Adds a passkey.",
                 "parameters": [
                     {
-                        "minLength": 2,
-                        "type": "string",
-                        "description": "Product Name",
-                        "name": "name",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "minLength": 50,
-                        "type": "string",
-                        "description": "Product Description",
-                        "name": "description",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Initial bid amount",
-                        "name": "starting_bid",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "integer"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "Array of category IDs",
-                        "name": "categories",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Product images to upload",
-                        "name": "product_images",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Minimum bid increment",
-                        "name": "step_bid_value",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "Buy It Now price",
-                        "name": "bin_price",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Allow unrated users to bid",
-                        "name": "allows_unrated",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Extend auction on late bids",
-                        "name": "auto_extends",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Expiration date (RFC3339)",
-                        "name": "expired_at",
-                        "in": "formData",
-                        "required": true
+                        "description": "Name and credential",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PasskeyRegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created an auction",
+                        "description": "Added passkey",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/auth.PasskeyDTO"
                         }
                     },
                     "400": {
-                        "description": "When the multipart data is invalid",
+                        "description": "Invalid body, response or challenge",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When the user is unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "When the user isn't subscribed",
+                        "description": "Unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "The server couldn't find the requested product",
+                    "409": {
+                        "description": "Passkey is already registered",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -997,97 +874,109 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/favorite": {
-            "get": {
+        "/auth/passkeys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Queries on my favorite products,",
+                "description": "Removes a passkey of the current user, so it can't log in anymore.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "authentication"
                 ],
-                "summary": "Retrieves my favorite products.",
+                "summary": "Removes a passkey.",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search Query",
-                        "name": "query",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per Page",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Removed",
                         "schema": {
-                            "$ref": "#/definitions/products.GetProductsResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When unauthenticated",
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such passkey",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/password/forgot": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+                "description": "Emails a single-use link to reset the password, if the email belongs to an account with a password. The response is the same either way.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Mark a product as my favorite, if it is not a favorite, otherwise, unmark it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "authentication"
+                ],
+                "summary": "Requests a password reset.",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordRequest"
+                        }
+                    }
                 ],
-                "summary": "Toggle a product as favorite.",
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "202": {
+                        "description": "Reset email sent if the account exists",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
-                    "401": {
-                        "description": "When unauthenticated",
+                    "400": {
+                        "description": "Invalid email",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Product could not be found",
+                    "429": {
+                        "description": "Too many requests for this email or IP",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1095,25 +984,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/top": {
-            "get": {
-                "description": "Queriee top-products via some metrics, instead of a pure query like /products.",
+        "/auth/password/reset": {
+            "post": {
+                "description": "Sets a new password using the token from a reset email. Every session of the account is logged out.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "authentication"
+                ],
+                "summary": "Resets a password.",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordRequest"
+                        }
+                    }
                 ],
-                "summary": "Retrieves a list of products of top-categories.",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Password was reset",
                         "schema": {
-                            "$ref": "#/definitions/products.GetTopProductsResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, or invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1121,55 +1030,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}": {
-            "get": {
-                "description": "Queries everything about a single product, with full joins.",
-                "produces": [
-                    "application/json"
-                ],
+        "/auth/refresh": {
+            "post": {
+                "description": "Uses the provided refresh token cookie to refresh on another short-lived access token.",
                 "tags": [
-                    "products"
-                ],
-                "summary": "Retrieves details on a single product.",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Product ID to view",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "authentication"
                 ],
+                "summary": "Refreshs a JWT key pair.",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Refreshed successfully",
                         "schema": {
-                            "$ref": "#/definitions/products.GetProductDetailsResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
                         }
                     },
-                    "404": {
-                        "description": "The server couldn't find the requested product",
+                    "401": {
+                        "description": "Did not attach refresh token",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "The server could not make the request",
+                    "403": {
+                        "description": "Account is suspended",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/auth.SuspendedResponse"
                         }
                     }
                 }
             }
         },
-        "/products/{id}/autobids": {
+        "/auth/register": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Bids on a product, hopefully with some race-condition management.",
+                "description": "Registers a new account with the system using a email-password pair.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1177,53 +1069,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "authentication"
                 ],
-                "summary": "Makes an automated bid on a product.",
+                "summary": "Registers a new account",
                 "parameters": [
                     {
-                        "description": "Bid body",
-                        "name": "body",
+                        "description": "Register credentials",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.PostBidBody"
+                            "$ref": "#/definitions/auth.RegisterRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successful bid",
+                        "description": "User was successfully registered",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "User is unauthenticated",
+                        "description": "Request body is invalid",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "User can not bid for other reasons",
+                        "description": "Captcha failed",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Race condition, and you lost",
+                        "description": "An account with that email already exists",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Server could not finish the request",
+                        "description": "The request could not be completed due to server faults",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1231,81 +1117,122 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}/bids": {
+        "/auth/verify": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Bids on a product, hopefully with some race-condition management.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Requests the server to send an OTP code for verification.",
                 "tags": [
-                    "products"
+                    "authentication"
                 ],
-                "summary": "Creates a bid on a product",
-                "parameters": [
-                    {
-                        "description": "Bid body",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                "summary": "Requests an OTP code for verification.",
+                "responses": {
+                    "200": {
+                        "description": "An OTP code has been sent",
                         "schema": {
-                            "$ref": "#/definitions/products.PostBidBody"
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "Already verified",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     }
+                }
+            }
+        },
+        "/auth/verify/check": {
+            "post": {
+                "description": "Verifies a user's OTP code. An OTP only takes 5 wrong codes before a new one has to be sent, and too many wrong codes lock verifying out for a while.",
+                "tags": [
+                    "authentication"
                 ],
+                "summary": "Verifies an OTP code.",
                 "responses": {
-                    "201": {
-                        "description": "Successful bid",
+                    "200": {
+                        "description": "Verification successfully",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Failed to verify",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "User is unauthenticated",
+                        "description": "Not logged in",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "User can not bid for other reasons",
+                    "422": {
+                        "description": "Token is valid but user does not exist",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Race condition, and you lost",
+                    "429": {
+                        "description": "Too many wrong codes, see Retry-After",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Server could not finish the request",
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "This endpoint retrieves all categories in a fully recursive nested list, for easily displaying on the frontend.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Retrieves a recursively nested list of categories.",
+                "responses": {
+                    "200": {
+                        "description": "List was successfully fetched",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/categories.CategoryDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Removes all bidders from a product.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1313,36 +1240,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
+                ],
+                "summary": "Creates a category",
+                "parameters": [
+                    {
+                        "description": "New category data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/categories.PostCategoryBody"
+                        }
+                    }
                 ],
-                "summary": "Clears all bids on a product.",
                 "responses": {
-                    "204": {
-                        "description": "Successful removal",
+                    "201": {
+                        "description": "Category was successfully created",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/categories.CategoryDTO"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Category body data was invalid",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "User is unauthenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "User is not the seller",
+                        "description": "Not enough permissions",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Server could not finish the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1350,14 +1288,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}/bin": {
-            "post": {
+        "/categories/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Make a BIN bid to a product and end it immediately.",
+                "description": "Update a category records by updating it partially",
                 "consumes": [
                     "application/json"
                 ],
@@ -1365,106 +1303,121 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
+                ],
+                "summary": "Update a category record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New updated category data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/categories.PutCategoryBody"
+                        }
+                    }
                 ],
-                "summary": "Make a BIN purchase.",
                 "responses": {
                     "200": {
-                        "description": "Successful bid",
+                        "description": "Category was successfully changed, returns old category",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/categories.CategoryDTO"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Category body data was invalid",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "User is unauthenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "User is not the seller",
+                        "description": "Not enough permissions",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category could not be found",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Server could not finish the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}/denials": {
-            "post": {
-                "description": "Deny a bidder from a current product.",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Soft-deletes a category record by setting a flag.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Deny a bidder.",
+                "summary": "Soft-delete a category record",
                 "parameters": [
                     {
-                        "description": "Body",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/products.PostDenyBidderBody"
-                        }
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully denied bidder",
-                        "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
-                        }
+                    "204": {
+                        "description": "Category was deleted"
                     },
                     "400": {
-                        "description": "Invalid bidder user ID",
+                        "description": "Category body data was invalid",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "User is unauthorized or not verified",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "User is not seller, or denied bidder id is the seller",
+                        "description": "Not enough permissions",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Product is not found",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Bidder is already denied",
+                        "description": "Category could not be found",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server couldn't complete the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1472,84 +1425,69 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}/description": {
-            "post": {
+        "/chat": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "The description can't be changed entirely, but there will be a post-script remark added to it.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Gets all users chat sessions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "chat"
                 ],
-                "summary": "Posts a new product's description change.",
+                "summary": "Gets all chat sessions.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "New product description body",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/products.PostProductDescriptionBody"
-                        }
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully added a postscript remark",
+                    "200": {
+                        "description": "Successful query",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/chat.ChatSessionResponse"
                         }
                     },
                     "400": {
-                        "description": "When the request is invalid",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When the user is unauthenticated",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "When the user can't edit a product or the product does not exist",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/questions": {
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a new question to a product.",
+                "description": "Creates a new chat session for a product, only callable by the seller or the winner.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1557,47 +1495,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "chat"
                 ],
-                "summary": "Posts a question to a product.",
+                "summary": "Creates a new chat session for a product.",
                 "parameters": [
                     {
-                        "description": "New question body",
-                        "name": "data",
+                        "description": "Body",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/questions.PostQuestionBody"
+                            "$ref": "#/definitions/chat.CreateChatSessionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Added a new question",
+                        "description": "Successfully created chat session",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "When the request is invalid",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When the user is unauthenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "When the user is the seller or the product is expired",
+                        "description": "No authority to create session for the product",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Failed to create chat session",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1605,109 +1549,132 @@ const docTemplate = `{
                 }
             }
         },
-        "/questions/{id}": {
-            "put": {
+        "/chat/stream": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Answers a question to the product.",
-                "consumes": [
-                    "application/json"
+                "description": "Establishes a persistent HTTP connection to receive real-time messages and receipts via Server-Sent Events.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Opens a SSE stream to the chat channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authentication token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/chat/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Retrieves a list of chat messages in a channel for history reasons.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "questions"
+                    "chat"
                 ],
-                "summary": "Answers a question.",
+                "summary": "Retrieves a list of chat messages in a channel",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Question ID",
+                        "description": "Channel ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Question Body",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/questions.PutQuestionBody"
-                        }
+                        "type": "integer",
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Added an answer",
+                        "description": "Successful",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/chat.ChatMessageResponse"
                         }
                     },
                     "400": {
-                        "description": "When the request is invalid",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When the user is unauthenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "403": {
-                        "description": "When the user isn't the seller or the product is expired",
+                        "description": "Not a chat participant",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not make the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/ratings": {
+            },
             "post": {
-                "description": "Posts a rating and feedback onto a users profile.",
+                "description": "Posts a chat message to a channel the user may access.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "chat"
                 ],
-                "summary": "Creates a rating.",
+                "summary": "Posts a chat message to a channel.",
                 "parameters": [
                     {
-                        "description": "Rating context",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ratings.PostRatingBody"
-                        }
+                        "type": "integer",
+                        "description": "Channel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully rated person",
+                        "description": "Successfully posted a message to a channel",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid reviewee ID or invalid format",
+                        "description": "Bad channel ID, or invalid body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1718,6 +1685,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Unknown channel ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Image too heavy",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "The server failed to complete the request",
                         "schema": {
@@ -1727,46 +1706,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/ratings/{id}": {
-            "put": {
-                "description": "Edits a rating and feedback onto a users profile.",
-                "consumes": [
-                    "application/json"
+        "/disputes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Gets disputes by status for admins to arbitrate, oldest first. Defaults to open disputes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "disputes"
                 ],
-                "summary": "Edits a rating.",
+                "summary": "Gets the dispute queue.",
                 "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Dispute status",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Rating ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Rating context",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ratings.PutRatingBody"
-                        }
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully edited rating",
+                        "description": "Successful query",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/disputes.DisputesResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid reviewee ID or invalid format",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1778,52 +1765,56 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Not your rating",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Rating ID not found",
+                        "description": "Missing the disputes.resolve permission",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server failed to complete the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes a rating.",
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lets the buyer or the seller dispute a paid or delivered transaction. The transaction is frozen until an admin resolves the dispute.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "disputes"
                 ],
-                "summary": "Deletes a rating.",
+                "summary": "Opens a dispute on a transaction.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Rating ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/disputes.PostDisputeRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully deleted rating",
+                    "201": {
+                        "description": "Successfully opened a dispute",
                         "schema": {
-                            "$ref": "#/definitions/shared.MessageResponse"
+                            "$ref": "#/definitions/shared.IDResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid reviewee ID or invalid format",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1835,19 +1826,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Not your rating",
+                        "description": "Not a party of the transaction, or it can't be disputed at this stage",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Rating ID not found",
+                        "description": "Unknown transaction ID",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server failed to complete the request",
+                        "description": "The server could not complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1855,44 +1846,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions": {
-            "post": {
+        "/disputes/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a transaction to link it with a product.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Gets a dispute with all of its statements. Only visible to the parties of the transaction and admins. Links to evidence expire after 15 minutes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "disputes"
                 ],
-                "summary": "Creates a transaction.",
+                "summary": "Gets a dispute.",
                 "parameters": [
                     {
-                        "description": "Transaction data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/transactions.PostTransactionRequest"
-                        }
+                        "type": "integer",
+                        "description": "Dispute ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created a transaction",
+                    "200": {
+                        "description": "Successful query",
                         "schema": {
-                            "$ref": "#/definitions/shared.IDResponse"
+                            "$ref": "#/definitions/disputes.DisputeDTO"
                         }
                     },
                     "400": {
-                        "description": "Invalid body",
+                        "description": "Invalid ID",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1904,19 +1890,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Only seller can create this transaction",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Already created the transaction for that product",
+                        "description": "Not a party of the dispute",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "The server failed to complete the request",
+                    "404": {
+                        "description": "Unknown dispute ID",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1924,14 +1904,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/{id}": {
-            "get": {
+        "/disputes/{id}/resolve": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets a transaction status",
+                "description": "Rules a dispute in favour of one side. Ruling for the seller completes the transaction, ruling for the buyer cancels and refunds it. The losing side gets a negative rating.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1939,27 +1919,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "disputes"
                 ],
-                "summary": "Gets a transaction status.",
+                "summary": "Resolves a dispute.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Dispute ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Ruling",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/disputes.PostResolveRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully queried",
+                        "description": "Successfully resolved the dispute",
                         "schema": {
-                            "$ref": "#/definitions/transactions.TransactionStatusResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid body",
+                        "description": "Invalid ID or body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1971,13 +1960,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Only seller can create this transaction",
+                        "description": "Missing the disputes.resolve permission",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Unknown transaction ID",
+                        "description": "Unknown dispute ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Dispute is already resolved",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -1989,51 +1984,57 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/disputes/{id}/statements": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a transaction status.",
+                "description": "Adds a statement to an open dispute, optionally with an image or PDF attached as evidence.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "disputes"
                 ],
-                "summary": "Updates a transaction.",
+                "summary": "Adds a statement to a dispute.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Dispute ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Transaction data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/transactions.PutTransactionRequest"
-                        }
+                        "type": "string",
+                        "description": "Statement",
+                        "name": "content",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Evidence, up to 10MB (JPEG, PNG, WebP or PDF)",
+                        "name": "attachment",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Successfully created a transaction",
+                        "description": "Successfully added a statement",
                         "schema": {
                             "$ref": "#/definitions/shared.IDResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid body",
+                        "description": "Invalid ID, body or attachment format",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2045,13 +2046,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Only seller can create this transaction",
+                        "description": "Not a party of the dispute, or the dispute is resolved",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Unknown transaction ID",
+                        "description": "Unknown dispute ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Attachment too heavy",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2065,79 +2072,94 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/health": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieves all users.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "others"
                 ],
-                "summary": "Retrieves all users",
+                "summary": "Checks health of the server.",
                 "responses": {
                     "200": {
-                        "description": "All users",
-                        "schema": {
-                            "$ref": "#/definitions/users.GetUsersResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "When unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "Always",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     }
                 }
             }
         },
-        "/users/approve": {
+        "/payments/mock/{reference}": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Approves seller privileges",
+                "description": "Only available with the mock payment provider. Simulates the buyer paying (or failing to pay), and delivers a signed webhook for it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "payments"
+                ],
+                "summary": "Completes a payment on the mock gateway.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment reference",
+                        "name": "reference",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outcome of the payment",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.PostMockCheckoutRequest"
+                        }
+                    }
                 ],
-                "summary": "Approves seller privileges",
                 "responses": {
-                    "204": {
-                        "description": "When success",
+                    "200": {
+                        "description": "Event was processed",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid body",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When unauthenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the buyer of the transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mock gateway disabled or unknown payment reference",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2145,61 +2167,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/avatar": {
+        "/payments/webhook": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Uploads and changes my avatar.",
+                "description": "Receives a signed event from the payment provider. Confirmed payments move the transaction to paid. Retried events are acknowledged without being applied twice.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "payments"
                 ],
-                "summary": "Changes my avatar.",
+                "summary": "Receives payment provider webhooks.",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "account image",
-                        "name": "avatar",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Webhook signature",
+                        "name": "X-Payment-Signature",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "When success",
+                        "description": "Event was processed, or was already processed",
                         "schema": {
-                            "$ref": "#/definitions/users.PostAvatarResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "When unauthenticated",
+                        "description": "Invalid signature or payload",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "413": {
-                        "description": "When the image is too big",
+                    "404": {
+                        "description": "Unknown payment reference",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server failed to complete the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2207,97 +2217,198 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
+        "/products": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieves information about your own profile if authenticated.",
+                "description": "Queries from a list of products using a set of keywords, using Full-text Queries or Fuzzy and Similarity queries.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
+                ],
+                "summary": "Queries products using fuzzy matchers and full-text matchers.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "description": "Search Categories",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort Type",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Sort Direction",
+                        "name": "asc",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search Query",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Gets your own profile.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/users.UserDTO"
-                        }
-                    },
-                    "401": {
-                        "description": "When unauthenticated",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "When your info had an invalid state on the server",
-                        "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/products.GetProductsResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server could not make the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates the current authenticated user's profile",
+                "description": "Posts a new product up for auctions.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Updates your user profile",
+                "summary": "Posts a new product.",
                 "parameters": [
                     {
-                        "description": "Profile data",
-                        "name": "profile",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/users.PostProfileRequest"
-                        }
+                        "minLength": 2,
+                        "type": "string",
+                        "description": "Product Name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "minLength": 50,
+                        "type": "string",
+                        "description": "Product Description",
+                        "name": "description",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Initial bid amount",
+                        "name": "starting_bid",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Array of category IDs",
+                        "name": "categories",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Product images to upload",
+                        "name": "product_images",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Minimum bid increment",
+                        "name": "step_bid_value",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Buy It Now price",
+                        "name": "bin_price",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow unrated users to bid",
+                        "name": "allows_unrated",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Extend auction on late bids",
+                        "name": "auto_extends",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Expiration date (RFC3339)",
+                        "name": "expired_at",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "When successfully changed",
+                    "201": {
+                        "description": "Successfully created an auction",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid body",
+                        "description": "When the multipart data is invalid",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "When unauthorized",
+                        "description": "When the user is unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "When the user isn't subscribed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The server couldn't find the requested product",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server could not make the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2305,22 +2416,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me/bids": {
+        "/products/favorite": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieves information about your ongoing bids.",
+                "description": "Queries on my favorite products,",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Gets my own bids.",
+                "summary": "Retrieves my favorite products.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search Query",
+                        "name": "query",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page Number",
@@ -2332,19 +2449,13 @@ const docTemplate = `{
                         "description": "Items per Page",
                         "name": "per_page",
                         "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "What type of bids to include, active/ended?",
-                        "name": "status",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/users.GetProductsResponse"
+                            "$ref": "#/definitions/products.GetProductsResponse"
                         }
                     },
                     "401": {
@@ -2354,76 +2465,74 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "The server could not make the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/users/me/password": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates the current authenticated user's profile password",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mark a product as my favorite, if it is not a favorite, otherwise, unmark it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Updates your profile password",
-                "parameters": [
-                    {
-                        "description": "New password data",
-                        "name": "profile",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/users.PutPasswordRequest"
-                        }
-                    }
+                    "products"
                 ],
+                "summary": "Toggle a product as favorite.",
                 "responses": {
-                    "200": {
-                        "description": "When successfully changed",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid body",
+                    "401": {
+                        "description": "When unauthenticated",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "When unauthorized",
+                    "404": {
+                        "description": "Product could not be found",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Incorrect current password",
+                    "500": {
+                        "description": "The server could not make the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
-                    },
-                    "421": {
-                        "description": "Account uses OAuth",
+                    }
+                }
+            }
+        },
+        "/products/top": {
+            "get": {
+                "description": "Queriee top-products via some metrics, instead of a pure query like /products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Retrieves a list of products of top-categories.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/products.GetTopProductsResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "The server could not make the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2431,56 +2540,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me/products": {
+        "/products/{id}": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieves my products, paginated.",
+                "description": "Queries everything about a single product, with full joins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Retrieves my products",
+                "summary": "Retrieves details on a single product.",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Type of products, active/ended/expired",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Items per Page",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Product ID to view",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved",
-                        "schema": {
-                            "$ref": "#/definitions/users.GetProductsResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "When the request is invalid",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/shared.ErrorResponse"
+                            "$ref": "#/definitions/products.GetProductDetailsResponse"
                         }
                     },
-                    "401": {
-                        "description": "When the user is unauthenticated",
+                    "404": {
+                        "description": "The server couldn't find the requested product",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2494,56 +2581,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me/rated": {
-            "get": {
+        "/products/{id}/autobids": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets all ratings and related products and feedbacks, made on me.",
+                "description": "Bids on a product, hopefully with some race-condition management.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Gets a list of ratings on me.",
+                "summary": "Makes an automated bid on a product.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Bid body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.PostBidBody"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "201": {
+                        "description": "Successful bid",
                         "schema": {
-                            "$ref": "#/definitions/users.GetRatingsResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid query",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthenticated",
+                        "description": "User is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User can not bid for other reasons",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Race condition, and you lost",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "Server could not finish the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
@@ -2551,296 +2650,5692 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me/ratings": {
-            "get": {
+        "/products/{id}/bids": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets all ratings and related products and feedbacks, made by me.",
+                "description": "Bids on a product, hopefully with some race-condition management.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Gets a list of ratings made by me.",
+                "summary": "Creates a bid on a product",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Bid body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.PostBidBody"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "201": {
+                        "description": "Successful bid",
                         "schema": {
-                            "$ref": "#/definitions/users.GetRatingsResponse"
+                            "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid query",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthenticated",
+                        "description": "User is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User can not bid for other reasons",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Race condition, and you lost",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The server could not complete the request",
+                        "description": "Server could not finish the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/users/request": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Sends a request to the admin to approve or deny seller privileges.",
+                "description": "Removes all bidders from a product.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "products"
                 ],
-                "summary": "Requests seller privileges",
+                "summary": "Clears all bids on a product.",
                 "responses": {
                     "204": {
-                        "description": "When success",
+                        "description": "Successful removal",
                         "schema": {
                             "$ref": "#/definitions/shared.MessageResponse"
                         }
                     },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
                     "401": {
-                        "description": "When unauthenticated",
+                        "description": "User is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not the seller",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "The request could not be completed due to server faults",
+                        "description": "Server could not finish the request",
                         "schema": {
                             "$ref": "#/definitions/shared.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.LoginRequest": {
-            "type": "object",
-            "required": [
+        },
+        "/products/{id}/bin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Make a BIN bid to a product and end it immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Make a BIN purchase.",
+                "responses": {
+                    "200": {
+                        "description": "Successful bid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not the seller",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server could not finish the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/denials": {
+            "post": {
+                "description": "Deny a bidder from a current product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Deny a bidder.",
+                "parameters": [
+                    {
+                        "description": "Body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.PostDenyBidderBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully denied bidder",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid bidder user ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User is unauthorized or not verified",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is not seller, or denied bidder id is the seller",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product is not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Bidder is already denied",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server couldn't complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/description": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The description can't be changed entirely, but there will be a post-script remark added to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Posts a new product's description change.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New product description body",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.PostProductDescriptionBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully added a postscript remark",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "When the request is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When the user is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "When the user can't edit a product or the product does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not make the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new question to a product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Posts a question to a product.",
+                "parameters": [
+                    {
+                        "description": "New question body",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/questions.PostQuestionBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Added a new question",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "When the request is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When the user is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "When the user is the seller or the product is expired",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not make the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/questions/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Answers a question to the product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "questions"
+                ],
+                "summary": "Answers a question.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Question ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Question Body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/questions.PutQuestionBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Added an answer",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "When the request is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When the user is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "When the user isn't the seller or the product is expired",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not make the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings": {
+            "post": {
+                "description": "Rates the other party of a completed or cancelled transaction the user took part in. Each side can rate the other once per transaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Creates a rating.",
+                "parameters": [
+                    {
+                        "description": "Rating context",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratings.PostRatingBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully rated person",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a party of the transaction, or it isn't finished yet",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already rated this transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/reports": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists reports of ratings with a status, oldest first. Admins see the original feedback, even if it was hidden or removed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Gets the moderation queue of reported ratings.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Report status, defaults to open",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reports",
+                        "schema": {
+                            "$ref": "#/definitions/ratings.RatingReportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the ratings.moderate permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/reports/{id}/moderate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolves a report by dismissing it, hiding the feedback of the rating, or removing the rating entirely. Every open report of the same rating is resolved with it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Moderates a reported rating.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderation",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratings.PostModerateBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully moderated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the ratings.moderate permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Report already resolved",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/{id}": {
+            "put": {
+                "description": "Edits a rating and feedback onto a users profile.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Edits a rating.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rating context",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratings.PutRatingBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully edited rating",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reviewee ID or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not your rating",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rating ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a rating. The transaction can't be rated again afterwards.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Deletes a rating.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted rating",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reviewee ID or invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not your rating",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rating ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/{id}/reply": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lets the reviewee of a rating reply to it publicly. A rating can only be replied to once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Replies to a rating.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reply",
+                        "name": "reply",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratings.PostReplyBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully replied",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a rating of you",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rating ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already replied",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/{id}/reports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Flags a rating as abusive for the admins to review. Every user can report a rating once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Reports a rating.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratings.PostReportBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully reported",
+                        "schema": {
+                            "$ref": "#/definitions/shared.IDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Rating ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reported",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/revenue": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Aggregates the fees earned and payouts owed on settled transactions over a date range, grouped by day, week or month.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Gets the platform revenue report.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "description": "Grouping interval, defaults to day",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Settled on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Settled on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful query",
+                        "schema": {
+                            "$ref": "#/definitions/reports.RevenueResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the reports.read permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the roles with the permissions they grant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Gets every role.",
+                "responses": {
+                    "200": {
+                        "description": "Successful",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/roles.RoleDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a role granting a set of permissions, which can then be assigned to users. Only permissions you have can be granted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Creates a role.",
+                "parameters": [
+                    {
+                        "description": "The role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/roles.PostRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/roles.RoleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or unknown permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission, or a permission being granted",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Role already exists",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the permissions that can be granted to roles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Gets every permission.",
+                "responses": {
+                    "200": {
+                        "description": "Successful",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/roles.PermissionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the description and permissions of a role, taking effect for everyone with it. Only permissions you have can be granted. The admin role always has every permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Changes a role.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/roles.PutRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changed",
+                        "schema": {
+                            "$ref": "#/definitions/roles.RoleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or unknown permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission, or a permission being granted",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The admin role can't be changed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Takes a role away from everyone who has it, then deletes it. The default and admin roles can't be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Deletes a role.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The role can't be deleted",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a transaction to link it with a product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Creates a transaction.",
+                "parameters": [
+                    {
+                        "description": "Transaction data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PostTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created a transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.IDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only seller can create this transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already created the transaction for that product",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a transaction status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Gets a transaction status.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully queried",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.TransactionStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only seller can create this transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a transaction status. Only admins with the payments.confirm permission can mark a pending transaction as paid, otherwise it's paid through the payment provider.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Updates a transaction.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transaction data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PutTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created a transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.IDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only seller can create this transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction status changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/invoice": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a link to the invoice of a completed transaction as a PDF or HTML document, listing the final price and the platform fees. Invoices are private, so the link expires after a few minutes. Only the buyer and the seller can get it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Links to the invoice of a transaction.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pdf",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Document format, defaults to pdf",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Link to the invoice",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.InvoiceURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or format",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a party of the transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The transaction isn't completed and settled yet",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/payments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a payment intent with the payment provider for a pending transaction. If there is one still open, that one is returned instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Starts paying for a transaction.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Payment intent to complete at the checkout URL",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PaymentIntentDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the buyer can pay for a pending transaction",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction ID, or payments are disabled",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/shipping": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lets the buyer submit a shipping address for the transaction, or confirm the one on their profile if no address is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Submits or confirms a shipping address.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shipping data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PutShippingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully saved the shipping address",
+                        "schema": {
+                            "$ref": "#/definitions/shared.IDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or no address to confirm",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the buyer can submit an address before delivery",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The product was shipped in the meantime",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Searches for users by name or email, optionally only the ones with a role, verified or not, sellers or not and suspended or not.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Searches for users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Part of the name or email",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the account is verified",
+                        "name": "verified",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the user can sell right now",
+                        "name": "seller",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the user is suspended or banned",
+                        "name": "suspended",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching users",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.read permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists what staff did to users and roles, newest first, optionally only to a user, by a user or of a kind.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets the audit trail.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only actions on this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only actions by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this kind of action, like user.ban",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The audit trail",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetAuditLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the audit.read permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Uploads and changes my avatar.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Changes my avatar.",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "account image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When success",
+                        "schema": {
+                            "$ref": "#/definitions/users.PostAvatarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "When the image is too big",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves information about your own profile if authenticated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets your own profile.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "When your info had an invalid state on the server",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the current authenticated user's profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Updates your user profile",
+                "parameters": [
+                    {
+                        "description": "Profile data",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When successfully changed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/bids": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves information about your ongoing bids.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my own bids.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "What type of bids to include, active/ended?",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetProductsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/email": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends a confirmation link to the new email, and lets the current one know. The current email stays until the link is used.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Requests an email change",
+                "parameters": [
+                    {
+                        "description": "New email and current password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostEmailChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Confirmation link was sent",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, or the email is already yours",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Incorrect current password",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already in use",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "421": {
+                        "description": "Account uses OAuth",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many email change requests",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/email/confirm": {
+            "post": {
+                "description": "Changes the email using the token from a confirmation email. Access tokens are revoked since they carry the old email, so clients should refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirms an email change",
+                "parameters": [
+                    {
+                        "description": "Confirmation token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostEmailConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email was changed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, or invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email was taken in the meantime",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the current authenticated user's profile password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Updates your profile password",
+                "parameters": [
+                    {
+                        "description": "New password data",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PutPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When successfully changed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Incorrect current password",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "421": {
+                        "description": "Account uses OAuth",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves what I was paid out for my completed sales, with the fees taken off each one, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my payout statement.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Settled on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Settled on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetPayoutsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/products": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves my products, paginated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Retrieves my products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Type of products, active/ended/expired",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetProductsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "When the request is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When the user is unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not make the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/purchases": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the transactions where I am the buyer, newest first, with the seller and ratings between us.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my purchases.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "paid",
+                            "delivered",
+                            "completed",
+                            "cancelled",
+                            "disputed"
+                        ],
+                        "type": "string",
+                        "description": "Transaction status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/rated": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets all ratings and related products and feedbacks, made on me.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets a list of ratings on me.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetRatingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/ratings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets all ratings and related products and feedbacks, made by me.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets a list of ratings made by me.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetRatingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/requests": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves every request for seller privileges you made, newest first, with the decisions on them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my seller requests",
+                "responses": {
+                    "200": {
+                        "description": "My requests",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/users.SellerRequestDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sales": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves the transactions where I am the seller, newest first, with the buyer and ratings between us.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my sales.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "paid",
+                            "delivered",
+                            "completed",
+                            "cancelled",
+                            "disputed"
+                        ],
+                        "type": "string",
+                        "description": "Transaction status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the sessions of the current user, most recently used first. The session of the refresh token cookie is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets where I'm logged in.",
+                "responses": {
+                    "200": {
+                        "description": "The sessions",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetSessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the refresh tokens and access tokens of every session, including the current one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Logs out everywhere.",
+                "responses": {
+                    "200": {
+                        "description": "Logged out everywhere",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the refresh token of one session, along with the access tokens given out for it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Logs out of a session.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged out of the session",
+                        "schema": {
+                            "$ref": "#/definitions/shared.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such session",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server could not complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves every seller subscription you had, latest first. Once they all expire, live auctions keep running until they end, but new ones can't be posted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets my subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "My subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/users.SubscriptionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/request": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends a request to the admins to approve or reject seller privileges on a plan, or to renew them. The account has to be at least 7 days old, and can only have one pending request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Requests seller privileges",
+                "parameters": [
+                    {
+                        "description": "Message to the admins",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/users.PostSellerRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The pending request",
+                        "schema": {
+                            "$ref": "#/definitions/users.SellerRequestDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or unknown plan",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Account is too new",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already has a pending request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/requests": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves requests for seller privileges with a status, oldest first. Defaults to pending ones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets seller requests",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Status of the requests",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per Page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The requests",
+                        "schema": {
+                            "$ref": "#/definitions/users.GetSellerRequestsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the sellers.review permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/requests/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Approves a pending request for seller privileges, giving its user a subscription on the requested plan and emailing them. Renewals start when the current subscription expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Approves a seller request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Seller request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note to the user",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/users.PostApproveSellerRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The approved request",
+                        "schema": {
+                            "$ref": "#/definitions/users.SellerRequestDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the sellers.review permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown seller request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/requests/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rejects a pending request for seller privileges with a reason, and emails its user. They can request again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Rejects a seller request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Seller request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why it was rejected",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostRejectSellerRequestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The rejected request",
+                        "schema": {
+                            "$ref": "#/definitions/users.SellerRequestDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the sellers.review permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown seller request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/subscriptions/plans": {
+            "get": {
+                "description": "Retrieves the plans seller privileges can be requested or renewed on. The first one is the default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets the subscription plans",
+                "responses": {
+                    "200": {
+                        "description": "The plans",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/users.SubscriptionPlanDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/ban": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Signs a user out and keeps them from using their account until reinstated, with a reason they're shown. Their automatic bids stop and their bids on running auctions are withdrawn. Only users with the roles.write permission can ban other staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Bans a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostBanUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The banned user",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.ban permission, or roles.write for staff",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Tried to ban yourself",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/dossier": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a user with their most recent listings, bids, ratings, purchases, sales, seller requests, subscriptions and what staff did to them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets the dossier of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The dossier",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDossierResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.read permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/profile": {
+            "get": {
+                "description": "Gets the reputation of a user, their most recent ratings with replies and their active listings. Emails are never shown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets the public profile of a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The profile",
+                        "schema": {
+                            "$ref": "#/definitions/users.PublicProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reinstate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lifts the suspension or ban of a user, so they can sign in again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reinstates a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/users.PostReinstateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The reinstated user",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.ban permission, or roles.write for staff",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user isn't suspended, or is yourself",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the roles of a user, who always keeps the default one. Their access tokens are revoked, so the change takes effect right away. Admins can't change their own roles, or give or take away roles with permissions they don't have.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Assigns roles to a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The roles",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PutUserRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Assigned",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or unknown role",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the roles.write permission, or a permission of a role being changed",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Tried to change your own roles",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The server failed to complete the request",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves every seller subscription a user had, latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Gets the subscriptions of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Their subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/users.SubscriptionDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.read permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gives a user seller privileges for a number of days, starting when their current subscription expires or now if they don't have one. The user is emailed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Extends the subscription of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "How long, and an optional note to the user",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostExtendSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The new subscription",
+                        "schema": {
+                            "$ref": "#/definitions/users.SubscriptionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the subscriptions.write permission",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Signs a user out and keeps them from using their account for a while, with a reason they're shown. Their automatic bids stop. Only users with the roles.write permission can suspend other staff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Suspends a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "How long and why",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.PostSuspendUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The suspended user",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or body",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "When unauthenticated",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing the users.ban permission, or roles.write for staff",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown user",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Tried to suspend yourself",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The request could not be completed due to server faults",
+                        "schema": {
+                            "$ref": "#/definitions/shared.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.GoogleCallbackRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                }
+            }
+        },
+        "auth.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.PasskeyDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.PasskeyLoginRequest": {
+            "type": "object",
+            "required": [
+                "credential"
+            ],
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/services.WebAuthnAssertion"
+                }
+            }
+        },
+        "auth.PasskeyRegisterRequest": {
+            "type": "object",
+            "required": [
+                "credential",
+                "name"
+            ],
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/services.WebAuthnRegistration"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "auth.PutRoleTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "required"
+            ],
+            "properties": {
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "auth.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "captcha_token",
                 "email",
+                "name",
                 "password"
             ],
             "properties": {
-                "email": {
+                "captcha_token": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                }
+            }
+        },
+        "auth.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 8
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.SuspendedResponse": {
+            "type": "object",
+            "properties": {
+                "banned": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "suspended_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.TwoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.TwoFactorCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "auth.TwoFactorConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.TwoFactorLoginRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "auth.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.TwoFactorStatusResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "recovery_codes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "categories.CategoryDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "subcategories": {
+                    "description": "To make Swagger not dig too deep",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "categories.PostCategoryBody": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 2
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "categories.PutCategoryBody": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 2
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.ChatMessageResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shared.ChatMessageDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.ChatSessionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shared.ChatSessionDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "chat.CreateChatSessionRequest": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "disputes.DisputeDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "opened_by": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "previous_status": {
+                    "$ref": "#/definitions/models.TransactionStatus"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "$ref": "#/definitions/models.DisputeResolution"
+                },
+                "resolution_note": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "statements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/disputes.DisputeStatementDTO"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/models.DisputeStatus"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/shared.TransactionDTO"
+                }
+            }
+        },
+        "disputes.DisputeStatementDTO": {
+            "type": "object",
+            "properties": {
+                "attachment_url": {
+                    "type": "string"
+                },
+                "author": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "disputes.DisputesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/disputes.DisputeDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "disputes.PostDisputeRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "transaction_id"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 10
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "disputes.PostResolveRequest": {
+            "type": "object",
+            "required": [
+                "note",
+                "resolution"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 1
+                },
+                "resolution": {
+                    "enum": [
+                        "buyer",
+                        "seller"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DisputeResolution"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.DisputeResolution": {
+            "type": "string",
+            "enum": [
+                "buyer",
+                "seller"
+            ],
+            "x-enum-varnames": [
+                "DisputeResolutionBuyer",
+                "DisputeResolutionSeller"
+            ]
+        },
+        "models.DisputeStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "DisputeStatusOpen",
+                "DisputeStatusResolved"
+            ]
+        },
+        "models.ModerationAction": {
+            "type": "string",
+            "enum": [
+                "dismiss",
+                "hide",
+                "remove"
+            ],
+            "x-enum-varnames": [
+                "ModerationActionDismiss",
+                "ModerationActionHide",
+                "ModerationActionRemove"
+            ]
+        },
+        "models.PaymentIntentStatus": {
+            "type": "string",
+            "enum": [
+                "requires_payment",
+                "succeeded",
+                "failed",
+                "refunded"
+            ],
+            "x-enum-varnames": [
+                "PaymentIntentStatusRequiresPayment",
+                "PaymentIntentStatusSucceeded",
+                "PaymentIntentStatusFailed",
+                "PaymentIntentStatusRefunded"
+            ]
+        },
+        "models.RatingReportStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "RatingReportStatusOpen",
+                "RatingReportStatusResolved"
+            ]
+        },
+        "models.TransactionStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "paid",
+                "delivered",
+                "completed",
+                "cancelled",
+                "disputed"
+            ],
+            "x-enum-varnames": [
+                "TransactionStatusPending",
+                "TransactionStatusWinnerPaid",
+                "TransactionStatusDelivered",
+                "TransactionStatusCompleted",
+                "TransactionStatusCancelled",
+                "TransactionStatusDisputed"
+            ]
+        },
+        "payments.PostMockCheckoutRequest": {
+            "type": "object",
+            "required": [
+                "outcome"
+            ],
+            "properties": {
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "succeeded",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "products.BidDTO": {
+            "type": "object",
+            "properties": {
+                "automated": {
+                    "type": "boolean"
+                },
+                "bidder": {
+                    "$ref": "#/definitions/products.ProfileDTO"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.CategoryDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.DescriptionChangeDTO": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.GetProductDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "allows_unrated_buyers": {
+                    "type": "boolean"
+                },
+                "auto_extends_time": {
+                    "type": "boolean"
+                },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.BidDTO"
+                    }
+                },
+                "bids_count": {
+                    "type": "integer"
+                },
+                "bin_price": {
+                    "type": "integer"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.CategoryDTO"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_highest_bid": {
+                    "$ref": "#/definitions/products.BidDTO"
+                },
+                "denied_bidders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProfileDTO"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.DescriptionChangeDTO"
+                    }
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "finalized_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_favorite": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductImageDTO"
+                    }
+                },
+                "product_state": {
+                    "type": "string"
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.QuestionDTO"
+                    }
+                },
+                "seller": {
+                    "$ref": "#/definitions/products.ProfileDTO"
+                },
+                "similar_products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductDTO"
+                    }
+                },
+                "starting_bid": {
+                    "type": "integer"
+                },
+                "step_bid_value": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.GetProductsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.GetTopProductsResponse": {
+            "type": "object",
+            "properties": {
+                "ending_soon": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductDTO"
+                    }
+                },
+                "highest_bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductDTO"
+                    }
+                },
+                "top_bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductDTO"
+                    }
+                }
+            }
+        },
+        "products.PostBidBody": {
+            "type": "object",
+            "required": [
+                "bid"
+            ],
+            "properties": {
+                "bid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.PostDenyBidderBody": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.PostProductDescriptionBody": {
+            "type": "object",
+            "required": [
+                "description"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "minLength": 50
+                }
+            }
+        },
+        "products.ProductDTO": {
+            "type": "object",
+            "properties": {
+                "allows_unrated_buyers": {
+                    "type": "boolean"
+                },
+                "auto_extends_time": {
+                    "type": "boolean"
+                },
+                "bids_count": {
+                    "type": "integer"
+                },
+                "bin_price": {
+                    "type": "integer"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.CategoryDTO"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_highest_bid": {
+                    "$ref": "#/definitions/products.BidDTO"
+                },
+                "denied_bidders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProfileDTO"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.DescriptionChangeDTO"
+                    }
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "finalized_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_favorite": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_state": {
+                    "type": "string"
+                },
+                "seller": {
+                    "$ref": "#/definitions/products.ProfileDTO"
+                },
+                "starting_bid": {
+                    "type": "integer"
+                },
+                "step_bid_value": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.ProductImageDTO": {
+            "type": "object",
+            "properties": {
+                "alt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.ProfileDTO": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "negative_ratings": {
+                    "type": "integer"
+                },
+                "positive_ratings": {
+                    "type": "integer"
+                },
+                "reputation": {
+                    "type": "number"
+                }
+            }
+        },
+        "products.QuestionDTO": {
+            "type": "object",
+            "properties": {
+                "answer": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/products.ProfileDTO"
+                }
+            }
+        },
+        "questions.PostQuestionBody": {
+            "type": "object",
+            "required": [
+                "content",
+                "product_id"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "minLength": 2
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "questions.PutQuestionBody": {
+            "type": "object",
+            "required": [
+                "answer"
+            ],
+            "properties": {
+                "answer": {
+                    "type": "string",
+                    "minLength": 2
+                }
+            }
+        },
+        "ratings.PostModerateBody": {
+            "type": "object",
+            "required": [
+                "action",
+                "reason"
+            ],
+            "properties": {
+                "action": {
+                    "enum": [
+                        "dismiss",
+                        "hide",
+                        "remove"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ModerationAction"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 1
+                }
+            }
+        },
+        "ratings.PostRatingBody": {
+            "type": "object",
+            "required": [
+                "feedback",
+                "rating"
+            ],
+            "properties": {
+                "feedback": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "description": "1 for a like, -1 for a dislike.",
+                    "type": "integer",
+                    "enum": [
+                        -1,
+                        1
+                    ]
+                },
+                "transaction_id": {
+                    "description": "The transaction to rate, or the product it's for.",
+                    "type": "integer"
+                }
+            }
+        },
+        "ratings.PostReplyBody": {
+            "type": "object",
+            "required": [
+                "reply"
+            ],
+            "properties": {
+                "reply": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "ratings.PostReportBody": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 5
+                }
+            }
+        },
+        "ratings.PutRatingBody": {
+            "type": "object",
+            "required": [
+                "feedback",
+                "rating"
+            ],
+            "properties": {
+                "feedback": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "1 for a like, -1 for a dislike.",
+                    "type": "integer",
+                    "enum": [
+                        -1,
+                        1
+                    ]
+                }
+            }
+        },
+        "ratings.RatingReportDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.ModerationAction"
+                },
+                "action_reason": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "$ref": "#/definitions/ratings.ReportedRatingDTO"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporter": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.RatingReportStatus"
+                }
+            }
+        },
+        "ratings.RatingReportsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ratings.RatingReportDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ratings.ReportedRatingDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "feedback": {
+                    "type": "string"
+                },
+                "feedback_hidden": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "boolean"
+                },
+                "reviewee": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "reviewer": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                }
+            }
+        },
+        "reports.RevenueBucketDTO": {
+            "type": "object",
+            "properties": {
+                "fee_amount": {
+                    "type": "integer"
+                },
+                "gross_amount": {
+                    "type": "integer"
+                },
+                "net_amount": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reports.RevenueResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reports.RevenueBucketDTO"
+                    }
+                },
+                "fee_amount": {
+                    "type": "integer"
+                },
+                "gross_amount": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "net_amount": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "roles.PermissionDTO": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "roles.PostRoleRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "id": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "permissions": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "roles.PutRoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "permissions": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "roles.RoleDTO": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 64,
-                    "minLength": 8
+                "id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "require_totp": {
+                    "type": "boolean"
                 }
             }
         },
-        "auth.LoginResponse": {
+        "services.PasskeyCreationOptions": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "attestation": {
                     "type": "string"
+                },
+                "authenticatorSelection": {
+                    "type": "object",
+                    "properties": {
+                        "residentKey": {
+                            "type": "string"
+                        },
+                        "userVerification": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "challenge": {
+                    "type": "string"
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PasskeyCredentialDescriptor"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PasskeyCredentialParam"
+                    }
+                },
+                "rp": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "string"
+                        },
+                        "name": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "object",
+                    "properties": {
+                        "displayName": {
+                            "type": "string"
+                        },
+                        "id": {
+                            "type": "string"
+                        },
+                        "name": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "auth.RegisterRequest": {
+        "services.PasskeyCredentialDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.PasskeyCredentialParam": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.PasskeyRequestOptions": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "rpId": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TrackingStatus": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "label_created",
+                "in_transit",
+                "out_for_delivery",
+                "delivered",
+                "exception"
+            ],
+            "x-enum-varnames": [
+                "TrackingStatusUnknown",
+                "TrackingStatusLabelCreated",
+                "TrackingStatusInTransit",
+                "TrackingStatusOutForDelivery",
+                "TrackingStatusDelivered",
+                "TrackingStatusException"
+            ]
+        },
+        "services.WebAuthnAssertion": {
             "type": "object",
             "required": [
-                "captcha_token",
-                "email",
-                "name",
-                "password"
+                "id",
+                "rawId",
+                "response",
+                "type"
             ],
             "properties": {
-                "captcha_token": {
+                "id": {
+                    "type": "string"
+                },
+                "rawId": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "object",
+                    "required": [
+                        "authenticatorData",
+                        "clientDataJSON",
+                        "signature"
+                    ],
+                    "properties": {
+                        "authenticatorData": {
+                            "type": "string"
+                        },
+                        "clientDataJSON": {
+                            "type": "string"
+                        },
+                        "signature": {
+                            "type": "string"
+                        },
+                        "userHandle": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.WebAuthnRegistration": {
+            "type": "object",
+            "required": [
+                "id",
+                "rawId",
+                "response",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "rawId": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "object",
+                    "required": [
+                        "attestationObject",
+                        "clientDataJSON"
+                    ],
+                    "properties": {
+                        "attestationObject": {
+                            "type": "string"
+                        },
+                        "clientDataJSON": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "shared.ChatMessageDTO": {
+            "type": "object",
+            "properties": {
+                "chat_session_id": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "sender": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                }
+            }
+        },
+        "shared.ChatSessionDTO": {
+            "type": "object",
+            "properties": {
+                "buyer": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product": {
+                    "$ref": "#/definitions/shared.ProductDTO"
+                },
+                "seller": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                }
+            }
+        },
+        "shared.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "shared.IDResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "shared.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "shared.ProductDTO": {
+            "type": "object",
+            "properties": {
+                "allows_unrated_buyers": {
+                    "type": "boolean"
+                },
+                "auto_extends_time": {
+                    "type": "boolean"
+                },
+                "bids_count": {
+                    "description": "CurrentHighestBid   *BidDTO                ` + "`" + `json:\"current_highest_bid\"` + "`" + `\nCategories          []CategoryDTO          ` + "`" + `json:\"categories\"` + "`" + `\nDescriptionChanges  []DescriptionChangeDTO ` + "`" + `json:\"description_changes\"` + "`" + `\nDeniedBidders []ProfileDTO ` + "`" + `json:\"denied_bidders\"` + "`" + `",
+                    "type": "integer"
+                },
+                "bin_price": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "finalized_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_favorite": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_state": {
+                    "type": "string"
+                },
+                "seller": {
+                    "$ref": "#/definitions/shared.ProfileDTO"
+                },
+                "starting_bid": {
+                    "type": "integer"
+                },
+                "step_bid_value": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/shared.TransactionDTO"
+                }
+            }
+        },
+        "shared.ProfileDTO": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
                     "type": "string"
                 },
                 "email": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 64,
-                    "minLength": 8
+                "negative_ratings": {
+                    "type": "integer"
+                },
+                "positive_ratings": {
+                    "type": "integer"
+                },
+                "reputation": {
+                    "type": "number"
                 }
             }
         },
-        "categories.CategoryDTO": {
+        "shared.TransactionDTO": {
             "type": "object",
             "properties": {
+                "buyer_id": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
-                    "type": "string"
+                "final_price": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "seller_id": {
+                    "type": "integer"
+                },
+                "transaction_status": {
                     "type": "string"
+                }
+            }
+        },
+        "transactions.InvoiceURLResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer"
                 },
-                "parent_id": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "transactions.PaymentIntentDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
                     "type": "integer"
                 },
-                "slug": {
+                "checkout_url": {
                     "type": "string"
                 },
-                "subcategories": {
-                    "description": "To make Swagger not dig too deep",
-                    "type": "array",
-                    "items": {
-                        "type": "object"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "id": {
+                    "type": "integer"
+                },
+                "provider": {
                     "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.PaymentIntentStatus"
                 }
             }
         },
-        "categories.PostCategoryBody": {
+        "transactions.PostTransactionRequest": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "name": {
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transactions.PutShippingRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
                     "type": "string",
-                    "maxLength": 200,
+                    "maxLength": 500,
                     "minLength": 2
-                },
-                "parent_id": {
-                    "type": "integer"
                 }
             }
         },
-        "categories.PutCategoryBody": {
+        "transactions.PutTransactionRequest": {
             "type": "object",
             "required": [
-                "name"
+                "status"
             ],
             "properties": {
-                "name": {
+                "carrier": {
                     "type": "string",
-                    "maxLength": 200,
+                    "maxLength": 100,
                     "minLength": 2
                 },
-                "parent_id": {
-                    "type": "integer"
+                "status": {
+                    "enum": [
+                        "pending",
+                        "paid",
+                        "delivered",
+                        "completed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransactionStatus"
+                        }
+                    ]
+                },
+                "tracking_number": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2
                 }
             }
         },
-        "chat.ChatMessageResponse": {
+        "transactions.ShippingDTO": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/shared.ChatMessageDTO"
-                    }
+                "address": {
+                    "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "carrier": {
+                    "type": "string"
                 },
-                "per_page": {
-                    "type": "integer"
+                "confirmed_at": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "tracking_number": {
+                    "type": "string"
                 },
-                "total_pages": {
-                    "type": "integer"
+                "tracking_status": {
+                    "$ref": "#/definitions/services.TrackingStatus"
                 }
             }
         },
-        "chat.ChatSessionResponse": {
+        "transactions.TransactionStatusResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/shared.ChatSessionDTO"
-                    }
+                "shipping": {
+                    "$ref": "#/definitions/transactions.ShippingDTO"
                 },
-                "page": {
-                    "type": "integer"
-                },
-                "per_page": {
-                    "type": "integer"
-                },
-                "total": {
-                    "type": "integer"
-                },
-                "total_pages": {
-                    "type": "integer"
+                "status": {
+                    "$ref": "#/definitions/models.TransactionStatus"
                 }
             }
         },
-        "chat.CreateChatSessionRequest": {
+        "users.AuditLogDTO": {
             "type": "object",
             "properties": {
-                "product_id": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "$ref": "#/definitions/users.ProfileDTO"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
                     "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "target_user": {
+                    "$ref": "#/definitions/users.ProfileDTO"
                 }
             }
         },
-        "models.TransactionStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "paid",
-                "delivered",
-                "completed",
-                "cancelled"
-            ],
-            "x-enum-varnames": [
-                "TransactionStatusPending",
-                "TransactionStatusWinnerPaid",
-                "TransactionStatusDelivered",
-                "TransactionStatusCompleted",
-                "TransactionStatusCancelled"
-            ]
-        },
-        "products.BidDTO": {
+        "users.BidDTO": {
             "type": "object",
             "properties": {
                 "automated": {
                     "type": "boolean"
                 },
                 "bidder": {
-                    "$ref": "#/definitions/products.ProfileDTO"
+                    "$ref": "#/definitions/users.ProfileDTO"
                 },
                 "created_at": {
                     "type": "string"
@@ -2856,7 +8351,7 @@ const docTemplate = `{
                 }
             }
         },
-        "products.CategoryDTO": {
+        "users.CategoryDTO": {
             "type": "object",
             "properties": {
                 "id": {
@@ -2870,7 +8365,7 @@ const docTemplate = `{
                 }
             }
         },
-        "products.DescriptionChangeDTO": {
+        "users.DescriptionChangeDTO": {
             "type": "object",
             "properties": {
                 "changes": {
@@ -2884,113 +8379,112 @@ const docTemplate = `{
                 }
             }
         },
-        "products.GetProductDetailsResponse": {
+        "users.DossierProductsDTO": {
             "type": "object",
             "properties": {
-                "allows_unrated_buyers": {
-                    "type": "boolean"
-                },
-                "auto_extends_time": {
-                    "type": "boolean"
-                },
-                "bids": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.BidDTO"
-                    }
-                },
-                "bids_count": {
-                    "type": "integer"
-                },
-                "bin_price": {
-                    "type": "integer"
-                },
-                "categories": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.CategoryDTO"
+                        "$ref": "#/definitions/users.ProductDTO"
                     }
                 },
-                "created_at": {
+                "state": {
                     "type": "string"
                 },
-                "current_highest_bid": {
-                    "$ref": "#/definitions/products.BidDTO"
-                },
-                "denied_bidders": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.DossierRatingsDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.ProfileDTO"
+                        "$ref": "#/definitions/users.RatingDTO"
                     }
                 },
-                "description": {
-                    "type": "string"
-                },
-                "description_changes": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.DossierTransactionsDTO": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.DescriptionChangeDTO"
+                        "$ref": "#/definitions/users.MyTransactionDTO"
                     }
                 },
-                "expired_at": {
-                    "type": "string"
-                },
-                "finalized_at": {
-                    "type": "string"
-                },
-                "id": {
+                "total": {
                     "type": "integer"
                 },
-                "is_favorite": {
-                    "type": "boolean"
-                },
-                "name": {
-                    "type": "string"
+                "total_amount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.FeeLineItemDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
                 },
-                "product_images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.ProductImageDTO"
-                    }
+                "description": {
+                    "type": "string"
                 },
-                "product_state": {
+                "kind": {
                     "type": "string"
                 },
-                "questions": {
+                "rate_bps": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.GetAuditLogsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.QuestionDTO"
+                        "$ref": "#/definitions/users.AuditLogDTO"
                     }
                 },
-                "seller": {
-                    "$ref": "#/definitions/products.ProfileDTO"
-                },
-                "similar_products": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.ProductDTO"
-                    }
+                "page": {
+                    "type": "integer"
                 },
-                "starting_bid": {
+                "per_page": {
                     "type": "integer"
                 },
-                "step_bid_value": {
+                "total": {
                     "type": "integer"
                 },
-                "thumbnail_url": {
-                    "type": "string"
+                "total_pages": {
+                    "type": "integer"
                 }
             }
         },
-        "products.GetProductsResponse": {
+        "users.GetPayoutsResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.ProductDTO"
+                        "$ref": "#/definitions/users.PayoutDTO"
                     }
                 },
+                "fee_amount": {
+                    "type": "integer"
+                },
+                "gross_amount": {
+                    "type": "integer"
+                },
+                "net_amount": {
+                    "type": "integer"
+                },
                 "page": {
                     "type": "integer"
                 },
@@ -3005,321 +8499,348 @@ const docTemplate = `{
                 }
             }
         },
-        "products.GetTopProductsResponse": {
+        "users.GetProductsResponse": {
             "type": "object",
             "properties": {
-                "ending_soon": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.ProductDTO"
+                        "$ref": "#/definitions/users.ProductDTO"
                     }
                 },
-                "highest_bids": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.ProductDTO"
-                    }
+                "page": {
+                    "type": "integer"
                 },
-                "top_bids": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.ProductDTO"
-                    }
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
                 }
             }
         },
-        "products.PostBidBody": {
+        "users.GetRatingsResponse": {
             "type": "object",
-            "required": [
-                "bid"
-            ],
             "properties": {
-                "bid": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.RatingDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
                     "type": "integer"
                 }
             }
         },
-        "products.PostDenyBidderBody": {
+        "users.GetSellerRequestsResponse": {
             "type": "object",
-            "required": [
-                "user_id"
-            ],
             "properties": {
-                "user_id": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.SellerRequestDTO"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
                     "type": "integer"
                 }
             }
         },
-        "products.PostProductDescriptionBody": {
+        "users.GetSessionsResponse": {
             "type": "object",
-            "required": [
-                "description"
-            ],
             "properties": {
-                "description": {
-                    "type": "string",
-                    "minLength": 50
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.SessionDTO"
+                    }
                 }
             }
         },
-        "products.ProductDTO": {
+        "users.GetTransactionsResponse": {
             "type": "object",
             "properties": {
-                "allows_unrated_buyers": {
-                    "type": "boolean"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.MyTransactionDTO"
+                    }
                 },
-                "auto_extends_time": {
-                    "type": "boolean"
+                "page": {
+                    "type": "integer"
                 },
-                "bids_count": {
+                "per_page": {
                     "type": "integer"
                 },
-                "bin_price": {
+                "total": {
                     "type": "integer"
                 },
-                "categories": {
+                "total_amount": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.GetUsersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/products.CategoryDTO"
+                        "$ref": "#/definitions/users.UserDTO"
                     }
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "current_highest_bid": {
-                    "$ref": "#/definitions/products.BidDTO"
+                "page": {
+                    "type": "integer"
                 },
-                "denied_bidders": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.ProfileDTO"
-                    }
+                "per_page": {
+                    "type": "integer"
                 },
-                "description": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 },
-                "description_changes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/products.DescriptionChangeDTO"
-                    }
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "users.MyTransactionDTO": {
+            "type": "object",
+            "properties": {
+                "counterparty": {
+                    "$ref": "#/definitions/users.ProfileDTO"
                 },
-                "expired_at": {
+                "created_at": {
                     "type": "string"
                 },
-                "finalized_at": {
-                    "type": "string"
+                "final_price": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_favorite": {
-                    "type": "boolean"
+                "my_rating": {
+                    "description": "The rating I left the counterparty, and the one they left me, if any.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/users.TransactionRatingDTO"
+                        }
+                    ]
                 },
-                "name": {
-                    "type": "string"
+                "product": {
+                    "$ref": "#/definitions/users.TransactionProductDTO"
                 },
-                "product_state": {
+                "status": {
                     "type": "string"
                 },
-                "seller": {
-                    "$ref": "#/definitions/products.ProfileDTO"
-                },
-                "starting_bid": {
-                    "type": "integer"
-                },
-                "step_bid_value": {
-                    "type": "integer"
+                "their_rating": {
+                    "$ref": "#/definitions/users.TransactionRatingDTO"
                 },
-                "thumbnail_url": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "products.ProductImageDTO": {
+        "users.PayoutDTO": {
             "type": "object",
             "properties": {
-                "alt": {
+                "created_at": {
                     "type": "string"
                 },
-                "url": {
-                    "type": "string"
-                }
-            }
-        },
-        "products.ProfileDTO": {
-            "type": "object",
-            "properties": {
-                "avatar_url": {
-                    "type": "string"
+                "fee_amount": {
+                    "type": "integer"
                 },
-                "average_rating": {
-                    "type": "number"
+                "fees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.FeeLineItemDTO"
+                    }
                 },
-                "email": {
-                    "type": "string"
+                "gross_amount": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "net_amount": {
+                    "type": "integer"
+                },
+                "product": {
+                    "$ref": "#/definitions/users.TransactionProductDTO"
+                },
+                "transaction_id": {
+                    "type": "integer"
                 }
             }
         },
-        "products.QuestionDTO": {
+        "users.PostApproveSellerRequestRequest": {
             "type": "object",
             "properties": {
-                "answer": {
-                    "type": "string"
-                },
-                "content": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "updated_at": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "users.PostAvatarResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/products.ProfileDTO"
                 }
             }
         },
-        "questions.PostQuestionBody": {
+        "users.PostBanUserRequest": {
             "type": "object",
             "required": [
-                "content",
-                "product_id"
+                "reason"
             ],
             "properties": {
-                "content": {
+                "reason": {
                     "type": "string",
+                    "maxLength": 1000,
                     "minLength": 2
-                },
-                "product_id": {
-                    "type": "integer"
                 }
             }
         },
-        "questions.PutQuestionBody": {
+        "users.PostEmailChangeRequest": {
             "type": "object",
             "required": [
-                "answer"
+                "current_password",
+                "email"
             ],
             "properties": {
-                "answer": {
+                "current_password": {
+                    "type": "string"
+                },
+                "email": {
                     "type": "string",
-                    "minLength": 2
+                    "maxLength": 200
                 }
             }
         },
-        "ratings.PostRatingBody": {
+        "users.PostEmailConfirmRequest": {
             "type": "object",
             "required": [
-                "feedback",
-                "product_id",
-                "reviewee_id"
+                "token"
             ],
             "properties": {
-                "feedback": {
+                "token": {
                     "type": "string"
-                },
-                "product_id": {
-                    "type": "integer"
-                },
-                "rating": {
-                    "type": "integer",
-                    "maximum": 1,
-                    "minimum": 0
-                },
-                "reviewee_id": {
-                    "type": "integer"
                 }
             }
         },
-        "ratings.PutRatingBody": {
+        "users.PostExtendSubscriptionRequest": {
             "type": "object",
             "required": [
-                "feedback"
+                "days"
             ],
             "properties": {
-                "feedback": {
-                    "type": "string"
-                },
-                "rating": {
+                "days": {
                     "type": "integer",
-                    "maximum": 1,
-                    "minimum": 0
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000
                 }
             }
         },
-        "shared.ChatMessageDTO": {
+        "users.PostProfileRequest": {
             "type": "object",
             "properties": {
-                "chat_session_id": {
-                    "type": "integer"
-                },
-                "content": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "image_url": {
-                    "type": "string"
+                "address": {
+                    "type": "string",
+                    "minLength": 2
                 },
-                "sender": {
-                    "$ref": "#/definitions/shared.ProfileDTO"
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 2
                 }
             }
         },
-        "shared.ChatSessionDTO": {
+        "users.PostReinstateUserRequest": {
             "type": "object",
             "properties": {
-                "buyer": {
-                    "$ref": "#/definitions/shared.ProfileDTO"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "product": {
-                    "$ref": "#/definitions/shared.ProductDTO"
-                },
-                "seller": {
-                    "$ref": "#/definitions/shared.ProfileDTO"
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
                 }
             }
         },
-        "shared.ErrorResponse": {
+        "users.PostRejectSellerRequestRequest": {
             "type": "object",
+            "required": [
+                "reason"
+            ],
             "properties": {
-                "error": {
-                    "type": "string"
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 2
                 }
             }
         },
-        "shared.IDResponse": {
+        "users.PostSellerRequestRequest": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
+                "message": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "plan": {
+                    "description": "Defaults to the first plan.",
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "shared.MessageResponse": {
+        "users.PostSuspendUserRequest": {
             "type": "object",
+            "required": [
+                "hours",
+                "reason"
+            ],
             "properties": {
-                "message": {
-                    "type": "string"
+                "hours": {
+                    "type": "integer",
+                    "maximum": 8760,
+                    "minimum": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 2
                 }
             }
         },
-        "shared.ProductDTO": {
+        "users.ProductDTO": {
             "type": "object",
             "properties": {
                 "allows_unrated_buyers": {
@@ -3328,19 +8849,45 @@ const docTemplate = `{
                 "auto_extends_time": {
                     "type": "boolean"
                 },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.BidDTO"
+                    }
+                },
                 "bids_count": {
-                    "description": "CurrentHighestBid   *BidDTO                ` + "`" + `json:\"current_highest_bid\"` + "`" + `\nCategories          []CategoryDTO          ` + "`" + `json:\"categories\"` + "`" + `\nDescriptionChanges  []DescriptionChangeDTO ` + "`" + `json:\"description_changes\"` + "`" + `\nDeniedBidders []ProfileDTO ` + "`" + `json:\"denied_bidders\"` + "`" + `",
                     "type": "integer"
                 },
                 "bin_price": {
                     "type": "integer"
                 },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.CategoryDTO"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "current_highest_bid": {
+                    "$ref": "#/definitions/users.BidDTO"
+                },
+                "denied_bidders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.ProfileDTO"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
+                "description_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.DescriptionChangeDTO"
+                    }
+                },
                 "expired_at": {
                     "type": "string"
                 },
@@ -3360,7 +8907,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "seller": {
-                    "$ref": "#/definitions/shared.ProfileDTO"
+                    "$ref": "#/definitions/users.ProfileDTO"
                 },
                 "starting_bid": {
                     "type": "integer"
@@ -3370,21 +8917,15 @@ const docTemplate = `{
                 },
                 "thumbnail_url": {
                     "type": "string"
-                },
-                "transaction": {
-                    "$ref": "#/definitions/shared.TransactionDTO"
                 }
             }
         },
-        "shared.ProfileDTO": {
+        "users.ProfileDTO": {
             "type": "object",
             "properties": {
                 "avatar_url": {
                     "type": "string"
                 },
-                "average_rating": {
-                    "type": "number"
-                },
                 "email": {
                     "type": "string"
                 },
@@ -3393,357 +8934,306 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
-                }
-            }
-        },
-        "shared.TransactionDTO": {
-            "type": "object",
-            "properties": {
-                "buyer_id": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "final_price": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
                 },
-                "product_id": {
+                "negative_ratings": {
                     "type": "integer"
                 },
-                "seller_id": {
+                "positive_ratings": {
                     "type": "integer"
                 },
-                "transaction_status": {
-                    "type": "string"
+                "reputation": {
+                    "type": "number"
                 }
             }
         },
-        "transactions.PostTransactionRequest": {
+        "users.PublicListingDTO": {
             "type": "object",
             "properties": {
-                "product_id": {
+                "bids_count": {
                     "type": "integer"
-                }
-            }
-        },
-        "transactions.PutTransactionRequest": {
-            "type": "object",
-            "required": [
-                "status"
-            ],
-            "properties": {
-                "status": {
-                    "enum": [
-                        "pending",
-                        "paid",
-                        "delivered",
-                        "completed",
-                        "cancelled"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.TransactionStatus"
-                        }
-                    ]
-                }
-            }
-        },
-        "transactions.TransactionStatusResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "$ref": "#/definitions/models.TransactionStatus"
-                }
-            }
-        },
-        "users.BidDTO": {
-            "type": "object",
-            "properties": {
-                "automated": {
-                    "type": "boolean"
-                },
-                "bidder": {
-                    "$ref": "#/definitions/users.ProfileDTO"
-                },
-                "created_at": {
-                    "type": "string"
                 },
-                "id": {
+                "bin_price": {
                     "type": "integer"
                 },
-                "price": {
+                "current_price": {
                     "type": "integer"
                 },
-                "updated_at": {
+                "expired_at": {
                     "type": "string"
-                }
-            }
-        },
-        "users.CategoryDTO": {
-            "type": "object",
-            "properties": {
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "parent_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "users.DescriptionChangeDTO": {
-            "type": "object",
-            "properties": {
-                "changes": {
-                    "type": "string"
-                },
-                "created_at": {
+                "thumbnail_url": {
                     "type": "string"
-                },
-                "id": {
-                    "type": "integer"
                 }
             }
         },
-        "users.GetProductsResponse": {
+        "users.PublicProfileResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/users.ProductDTO"
-                    }
-                },
-                "page": {
-                    "type": "integer"
-                },
-                "per_page": {
-                    "type": "integer"
-                },
-                "total": {
+                "active_count": {
                     "type": "integer"
                 },
-                "total_pages": {
-                    "type": "integer"
-                }
-            }
-        },
-        "users.GetRatingsResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
+                "active_listings": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/users.RatingDTO"
+                        "$ref": "#/definitions/users.PublicListingDTO"
                     }
                 },
-                "page": {
-                    "type": "integer"
+                "avatar_url": {
+                    "type": "string"
                 },
-                "per_page": {
+                "id": {
                     "type": "integer"
                 },
-                "total": {
+                "member_since": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "negative_ratings": {
                     "type": "integer"
                 },
-                "total_pages": {
+                "positive_ratings": {
                     "type": "integer"
+                },
+                "recent_ratings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.PublicRatingDTO"
+                    }
+                },
+                "reputation": {
+                    "type": "number"
                 }
             }
         },
-        "users.GetUsersResponse": {
+        "users.PublicRatingDTO": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/users.UserDTO"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "feedback": {
+                    "type": "string"
                 },
-                "per_page": {
-                    "type": "integer"
+                "feedback_hidden": {
+                    "type": "boolean"
                 },
-                "total": {
+                "id": {
                     "type": "integer"
                 },
-                "total_pages": {
+                "rating": {
                     "type": "integer"
+                },
+                "replied_at": {
+                    "type": "string"
+                },
+                "reply": {
+                    "type": "string"
+                },
+                "reviewer": {
+                    "$ref": "#/definitions/users.PublicUserDTO"
                 }
             }
         },
-        "users.PostAvatarResponse": {
+        "users.PublicUserDTO": {
             "type": "object",
             "properties": {
                 "avatar_url": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "users.PostProfileRequest": {
+        "users.PutPasswordRequest": {
             "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
             "properties": {
-                "address": {
+                "current_password": {
                     "type": "string",
                     "minLength": 2
                 },
-                "name": {
+                "new_password": {
                     "type": "string",
-                    "maxLength": 200,
                     "minLength": 2
                 }
             }
         },
-        "users.ProductDTO": {
+        "users.PutUserRolesRequest": {
             "type": "object",
             "properties": {
-                "allows_unrated_buyers": {
-                    "type": "boolean"
-                },
-                "auto_extends_time": {
-                    "type": "boolean"
-                },
-                "bids": {
+                "roles": {
                     "type": "array",
+                    "maxItems": 20,
                     "items": {
-                        "$ref": "#/definitions/users.BidDTO"
+                        "type": "string"
                     }
+                }
+            }
+        },
+        "users.RatingDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "bids_count": {
+                "feedback": {
+                    "type": "string"
+                },
+                "feedback_hidden": {
+                    "type": "boolean"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "bin_price": {
+                "rating": {
                     "type": "integer"
                 },
-                "categories": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/users.CategoryDTO"
-                    }
+                "replied_at": {
+                    "type": "string"
                 },
-                "created_at": {
+                "reply": {
                     "type": "string"
                 },
-                "current_highest_bid": {
-                    "$ref": "#/definitions/users.BidDTO"
+                "reviewee": {
+                    "$ref": "#/definitions/users.ProfileDTO"
                 },
-                "denied_bidders": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/users.ProfileDTO"
-                    }
+                "reviewer": {
+                    "$ref": "#/definitions/users.ProfileDTO"
+                }
+            }
+        },
+        "users.SellerRequestDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "description": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
                     "type": "string"
                 },
-                "description_changes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/users.DescriptionChangeDTO"
-                    }
+                "plan": {
+                    "type": "string"
                 },
-                "expired_at": {
+                "reason": {
                     "type": "string"
                 },
-                "finalized_at": {
+                "reviewed_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
+                "status": {
+                    "type": "string"
                 },
-                "is_favorite": {
+                "user": {
+                    "$ref": "#/definitions/users.ProfileDTO"
+                }
+            }
+        },
+        "users.SessionDTO": {
+            "type": "object",
+            "properties": {
+                "current": {
                     "type": "boolean"
                 },
-                "name": {
+                "expired_at": {
                     "type": "string"
                 },
-                "product_state": {
+                "id": {
                     "type": "string"
                 },
-                "seller": {
-                    "$ref": "#/definitions/users.ProfileDTO"
+                "ip_address": {
+                    "type": "string"
                 },
-                "starting_bid": {
-                    "type": "integer"
+                "last_used_at": {
+                    "type": "string"
                 },
-                "step_bid_value": {
-                    "type": "integer"
+                "signed_in_at": {
+                    "type": "string"
                 },
-                "thumbnail_url": {
+                "user_agent": {
                     "type": "string"
                 }
             }
         },
-        "users.ProfileDTO": {
+        "users.SubscriptionDTO": {
             "type": "object",
             "properties": {
-                "avatar_url": {
+                "created_at": {
                     "type": "string"
                 },
-                "average_rating": {
-                    "type": "number"
-                },
-                "email": {
+                "expired_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "note": {
+                    "type": "string"
+                },
+                "plan": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "starts_at": {
                     "type": "string"
                 }
             }
         },
-        "users.PutPasswordRequest": {
+        "users.SubscriptionPlanDTO": {
             "type": "object",
-            "required": [
-                "current_password",
-                "new_password"
-            ],
             "properties": {
-                "current_password": {
-                    "type": "string",
-                    "minLength": 2
+                "days": {
+                    "type": "integer"
                 },
-                "new_password": {
-                    "type": "string",
-                    "minLength": 2
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "users.RatingDTO": {
+        "users.TransactionProductDTO": {
             "type": "object",
             "properties": {
-                "feedback": {
-                    "type": "string"
-                },
-                "rating": {
+                "id": {
                     "type": "integer"
                 },
-                "reviewee": {
-                    "$ref": "#/definitions/users.ProfileDTO"
+                "name": {
+                    "type": "string"
                 },
-                "reviewer": {
-                    "$ref": "#/definitions/users.ProfileDTO"
+                "thumbnail_url": {
+                    "type": "string"
                 }
             }
         },
-        "users.SubscriptionDTO": {
+        "users.TransactionRatingDTO": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "feedback": {
                     "type": "string"
                 },
-                "expired_at": {
-                    "type": "string"
+                "rating": {
+                    "type": "integer"
                 }
             }
         },
@@ -3756,8 +9246,8 @@ const docTemplate = `{
                 "avatar_url": {
                     "type": "string"
                 },
-                "average_rating": {
-                    "type": "number"
+                "banned": {
+                    "type": "boolean"
                 },
                 "created_at": {
                     "type": "string"
@@ -3771,20 +9261,97 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "negative_ratings": {
+                    "type": "integer"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "positive_ratings": {
+                    "type": "integer"
+                },
+                "reputation": {
+                    "type": "number"
+                },
                 "roles": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "seller_request": {
+                    "description": "The latest seller request and what your roles allow, only shown for your own profile.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/users.SellerRequestDTO"
+                        }
+                    ]
+                },
                 "subscription": {
                     "$ref": "#/definitions/users.SubscriptionDTO"
                 },
+                "suspended_until": {
+                    "description": "Banned users stay suspended forever.",
+                    "type": "string"
+                },
+                "suspension_reason": {
+                    "type": "string"
+                },
                 "verified": {
                     "type": "boolean"
+                }
+            }
+        },
+        "users.UserDossierResponse": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.AuditLogDTO"
+                    }
                 },
-                "waiting_approval": {
-                    "type": "boolean"
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.DossierProductsDTO"
+                    }
+                },
+                "listings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.DossierProductsDTO"
+                    }
+                },
+                "purchases": {
+                    "$ref": "#/definitions/users.DossierTransactionsDTO"
+                },
+                "rated": {
+                    "$ref": "#/definitions/users.DossierRatingsDTO"
+                },
+                "ratings": {
+                    "$ref": "#/definitions/users.DossierRatingsDTO"
+                },
+                "sales": {
+                    "$ref": "#/definitions/users.DossierTransactionsDTO"
+                },
+                "seller_requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.SellerRequestDTO"
+                    }
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/users.SubscriptionDTO"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/users.UserDTO"
                 }
             }
         }
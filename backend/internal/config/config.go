@@ -1,17 +1,31 @@
 // Package config provides a centralized way to handle environment variables
 package config
 
-import "luny.dev/cherryauctions/pkg/env"
+import (
+	"log"
+
+	"luny.dev/cherryauctions/pkg/env"
+)
 
 // -------------------------------------
 // Thanks Gemini
 // -------------------------------------
 
 type Config struct {
-	DatabaseURL     string
-	Domain          string
-	CookieSecure    bool
-	RecaptchaSecret string
+	DatabaseURL  string
+	Domain       string
+	CookieSecure bool
+
+	// Local development, allows the mock payment gateway. Never set it in production.
+	DevMode bool
+
+	// Captcha provider (recaptcha, hcaptcha, turnstile or none) and the rules of each endpoint
+	// as JSON, see services.ParseCaptchaRules.
+	Captcha struct {
+		Provider string
+		Secret   string
+		Rules    string
+	}
 
 	CORS struct {
 		Origins string
@@ -23,6 +37,10 @@ type Config struct {
 		Secret   string
 		Audience string
 		Expiry   int
+
+		// Asymmetric keys as kid=source pairs, see services.LoadJWTKeys. HS256 with the secret
+		// is used without any.
+		Keys string
 	}
 
 	AWS struct {
@@ -41,6 +59,44 @@ type Config struct {
 		User     string
 		Password string
 	}
+
+	Payment struct {
+		Provider        string
+		WebhookSecret   string
+		CheckoutBaseURL string
+	}
+
+	// Deadlines for transactions, in days.
+	Deadlines struct {
+		PaymentDays  int
+		DeliveryDays int
+		ReminderDays int
+	}
+
+	// Fee schedule as JSON, see services.FeeSchedule.
+	FeeSchedule string
+
+	// Seller subscription plans as JSON, see services.SubscriptionPlan.
+	Subscriptions struct {
+		Plans        string
+		ReminderDays int
+	}
+
+	// Sign in with Google, disabled without a client ID.
+	Google struct {
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		RedirectURL  string
+	}
+
+	// Passkeys. The relying party ID is the domain of the frontend, and the origins default to
+	// the CORS origins.
+	WebAuthn struct {
+		RPID    string
+		RPName  string
+		Origins string
+	}
 }
 
 func Load() *Config {
@@ -50,7 +106,12 @@ func Load() *Config {
 	cfg.DatabaseURL = env.Fatalenv("DATABASE_URL")
 	cfg.Domain = env.Fatalenv("DOMAIN")
 	cfg.CookieSecure = env.FatalenvBool("COOKIE_SECURE")
-	cfg.RecaptchaSecret = env.Fatalenv("RECAPTCHA_SECRET")
+	cfg.DevMode = env.GetenvBool("DEV_MODE", false)
+
+	// Captcha, RECAPTCHA_SECRET is from before other providers.
+	cfg.Captcha.Provider = env.Getenv("CAPTCHA_PROVIDER", "recaptcha")
+	cfg.Captcha.Secret = env.Getenv("CAPTCHA_SECRET", env.Getenv("RECAPTCHA_SECRET", ""))
+	cfg.Captcha.Rules = env.Getenv("CAPTCHA_RULES", `{"register":{"action":"submit","min_score":0.5}}`)
 
 	// CORS
 	cfg.CORS.Origins = env.Fatalenv("CORS_ORIGINS")
@@ -61,6 +122,7 @@ func Load() *Config {
 	cfg.JWT.Secret = env.Fatalenv("JWT_SECRET_KEY")
 	cfg.JWT.Audience = env.Fatalenv("JWT_AUDIENCE")
 	cfg.JWT.Expiry = int(env.FatalenvInt("JWT_EXPIRY"))
+	cfg.JWT.Keys = env.Getenv("JWT_KEYS", "")
 
 	// AWS
 	cfg.AWS.AccessKeyID = env.Fatalenv("AWS_ACCESS_KEY_ID")
@@ -77,5 +139,44 @@ func Load() *Config {
 	cfg.SMTP.User = env.Fatalenv("SMTP_USER")
 	cfg.SMTP.Password = env.Fatalenv("SMTP_PASSWORD")
 
+	// Payments, these only default to the built-in mock gateway in development. "none" disables
+	// payments, and doesn't need a webhook secret.
+	if cfg.DevMode {
+		cfg.Payment.Provider = env.Getenv("PAYMENT_PROVIDER", "mock")
+		cfg.Payment.WebhookSecret = env.Getenv("PAYMENT_WEBHOOK_SECRET", "mock-webhook-secret")
+	} else {
+		cfg.Payment.Provider = env.Fatalenv("PAYMENT_PROVIDER")
+		if cfg.Payment.Provider != "none" {
+			cfg.Payment.WebhookSecret = env.Fatalenv("PAYMENT_WEBHOOK_SECRET")
+			if cfg.Payment.WebhookSecret == "" {
+				log.Fatalf("fatal: PAYMENT_WEBHOOK_SECRET can't be empty\n")
+			}
+		}
+	}
+	cfg.Payment.CheckoutBaseURL = env.Getenv("PAYMENT_CHECKOUT_BASE_URL", "http://localhost/v1")
+
+	// Transaction deadlines
+	cfg.Deadlines.PaymentDays = int(env.GetenvInt("DEADLINE_PAYMENT_DAYS", 3))
+	cfg.Deadlines.DeliveryDays = int(env.GetenvInt("DEADLINE_DELIVERY_DAYS", 7))
+	cfg.Deadlines.ReminderDays = int(env.GetenvInt("DEADLINE_REMINDER_DAYS", 1))
+
+	// Fees, 5% flat by default.
+	cfg.FeeSchedule = env.Getenv("FEE_SCHEDULE", `{"rate_bps":500}`)
+
+	// Subscriptions, a week or a month.
+	cfg.Subscriptions.Plans = env.Getenv("SUBSCRIPTION_PLANS", `[{"id":"week","name":"1 Week","days":7},{"id":"month","name":"1 Month","days":30}]`)
+	cfg.Subscriptions.ReminderDays = int(env.GetenvInt("SUBSCRIPTION_REMINDER_DAYS", 2))
+
+	// Google
+	cfg.Google.Issuer = env.Getenv("GOOGLE_ISSUER", "https://accounts.google.com")
+	cfg.Google.ClientID = env.Getenv("GOOGLE_CLIENT_ID", "")
+	cfg.Google.ClientSecret = env.Getenv("GOOGLE_CLIENT_SECRET", "")
+	cfg.Google.RedirectURL = env.Getenv("GOOGLE_REDIRECT_URL", "")
+
+	// WebAuthn
+	cfg.WebAuthn.RPID = env.Getenv("WEBAUTHN_RP_ID", "localhost")
+	cfg.WebAuthn.RPName = env.Getenv("WEBAUTHN_RP_NAME", "Cherry Auctions")
+	cfg.WebAuthn.Origins = env.Getenv("WEBAUTHN_ORIGINS", cfg.CORS.Origins)
+
 	return cfg
 }
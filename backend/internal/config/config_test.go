@@ -49,6 +49,9 @@ func TestConfigLoad(t *testing.T) {
 	t.Setenv("SMTP_USER", "user@gmail.com")
 	t.Setenv("SMTP_PASSWORD", "password")
 
+	t.Setenv("PAYMENT_PROVIDER", "mock")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "webhook-secret")
+
 	// 3. Run the function
 	cfg := config.Load()
 
@@ -4,10 +4,12 @@ package infra
 
 import (
 	"log"
+	"slices"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
@@ -32,11 +34,20 @@ func SetupDatabase(databaseURL string) *gorm.DB {
 
 // MigrateModels uses GORM to migrate the models.
 func MigrateModels(db *gorm.DB) {
+	// Has to happen before the ratings get their new constraint.
+	legacyRatings := migrateRatingsToLikes(db)
+	migrateDisputeAttachments(db)
+	legacySubscriptions := db.Migrator().HasTable(&models.SellerSubscription{}) &&
+		!db.Migrator().HasColumn(&models.SellerSubscription{}, "starts_at")
+	legacyInvoices := db.Migrator().HasTable(&models.Transaction{}) &&
+		!db.Migrator().HasColumn(&models.Transaction{}, "invoice_sent_at")
+
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.RefreshToken{},
 		&models.Category{},
 		&models.Role{},
+		&models.Permission{},
 		&models.Product{},
 		&models.Question{},
 		&models.ProductImage{},
@@ -49,8 +60,205 @@ func MigrateModels(db *gorm.DB) {
 		&models.Transaction{},
 		&models.Rating{},
 		&models.BidIntent{},
+		&models.PaymentIntent{},
+		&models.PaymentWebhookEvent{},
+		&models.Dispute{},
+		&models.DisputeStatement{},
+		&models.Payout{},
+		&models.FeeLineItem{},
+		&models.RatingReport{},
+		&models.PasswordReset{},
+		&models.RecoveryCode{},
+		&models.TwoFactorChallenge{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnChallenge{},
+		&models.AuthThrottle{},
+		&models.EmailChange{},
+		&models.SellerRequest{},
+		&models.AuditLog{},
 	)
 	if err != nil {
 		log.Fatalln("fatal: failed to auto migrate models. check them yourself")
 	}
+
+	if legacyRatings {
+		backfillRatingCounters(db)
+	}
+	linkRatingsToTransactions(db)
+	backfillTokenFamilies(db)
+	dropPlaintextOTPs(db)
+	migrateWaitingApprovals(db)
+	if legacySubscriptions {
+		backfillSubscriptionStarts(db)
+	}
+	if legacyInvoices {
+		backfillInvoicesSent(db)
+	}
+	syncPermissions(db)
+}
+
+// migrateRatingsToLikes turns ratings from the old 0 (bad) and 1+ (good) scale into
+// dislikes and likes. Reports whether the users still have the old average rating.
+func migrateRatingsToLikes(db *gorm.DB) bool {
+	if db.Migrator().HasTable(&models.Rating{}) {
+		err := db.Exec("UPDATE ratings SET rating = CASE WHEN rating >= 1 THEN 1 ELSE -1 END WHERE rating NOT IN (-1, 1)").Error
+		if err != nil {
+			log.Fatalf("fatal: failed to migrate ratings: %v", err)
+		}
+	}
+
+	return db.Migrator().HasColumn(&models.User{}, "average_rating")
+}
+
+// migrateDisputeAttachments turns the public URLs of dispute evidence into the keys of
+// the objects, since they are only handed out through presigned URLs now.
+func migrateDisputeAttachments(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.DisputeStatement{}, "attachment_url") {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().RenameColumn(&models.DisputeStatement{}, "attachment_url", "attachment_key"); err != nil {
+			return err
+		}
+		return tx.Exec(`
+			UPDATE dispute_statements
+			SET attachment_key = substring(attachment_key from position('disputes/' in attachment_key))
+			WHERE attachment_key IS NOT NULL
+		`).Error
+	})
+	if err != nil {
+		log.Fatalf("fatal: failed to migrate dispute attachments: %v", err)
+	}
+}
+
+// backfillRatingCounters counts the likes and dislikes of every user, replacing the average rating.
+func backfillRatingCounters(db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE users SET
+				positive_ratings = (SELECT COUNT(*) FROM ratings WHERE reviewee_id = users.id AND rating > 0 AND deleted_at IS NULL),
+				negative_ratings = (SELECT COUNT(*) FROM ratings WHERE reviewee_id = users.id AND rating < 0 AND deleted_at IS NULL)`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&models.User{}, "average_rating")
+	})
+	if err != nil {
+		log.Fatalf("fatal: failed to backfill rating counters: %v", err)
+	}
+}
+
+// linkRatingsToTransactions ties ratings from before they referenced a transaction to the
+// transaction of their product. Only the latest rating of each reviewer is kept linked, older
+// duplicates stay as they are.
+func linkRatingsToTransactions(db *gorm.DB) {
+	err := db.Exec(`
+		UPDATE ratings SET transaction_id = transactions.id
+		FROM transactions
+		WHERE transactions.product_id = ratings.product_id
+			AND ratings.reviewer_id IN (transactions.buyer_id, transactions.seller_id)
+			AND ratings.transaction_id IS NULL
+			AND ratings.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM ratings other
+				WHERE other.product_id = ratings.product_id
+					AND other.reviewer_id = ratings.reviewer_id
+					AND other.deleted_at IS NULL
+					AND (other.id > ratings.id OR other.transaction_id IS NOT NULL)
+			)`).Error
+	if err != nil {
+		log.Fatalf("fatal: failed to link ratings to transactions: %v", err)
+	}
+}
+
+// backfillTokenFamilies puts refresh tokens from before sessions each in their own family.
+func backfillTokenFamilies(db *gorm.DB) {
+	err := db.Exec(`
+		UPDATE refresh_tokens
+		SET family_id = 'legacy-' || id, signed_in_at = created_at, last_used_at = updated_at
+		WHERE family_id = ''`).Error
+	if err != nil {
+		log.Fatalf("fatal: failed to backfill refresh token families: %v", err)
+	}
+}
+
+// dropPlaintextOTPs drops the OTP codes from before they were hashed. Anyone who was verifying
+// has to ask for a new code.
+func dropPlaintextOTPs(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.User{}, "otp_code") {
+		return
+	}
+
+	if err := db.Migrator().DropColumn(&models.User{}, "otp_code"); err != nil {
+		log.Fatalf("fatal: failed to drop plaintext otp codes: %v", err)
+	}
+}
+
+// migrateWaitingApprovals turns users that were waiting for approval into pending seller requests,
+// then drops the flag.
+func migrateWaitingApprovals(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&models.User{}, "waiting_approval") {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO seller_requests (user_id, message, status, created_at, updated_at)
+			SELECT id, '', 'pending', updated_at, updated_at
+			FROM users
+			WHERE waiting_approval`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&models.User{}, "waiting_approval")
+	})
+	if err != nil {
+		log.Fatalf("fatal: failed to migrate waiting approvals: %v", err)
+	}
+}
+
+// backfillInvoicesSent marks transactions completed from before invoices as invoiced, so they
+// don't all get emailed at once.
+func backfillInvoicesSent(db *gorm.DB) {
+	err := db.Model(&models.Transaction{}).
+		Where("transaction_status = ? AND invoice_sent_at IS NULL", models.TransactionStatusCompleted).
+		UpdateColumn("invoice_sent_at", gorm.Expr("updated_at")).
+		Error
+	if err != nil {
+		log.Fatalf("fatal: failed to backfill sent invoices: %v", err)
+	}
+}
+
+// backfillSubscriptionStarts starts subscriptions from before renewals when they were created.
+func backfillSubscriptionStarts(db *gorm.DB) {
+	err := db.Exec("UPDATE seller_subscriptions SET starts_at = created_at").Error
+	if err != nil {
+		log.Fatalf("fatal: failed to backfill subscription starts: %v", err)
+	}
+}
+
+// syncPermissions saves every permission the server checks for and gives them all to the admin role.
+func syncPermissions(db *gorm.DB) {
+	permissions := slices.Clone(models.Permissions)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"description"}),
+		}).Create(&permissions).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+			WHERE roles.id = ?
+			ON CONFLICT DO NOTHING`, models.ROLE_ADMIN).Error
+	})
+	if err != nil {
+		log.Fatalf("fatal: failed to sync permissions: %v", err)
+	}
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionUserSuspend          AuditAction = "user.suspend"
+	AuditActionUserBan              AuditAction = "user.ban"
+	AuditActionUserReinstate        AuditAction = "user.reinstate"
+	AuditActionUserRoles            AuditAction = "user.roles"
+	AuditActionSubscriptionExtend   AuditAction = "subscription.extend"
+	AuditActionSellerRequestApprove AuditAction = "seller_request.approve"
+	AuditActionSellerRequestReject  AuditAction = "seller_request.reject"
+	AuditActionRoleCreate           AuditAction = "role.create"
+	AuditActionRoleUpdate           AuditAction = "role.update"
+	AuditActionRoleDelete           AuditAction = "role.delete"
+	AuditActionRoleTwoFactor        AuditAction = "role.require_totp"
+)
+
+// AuditLog records something staff did to a user or to the roles, and why.
+type AuditLog struct {
+	ID           uint           `gorm:"primaryKey"`
+	ActorID      uint           `gorm:"not null;index"`
+	Actor        User           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"` // Staff with a trail can't be deleted.
+	Action       AuditAction    `gorm:"not null;size:50;index"`
+	TargetUserID *uint          `gorm:"index"`
+	TargetUser   *User          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Reason       *string        `gorm:"size:1000"`
+	Details      map[string]any `gorm:"serializer:json;type:text"`
+	CreatedAt    time.Time      `gorm:"not null;autoCreateTime;index"`
+}
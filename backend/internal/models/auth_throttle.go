@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// AuthThrottle counts recent failures of something like a login, keyed by what they count
+// against, such as an account or an IP. Lockouts get longer with every failure.
+type AuthThrottle struct {
+	ID            uint      `gorm:"primaryKey"`
+	Key           string    `gorm:"not null;uniqueIndex;size:200"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
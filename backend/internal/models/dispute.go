@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen     DisputeStatus = "open"
+	DisputeStatusResolved DisputeStatus = "resolved"
+)
+
+// DisputeResolution is the side an admin ruled in favour of.
+type DisputeResolution string
+
+const (
+	DisputeResolutionBuyer  DisputeResolution = "buyer"
+	DisputeResolutionSeller DisputeResolution = "seller"
+)
+
+type Dispute struct {
+	gorm.Model
+	TransactionID uint `gorm:"not null;index"`
+	Transaction   Transaction
+	OpenedByID    uint `gorm:"not null;index"`
+	OpenedBy      User
+	Reason        string        `gorm:"not null"`
+	Status        DisputeStatus `gorm:"not null;index"`
+
+	// The transaction status before it got frozen by the dispute.
+	PreviousStatus TransactionStatus `gorm:"not null"`
+
+	Resolution     *DisputeResolution
+	ResolutionNote *string
+	ResolvedByID   *uint
+	ResolvedBy     *User
+	ResolvedAt     *time.Time
+
+	Statements []DisputeStatement
+}
+
+// DisputeStatement is a statement by either party or an admin, optionally with a piece of evidence attached.
+type DisputeStatement struct {
+	gorm.Model
+	DisputeID     uint `gorm:"not null;index"`
+	AuthorID      uint `gorm:"not null;index"`
+	Author        User
+	Content       string  `gorm:"not null"`
+	AttachmentKey *string `gorm:"default:null"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// EmailChange is a request to change the email of a user, confirmed with a link sent to the new
+// address. The old email stays until then. Only the SHA-256 hash of the emailed token is stored.
+type EmailChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	NewEmail  string    `gorm:"not null;size:200"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiredAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PasswordReset is a request to reset a forgotten password. Requests for unknown emails are
+// kept as well, without a token, so every request counts towards the rate limits.
+type PasswordReset struct {
+	ID        uint   `gorm:"primaryKey"`
+	Email     string `gorm:"not null;size:200;index"`
+	RequestIP string `gorm:"not null;size:64;index"`
+	UserID    *uint  `gorm:"index"`
+	User      *User  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	// Only the SHA-256 hash of the emailed token is stored.
+	TokenHash *string   `gorm:"uniqueIndex"`
+	ExpiredAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentStatusRequiresPayment PaymentIntentStatus = "requires_payment"
+	PaymentIntentStatusSucceeded       PaymentIntentStatus = "succeeded"
+	PaymentIntentStatusFailed          PaymentIntentStatus = "failed"
+	PaymentIntentStatusRefunded        PaymentIntentStatus = "refunded"
+)
+
+// PaymentIntent is an attempt by the buyer to pay for a transaction through a payment provider.
+type PaymentIntent struct {
+	gorm.Model
+	TransactionID uint `gorm:"not null;index"`
+	Transaction   Transaction
+	Provider      string              `gorm:"not null"`
+	Reference     string              `gorm:"not null;uniqueIndex"`
+	Amount        int64               `gorm:"type:bigint;not null"`
+	Status        PaymentIntentStatus `gorm:"not null;default:requires_payment"`
+	CheckoutURL   string              `gorm:"not null"`
+	RefundedAt    *time.Time          `gorm:"default:null"`
+}
+
+// PaymentWebhookEvent records every webhook event that was processed, so retries
+// from the provider are only applied once.
+type PaymentWebhookEvent struct {
+	EventID     string    `gorm:"primaryKey"`
+	Provider    string    `gorm:"not null"`
+	Type        string    `gorm:"not null"`
+	ProcessedAt time.Time `gorm:"not null;autoCreateTime"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "gorm.io/gorm"
+
+type FeeKind string
+
+const (
+	FeeKindCommission FeeKind = "commission"
+	FeeKindFixed      FeeKind = "fixed"
+)
+
+// Payout is what the seller is owed for a completed transaction, after the platform's fees.
+type Payout struct {
+	gorm.Model
+	TransactionID uint `gorm:"not null;uniqueIndex"`
+	Transaction   Transaction
+	SellerID      uint `gorm:"not null;index"`
+	Seller        User
+	GrossAmount   int64 `gorm:"type:bigint;not null"`
+	FeeAmount     int64 `gorm:"type:bigint;not null"`
+	NetAmount     int64 `gorm:"type:bigint;not null"`
+	Fees          []FeeLineItem
+}
+
+// FeeLineItem is a single fee the platform took out of a payout.
+type FeeLineItem struct {
+	gorm.Model
+	PayoutID    uint    `gorm:"not null;index"`
+	Kind        FeeKind `gorm:"not null"`
+	Description string  `gorm:"not null"`
+	RateBPS     int64   `gorm:"not null;default:0"`
+	Amount      int64   `gorm:"type:bigint;not null"`
+}
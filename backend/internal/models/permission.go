@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	PERMISSION_USERS_READ          = "users.read"
+	PERMISSION_USERS_BAN           = "users.ban"
+	PERMISSION_AUDIT_READ          = "audit.read"
+	PERMISSION_ROLES_WRITE         = "roles.write"
+	PERMISSION_SELLERS_REVIEW      = "sellers.review"
+	PERMISSION_SUBSCRIPTIONS_WRITE = "subscriptions.write"
+	PERMISSION_CATEGORIES_WRITE    = "categories.write"
+	PERMISSION_DISPUTES_RESOLVE    = "disputes.resolve"
+	PERMISSION_RATINGS_MODERATE    = "ratings.moderate"
+	PERMISSION_REPORTS_READ        = "reports.read"
+	PERMISSION_PAYMENTS_CONFIRM    = "payments.confirm"
+)
+
+// Permission allows something on the admin side, granted to users through their roles.
+type Permission struct {
+	ID          string `gorm:"primaryKey"`
+	Description string
+	CreatedAt   time.Time
+}
+
+// Permissions is every permission the server checks for, synced to the database on startup.
+// The admin role always has all of them.
+var Permissions = []Permission{
+	{ID: PERMISSION_USERS_READ, Description: "Search users and view their dossiers"},
+	{ID: PERMISSION_USERS_BAN, Description: "Suspend, ban and reinstate users"},
+	{ID: PERMISSION_AUDIT_READ, Description: "View the audit trail"},
+	{ID: PERMISSION_ROLES_WRITE, Description: "Manage roles and assign them to users"},
+	{ID: PERMISSION_SELLERS_REVIEW, Description: "Approve or reject seller requests"},
+	{ID: PERMISSION_SUBSCRIPTIONS_WRITE, Description: "Extend seller subscriptions"},
+	{ID: PERMISSION_CATEGORIES_WRITE, Description: "Create, edit and delete categories"},
+	{ID: PERMISSION_DISPUTES_RESOLVE, Description: "View and resolve transaction disputes"},
+	{ID: PERMISSION_RATINGS_MODERATE, Description: "Moderate reported ratings"},
+	{ID: PERMISSION_REPORTS_READ, Description: "View revenue reports"},
+	{ID: PERMISSION_PAYMENTS_CONFIRM, Description: "Mark transactions as paid outside of the platform"},
+}
@@ -1,15 +1,44 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// A rating is either a like or a dislike.
+const (
+	RatingPositive int8 = 1
+	RatingNegative int8 = -1
+)
+
+// Rating is what one party of a transaction thought of the other. Each side can rate the
+// other once per transaction. Ratings from before they were tied to transactions have none.
 type Rating struct {
 	gorm.Model
-	Rating     uint   `gorm:"not null"`
-	Feedback   string `gorm:"not null"`
-	ProductID  uint   `gorm:"not null;index"`
-	Product    Product
-	ReviewerID uint `gorm:"not null;index"`
-	Reviewer   User
-	RevieweeID uint `gorm:"not null;index"`
-	Reviewee   User
+	Rating        int8   `gorm:"not null;check:rating IN (-1, 1)"`
+	Feedback      string `gorm:"not null"`
+	TransactionID *uint  `gorm:"uniqueIndex:idx_ratings_transaction_reviewer,priority:1,where:deleted_at IS NULL"`
+	Transaction   *Transaction
+	ProductID     uint `gorm:"not null;index"`
+	Product       Product
+	ReviewerID    uint `gorm:"not null;index;uniqueIndex:idx_ratings_transaction_reviewer,priority:2"`
+	Reviewer      User
+	RevieweeID    uint `gorm:"not null;index"`
+	Reviewee      User
+
+	// The reviewee gets a single reply to the rating.
+	Reply     *string
+	RepliedAt *time.Time
+
+	// Set when an admin hid the feedback for being abusive. The rating itself still counts.
+	FeedbackHiddenAt *time.Time
+}
+
+// VisibleFeedback is the feedback as it's shown to users.
+func (r *Rating) VisibleFeedback() string {
+	if r.FeedbackHiddenAt != nil {
+		return ""
+	}
+	return r.Feedback
 }
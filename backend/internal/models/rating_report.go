@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RatingReportStatus string
+
+const (
+	RatingReportStatusOpen     RatingReportStatus = "open"
+	RatingReportStatusResolved RatingReportStatus = "resolved"
+)
+
+// ModerationAction is what an admin did about a reported rating.
+type ModerationAction string
+
+const (
+	ModerationActionDismiss ModerationAction = "dismiss"
+	ModerationActionHide    ModerationAction = "hide"
+	ModerationActionRemove  ModerationAction = "remove"
+)
+
+// RatingReport is a user flagging a rating as abusive. Every user can report a rating once.
+type RatingReport struct {
+	gorm.Model
+	RatingID   uint `gorm:"not null;uniqueIndex:idx_rating_reports_rating_reporter"`
+	Rating     Rating
+	ReporterID uint `gorm:"not null;uniqueIndex:idx_rating_reports_rating_reporter"`
+	Reporter   User
+	Reason     string             `gorm:"not null"`
+	Status     RatingReportStatus `gorm:"not null;index"`
+
+	// Resolving a report resolves every open report of the same rating.
+	Action       *ModerationAction
+	ActionReason *string
+	ResolvedByID *uint
+	ResolvedBy   *User
+	ResolvedAt   *time.Time
+}
@@ -2,13 +2,22 @@ package models
 
 import "time"
 
+// RefreshToken is one link of a session. Every refresh rotates the token, and the new one stays
+// in the family of the login that started the session.
 type RefreshToken struct {
 	ID           uint      `gorm:"column:id;primaryKey;autoIncrement"`
 	UserID       uint      `gorm:"column:user_id;index"`
 	User         User      `gorm:""`
 	RefreshToken string    `gorm:"column:refresh_token;not null;uniqueIndex"`
+	FamilyID     string    `gorm:"column:family_id;size:64;not null;default:'';index"`
 	IsRevoked    bool      `gorm:"column:is_revoked;not null;default:false"`
 	ExpiredAt    time.Time `gorm:"column:expired_at;not null"`
 	CreatedAt    time.Time `gorm:"column:created_at;not null;autoCreateTime"`
 	UpdatedAt    time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+
+	// Where the session is used from, updated on every refresh.
+	UserAgent  string `gorm:"column:user_agent;size:512;not null;default:''"`
+	IPAddress  string `gorm:"column:ip_address;size:64;not null;default:''"`
+	SignedInAt *time.Time
+	LastUsedAt *time.Time
 }
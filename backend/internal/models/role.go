@@ -12,4 +12,9 @@ type Role struct {
 	Description string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// Users without two-factor authentication don't get the role in their access tokens.
+	RequireTOTP bool `gorm:"not null;default:false"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SellerRequestStatus string
+
+const (
+	SellerRequestStatusPending  SellerRequestStatus = "pending"
+	SellerRequestStatusApproved SellerRequestStatus = "approved"
+	SellerRequestStatusRejected SellerRequestStatus = "rejected"
+)
+
+// SellerRequest is a bidder asking an admin for seller privileges. A user has at most one pending
+// request, and older ones are kept as their history.
+type SellerRequest struct {
+	gorm.Model
+	UserID  uint `gorm:"not null;index"`
+	User    User
+	Message string              `gorm:"not null;default:''"`
+	Plan    string              `gorm:"not null;default:''"`
+	Status  SellerRequestStatus `gorm:"not null;index;check:status in ('pending','approved','rejected')"`
+
+	// Set once an admin approves or rejects it. Rejections always have a reason.
+	ReviewerID *uint
+	Reviewer   *User
+	Reason     *string
+	ReviewedAt *time.Time
+}
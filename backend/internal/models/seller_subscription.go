@@ -6,9 +6,31 @@ import (
 	"gorm.io/gorm"
 )
 
+// SubscriptionSource is how a seller got a subscription.
+type SubscriptionSource string
+
+const (
+	SubscriptionSourceRequest   SubscriptionSource = "request"
+	SubscriptionSourceExtension SubscriptionSource = "extension"
+)
+
+// SellerSubscription lets a user sell from when it starts until it expires. Renewals and extensions
+// start when the current one expires, so every subscription a user had is kept.
 type SellerSubscription struct {
 	gorm.Model
+	StartsAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
 	ExpiredAt time.Time `gorm:"not null;index"`
 	UserID    uint      `gorm:"not null"`
 	User      User
+	Plan      string             `gorm:"not null;default:''"`
+	Source    SubscriptionSource `gorm:"not null;default:request;check:source in ('request','extension')"`
+
+	// The request it was approved from, or the admin that extended it.
+	SellerRequestID *uint
+	SellerRequest   *SellerRequest
+	GrantedByID     *uint
+	GrantedBy       *User
+	Note            *string
+
+	ReminderSentAt *time.Time
 }
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type TransactionStatus string
 
@@ -10,6 +14,7 @@ const (
 	TransactionStatusDelivered  TransactionStatus = "delivered"
 	TransactionStatusCompleted  TransactionStatus = "completed"
 	TransactionStatusCancelled  TransactionStatus = "cancelled"
+	TransactionStatusDisputed   TransactionStatus = "disputed"
 )
 
 type Transaction struct {
@@ -22,4 +27,19 @@ type Transaction struct {
 	Seller            User
 	FinalPrice        int64
 	TransactionStatus TransactionStatus
+
+	// Shipping details, submitted by the buyer and completed by the seller on delivery.
+	ShippingAddress     *string
+	ShippingConfirmedAt *time.Time
+	ShippingCarrier     *string
+	TrackingNumber      *string
+	DeliveredAt         *time.Time
+
+	// When the reminders for the payment and delivery deadlines were sent, so they only go out once.
+	PaymentReminderSentAt  *time.Time
+	DeliveryReminderSentAt *time.Time
+
+	// Where the invoice is stored, without the extension, and when it was emailed to both parties.
+	InvoiceKey    *string
+	InvoiceSentAt *time.Time
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one time code that signs in instead of TOTP, for when the authenticator is lost.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CodeHash  string `gorm:"not null;size:64"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+// TwoFactorChallenge is the second step of a login, given out after the password was right. Only
+// the SHA-256 hash of its token is stored, and it only takes a few wrong codes.
+type TwoFactorChallenge struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiredAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
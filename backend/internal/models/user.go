@@ -14,14 +14,29 @@ type User struct {
 	Address      *string
 	AvatarURL    *string
 	OauthType    string     `gorm:"column:oauth_type;not null;default:none;check:oauth_type in ('google','none')"`
+	OauthSubject *string    `gorm:"column:oauth_subject;uniqueIndex"`
 	Verified     bool       `gorm:"column:verified;not null;default:false"`
-	OTPCode      *string    `gorm:"column:otp_code;size:10"`
+	OTPHash      *string    `gorm:"column:otp_hash;size:64"` // SHA-256 of the code, cleared after too many wrong ones.
 	OTPExpiredAt *time.Time `gorm:"column:otp_expired_at"`
-	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime;not null"`
-	UpdatedAt    time.Time  `gorm:"column:updated_at;autoUpdateTime;not null"`
+	OTPAttempts  int        `gorm:"column:otp_attempts;not null;default:0"`
+	TokenVersion int64      `gorm:"column:token_version;not null;default:0"` // Bumped to revoke every access token.
 
-	AverageRating   float64 `gorm:"not null;default:0"`
-	WaitingApproval bool    `gorm:"not null;default:false"`
+	// TOTP two-factor authentication. The secret is kept while setting up, but only asked for
+	// once enabled. The last used time step can't be used again.
+	TOTPSecret   *string   `gorm:"column:totp_secret;size:64"`
+	TOTPEnabled  bool      `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep int64     `gorm:"column:totp_last_step;not null;default:0"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime;not null"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime;not null"`
+
+	// Suspended users can't sign in or use their account until the time passes, banned ones never.
+	SuspendedUntil   *time.Time `gorm:"column:suspended_until"`
+	Banned           bool       `gorm:"column:banned;not null;default:false"`
+	SuspensionReason *string    `gorm:"column:suspension_reason;size:1000"`
+
+	// Likes and dislikes received from other users.
+	PositiveRatings int64 `gorm:"not null;default:0"`
+	NegativeRatings int64 `gorm:"not null;default:0"`
 
 	RefreshTokens    []RefreshToken       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Roles            []Role               `gorm:"many2many:user_roles"`
@@ -30,3 +45,17 @@ type User struct {
 	Ratings          []Rating             `gorm:"foreignKey:ReviewerID"`
 	RatedRatings     []Rating             `gorm:"foreignKey:RevieweeID"`
 }
+
+// IsSuspended reports whether the user is banned, or suspended at a time.
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.Banned || (u.SuspendedUntil != nil && u.SuspendedUntil.After(now))
+}
+
+// Reputation is the percentage of ratings that are likes, or 0 if the user hasn't been rated yet.
+func (u *User) Reputation() float64 {
+	total := u.PositiveRatings + u.NegativeRatings
+	if total == 0 {
+		return 0
+	}
+	return float64(u.PositiveRatings) * 100 / float64(total)
+}
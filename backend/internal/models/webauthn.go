@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey registered by a user. The public key is stored as PKIX DER.
+type WebAuthnCredential struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;index"`
+	User         User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CredentialID string `gorm:"not null;uniqueIndex;size:1400"` // base64url
+	PublicKey    []byte `gorm:"not null"`
+	Algorithm    int    `gorm:"not null"`
+	SignCount    int64  `gorm:"not null;default:0"`
+	AAGUID       []byte
+	Name         string `gorm:"not null;size:100"`
+	LastUsedAt   *time.Time
+	CreatedAt    time.Time `gorm:"not null;autoCreateTime"`
+}
+
+// WebAuthnChallenge is the challenge of a registration or login ceremony, only answered once.
+// Registrations are tied to the user starting them, logins find their user from the credential.
+type WebAuthnChallenge struct {
+	ID        uint      `gorm:"primaryKey"`
+	Challenge string    `gorm:"not null;uniqueIndex"`
+	Ceremony  string    `gorm:"not null;size:20;check:ceremony in ('registration','login')"`
+	UserID    *uint     `gorm:"index"`
+	User      *User     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ExpiredAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+// AuditLogFilter narrows down the audit trail, empty fields match everything.
+type AuditLogFilter struct {
+	ActorID      uint
+	TargetUserID uint
+	Action       models.AuditAction
+}
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+func (r *AuditLogRepository) CreateAuditLog(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Omit("Actor", "TargetUser").Create(entry).Error
+}
+
+func (r *AuditLogRepository) filterAuditLogs(ctx context.Context, filter AuditLogFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetUserID != 0 {
+		query = query.Where("target_user_id = ?", filter.TargetUserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	return query
+}
+
+// GetAuditLogs retrieves the audit trail, newest first.
+func (r *AuditLogRepository) GetAuditLogs(ctx context.Context, filter AuditLogFilter, limit int, offset int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	err := r.filterAuditLogs(ctx, filter).
+		Preload("Actor").
+		Preload("TargetUser").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).
+		Error
+	return entries, err
+}
+
+func (r *AuditLogRepository) CountAuditLogs(ctx context.Context, filter AuditLogFilter) (int64, error) {
+	var count int64
+	err := r.filterAuditLogs(ctx, filter).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+type AuthThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthThrottleRepository(db *gorm.DB) *AuthThrottleRepository {
+	return &AuthThrottleRepository{
+		db: db,
+	}
+}
+
+// GetLockedThrottles finds the throttles of the keys that are locked out right now.
+func (r *AuthThrottleRepository) GetLockedThrottles(ctx context.Context, keys []string) ([]models.AuthThrottle, error) {
+	var throttles []models.AuthThrottle
+	err := r.db.WithContext(ctx).
+		Where("key IN ? AND locked_until > ?", keys, time.Now()).
+		Find(&throttles).
+		Error
+	return throttles, err
+}
+
+// RecordFailure counts a failure against a key, starting over if the last one was before the
+// window. Returns the throttle with the new count.
+func (r *AuthThrottleRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (models.AuthThrottle, error) {
+	var throttle models.AuthThrottle
+	now := time.Now()
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO auth_throttles (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN auth_throttles.last_failure_at < ? THEN 1 ELSE auth_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING *`, key, now, now.Add(-window)).
+		Scan(&throttle).
+		Error
+	return throttle, err
+}
+
+// ReserveAttempt counts an attempt against a key before it's checked, unless the key is locked out
+// right now. It starts over if the last attempt was before the window, then locks the key out for
+// as long as lockout says for the new count. The row is locked while this happens, so concurrent
+// attempts each get their own count.
+//
+// Returns the throttle, and whether the attempt was counted. It isn't if the key is locked out.
+func (r *AuthThrottleRepository) ReserveAttempt(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+	lockout func(failures int) time.Duration,
+) (models.AuthThrottle, bool, error) {
+	var throttle models.AuthThrottle
+	reserved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		db := tx.Raw(`
+			INSERT INTO auth_throttles (key, failures, last_failure_at)
+			VALUES (?, 1, ?)
+			ON CONFLICT (key) DO UPDATE SET
+				failures = CASE WHEN auth_throttles.last_failure_at < ? THEN 1 ELSE auth_throttles.failures + 1 END,
+				last_failure_at = EXCLUDED.last_failure_at
+			WHERE auth_throttles.locked_until IS NULL OR auth_throttles.locked_until <= ?
+			RETURNING *`, key, now, now.Add(-window), now).
+			Scan(&throttle)
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return tx.Where("key = ?", key).First(&throttle).Error
+		}
+		reserved = true
+
+		if d := lockout(throttle.Failures); d > 0 {
+			until := now.Add(d)
+			throttle.LockedUntil = &until
+			return tx.Model(&throttle).Update("locked_until", until).Error
+		}
+		return nil
+	})
+	return throttle, reserved, err
+}
+
+// LockUntil locks a throttle out until a point in time.
+func (r *AuthThrottleRepository) LockUntil(ctx context.Context, id uint, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AuthThrottle{}).
+		Where("id = ?", id).
+		Update("locked_until", until).
+		Error
+}
+
+// Reset forgets the failures of a key.
+func (r *AuthThrottleRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&models.AuthThrottle{}).Error
+}
+
+// DeleteStaleThrottles deletes throttles that haven't failed since a point in time, and aren't
+// locked anymore.
+func (r *AuthThrottleRepository) DeleteStaleThrottles(ctx context.Context, before time.Time) (int, error) {
+	db := r.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now()).
+		Delete(&models.AuthThrottle{})
+	return int(db.RowsAffected), db.Error
+}
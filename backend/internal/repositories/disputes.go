@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var (
+	ErrDisputeNotAllowed = errors.New("transaction can't be disputed at this stage")
+	ErrDisputeResolved   = errors.New("dispute is already resolved")
+)
+
+type DisputeRepository struct {
+	db *gorm.DB
+}
+
+func NewDisputeRepository(db *gorm.DB) *DisputeRepository {
+	return &DisputeRepository{
+		db: db,
+	}
+}
+
+// OpenDispute creates a dispute on a paid or delivered transaction, and freezes
+// the transaction until an admin resolves it.
+func (r *DisputeRepository) OpenDispute(ctx context.Context, dispute *models.Dispute) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Transaction{}).
+			Where("id = ?", dispute.TransactionID).
+			First(&transaction).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if transaction.TransactionStatus != models.TransactionStatusWinnerPaid && transaction.TransactionStatus != models.TransactionStatusDelivered {
+			return ErrDisputeNotAllowed
+		}
+
+		dispute.Status = models.DisputeStatusOpen
+		dispute.PreviousStatus = transaction.TransactionStatus
+		if err := tx.Create(dispute).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Transaction{}).
+			Where("id = ?", transaction.ID).
+			Update("transaction_status", models.TransactionStatusDisputed).
+			Error
+	})
+}
+
+func (r *DisputeRepository) GetDisputeByID(ctx context.Context, id uint) (models.Dispute, error) {
+	var dispute models.Dispute
+	err := r.db.WithContext(ctx).
+		Model(&models.Dispute{}).
+		Preload("Transaction.Product.ChatSession").
+		Preload("OpenedBy").
+		Preload("ResolvedBy").
+		Preload("Statements", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Statements.Author").
+		Where("id = ?", id).
+		First(&dispute).
+		Error
+	return dispute, err
+}
+
+// GetDisputes retrieves disputes with a status, oldest first so the queue is worked in order.
+func (r *DisputeRepository) GetDisputes(ctx context.Context, status models.DisputeStatus, limit int, offset int) ([]models.Dispute, error) {
+	var disputes []models.Dispute
+	err := r.db.WithContext(ctx).
+		Model(&models.Dispute{}).
+		Preload("Transaction").
+		Preload("OpenedBy").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&disputes).
+		Error
+	return disputes, err
+}
+
+func (r *DisputeRepository) CountDisputes(ctx context.Context, status models.DisputeStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Dispute{}).
+		Where("status = ?", status).
+		Count(&count).
+		Error
+	return count, err
+}
+
+func (r *DisputeRepository) CreateDisputeStatement(ctx context.Context, statement *models.DisputeStatement) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DisputeStatement{}).
+		Create(statement).
+		Error
+}
+
+// ResolveDispute resolves an open dispute in favour of one side. The transaction is completed
+// if the seller wins, or cancelled if the buyer wins, and the losing side gets a negative rating
+// from the other, replacing any rating they already gave for the product.
+func (r *DisputeRepository) ResolveDispute(
+	ctx context.Context,
+	id uint,
+	adminID uint,
+	resolution models.DisputeResolution,
+	note string,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var dispute models.Dispute
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Dispute{}).
+			Preload("Transaction").
+			Where("id = ?", id).
+			First(&dispute).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if dispute.Status != models.DisputeStatusOpen {
+			return ErrDisputeResolved
+		}
+
+		err = tx.Model(&dispute).Updates(map[string]any{
+			"status":          models.DisputeStatusResolved,
+			"resolution":      resolution,
+			"resolution_note": note,
+			"resolved_by_id":  adminID,
+			"resolved_at":     time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		transaction := dispute.Transaction
+		status := models.TransactionStatusCompleted
+		winnerID, loserID := transaction.SellerID, transaction.BuyerID
+		if resolution == models.DisputeResolutionBuyer {
+			status = models.TransactionStatusCancelled
+			winnerID, loserID = transaction.BuyerID, transaction.SellerID
+		}
+
+		err = tx.Model(&models.Transaction{}).
+			Where("id = ?", transaction.ID).
+			Update("transaction_status", status).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Product{}).
+			Where("id = ?", transaction.ProductID).
+			Update("finalized_at", time.Now()).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// Replace whatever the winner thought of the loser with the ruling.
+		err = tx.Where("transaction_id = ? AND reviewer_id = ?", transaction.ID, winnerID).
+			Delete(&models.Rating{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		rating := models.Rating{
+			TransactionID: &transaction.ID,
+			ProductID:     transaction.ProductID,
+			ReviewerID:    winnerID,
+			RevieweeID:    loserID,
+			Rating:        models.RatingNegative,
+			Feedback:      "Lost a dispute on this transaction",
+		}
+		if err := tx.Create(&rating).Error; err != nil {
+			return err
+		}
+
+		return updateRatingCounters(tx, loserID)
+	})
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var (
+	ErrEmailChangeInvalid = errors.New("invalid or expired email change")
+	ErrEmailInUse         = errors.New("email is already in use")
+)
+
+type EmailChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailChangeRepository(db *gorm.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{
+		db: db,
+	}
+}
+
+// CreateEmailChange creates a new email change, replacing any pending one of the user.
+func (r *EmailChangeRepository) CreateEmailChange(ctx context.Context, change *models.EmailChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.EmailChange{}).
+			Where("user_id = ? AND used_at IS NULL", change.UserID).
+			Update("expired_at", time.Now()).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+}
+
+// CountEmailChanges counts the email changes a user requested since a point in time.
+func (r *EmailChangeRepository) CountEmailChanges(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.EmailChange{}).
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// ConfirmEmailChange uses a token to change the email of its user, returning the change with the
+// user as it was before. The new email counts as verified, and access tokens are revoked since
+// they carry the old one.
+func (r *EmailChangeRepository) ConfirmEmailChange(ctx context.Context, tokenHash string) (models.EmailChange, error) {
+	var change models.EmailChange
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("User").
+			Where("token_hash = ?", tokenHash).
+			First(&change).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmailChangeInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if change.UsedAt != nil || change.ExpiredAt.Before(now) {
+			return ErrEmailChangeInvalid
+		}
+
+		var taken int64
+		err = tx.Model(&models.User{}).Where("email = ?", change.NewEmail).Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrEmailInUse
+		}
+
+		err = tx.Model(&models.User{}).
+			Where("id = ?", change.UserID).
+			Updates(map[string]any{"email": change.NewEmail, "verified": true, "token_version": gorm.Expr("token_version + 1")}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.EmailChange{}).Where("id = ?", change.ID).Update("used_at", now).Error
+	})
+	return change, err
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var (
+	ErrResetTokenInvalid = errors.New("invalid or expired reset token")
+	ErrTooManyResets     = errors.New("too many password reset requests")
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+// CreatePasswordReset records a reset request, unless there were already as many for its email or
+// from its IP since a point in time. Requests for the same email or IP wait on each other, so
+// concurrent ones can't all squeeze in under the limits.
+func (r *PasswordResetRepository) CreatePasswordReset(ctx context.Context, reset *models.PasswordReset, since time.Time, maxPerEmail int64, maxPerIP int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Always the email first, then the IP, so requests never deadlock on each other.
+		for _, key := range []string{"password_reset:email:" + reset.Email, "password_reset:ip:" + reset.RequestIP} {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+				return err
+			}
+		}
+
+		var byEmail, byIP int64
+		err := tx.Model(&models.PasswordReset{}).
+			Where("email = ? AND created_at > ?", reset.Email, since).
+			Count(&byEmail).
+			Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.PasswordReset{}).
+			Where("request_ip = ? AND created_at > ?", reset.RequestIP, since).
+			Count(&byIP).
+			Error
+		if err != nil {
+			return err
+		}
+		if byEmail >= maxPerEmail || byIP >= maxPerIP {
+			return ErrTooManyResets
+		}
+
+		return tx.Create(reset).Error
+	})
+}
+
+// ResetPassword uses a reset token to change the password of its user. Every other pending
+// reset of the user is used up with it, and all of their refresh tokens are revoked.
+func (r *PasswordResetRepository) ResetPassword(ctx context.Context, tokenHash string, hashedPassword string) (uint, error) {
+	var userID uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordReset
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(&reset).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResetTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if reset.UserID == nil || reset.UsedAt != nil || reset.ExpiredAt.Before(now) {
+			return ErrResetTokenInvalid
+		}
+		userID = *reset.UserID
+
+		err = tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{"password": hashedPassword, "token_version": gorm.Expr("token_version + 1")}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = ?", userID, false).
+			Update("is_revoked", true).
+			Error
+	})
+	return userID, err
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var ErrPaymentEventProcessed = errors.New("payment webhook event was already processed")
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+// CreatePaymentIntent saves a new payment intent returned by a provider.
+func (r *PaymentRepository) CreatePaymentIntent(ctx context.Context, intent *models.PaymentIntent) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Create(intent).
+		Error
+}
+
+// GetOpenPaymentIntent retrieves a payment intent of the transaction that is still waiting for payment.
+func (r *PaymentRepository) GetOpenPaymentIntent(ctx context.Context, transactionID uint) (models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Where("transaction_id = ?", transactionID).
+		Where("status = ?", models.PaymentIntentStatusRequiresPayment).
+		Order("created_at DESC").
+		First(&intent).
+		Error
+	return intent, err
+}
+
+func (r *PaymentRepository) GetPaymentIntentByReference(ctx context.Context, reference string) (models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Preload("Transaction").
+		Where("reference = ?", reference).
+		First(&intent).
+		Error
+	return intent, err
+}
+
+// GetSucceededPaymentIntents retrieves all payments of a transaction that were captured and not refunded yet.
+func (r *PaymentRepository) GetSucceededPaymentIntents(ctx context.Context, transactionID uint) ([]models.PaymentIntent, error) {
+	var intents []models.PaymentIntent
+	err := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Where("transaction_id = ?", transactionID).
+		Where("status = ?", models.PaymentIntentStatusSucceeded).
+		Find(&intents).
+		Error
+	return intents, err
+}
+
+// GetUnrefundedTransactionIDs retrieves the IDs of cancelled transactions that still have captured payments.
+func (r *PaymentRepository) GetUnrefundedTransactionIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Joins("JOIN transactions ON transactions.id = payment_intents.transaction_id").
+		Where("transactions.transaction_status = ?", models.TransactionStatusCancelled).
+		Where("payment_intents.status = ?", models.PaymentIntentStatusSucceeded).
+		Distinct().
+		Pluck("payment_intents.transaction_id", &ids).
+		Error
+	return ids, err
+}
+
+// MarkPaymentIntentRefunded marks a payment intent as refunded as of now.
+func (r *PaymentRepository) MarkPaymentIntentRefunded(ctx context.Context, id uint) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Where("id = ?", id).
+		Where("status = ?", models.PaymentIntentStatusSucceeded).
+		Updates(map[string]any{
+			"status":      models.PaymentIntentStatusRefunded,
+			"refunded_at": time.Now(),
+		})
+	return db.RowsAffected, db.Error
+}
+
+// ApplyPaymentEvent records a webhook event and moves the payment intent to the new status.
+// When the payment succeeded, the transaction is moved from pending to paid.
+//
+// Returns ErrPaymentEventProcessed if the event was seen before, so provider retries are no-ops.
+// The returned intent has its transaction preloaded as of after the update.
+func (r *PaymentRepository) ApplyPaymentEvent(
+	ctx context.Context,
+	event *models.PaymentWebhookEvent,
+	reference string,
+	status models.PaymentIntentStatus,
+) (models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrPaymentEventProcessed
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.PaymentIntent{}).
+			Where("reference = ?", reference).
+			First(&intent).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// Only an open intent can move, a late failure can't undo a captured payment.
+		if intent.Status == models.PaymentIntentStatusRequiresPayment {
+			err = tx.Model(&intent).Update("status", status).Error
+			if err != nil {
+				return err
+			}
+			intent.Status = status
+		}
+
+		if intent.Status == models.PaymentIntentStatusSucceeded {
+			err = tx.Model(&models.Transaction{}).
+				Where("id = ?", intent.TransactionID).
+				Where("transaction_status = ?", models.TransactionStatusPending).
+				Update("transaction_status", models.TransactionStatusWinnerPaid).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Transaction{}).
+			Preload("Product.ChatSession").
+			Where("id = ?", intent.TransactionID).
+			First(&intent.Transaction).
+			Error
+	})
+	return intent, err
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+type PayoutRepository struct {
+	db *gorm.DB
+}
+
+func NewPayoutRepository(db *gorm.DB) *PayoutRepository {
+	return &PayoutRepository{
+		db: db,
+	}
+}
+
+// PayoutTotals are the sums of a set of payouts.
+type PayoutTotals struct {
+	Count       int64
+	GrossAmount int64
+	FeeAmount   int64
+	NetAmount   int64
+}
+
+// RevenueBucket is what the platform earned over a period of time.
+type RevenueBucket struct {
+	Period time.Time
+	PayoutTotals
+}
+
+// GetTransactionForSettlement retrieves a transaction with what's needed to compute its fees.
+func (r *PayoutRepository) GetTransactionForSettlement(ctx context.Context, transactionID uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Preload("Product.Categories").
+		Where("id = ?", transactionID).
+		First(&transaction).
+		Error
+	return transaction, err
+}
+
+// CreatePayout saves a payout with its fee line items. If the transaction already has a payout,
+// nothing is saved.
+func (r *PayoutRepository) CreatePayout(ctx context.Context, payout *models.Payout) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fees := payout.Fees
+		db := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "transaction_id"}}, DoNothing: true}).
+			Omit("Fees").
+			Create(payout)
+		if db.Error != nil || db.RowsAffected == 0 {
+			return db.Error
+		}
+
+		if len(fees) == 0 {
+			return nil
+		}
+
+		for i := range fees {
+			fees[i].PayoutID = payout.ID
+		}
+		return tx.Create(&fees).Error
+	})
+}
+
+// GetPayoutByTransactionID retrieves the payout of a transaction with its fees.
+func (r *PayoutRepository) GetPayoutByTransactionID(ctx context.Context, transactionID uint) (models.Payout, error) {
+	var payout models.Payout
+	err := r.db.WithContext(ctx).
+		Model(&models.Payout{}).
+		Preload("Fees", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("transaction_id = ?", transactionID).
+		First(&payout).
+		Error
+	return payout, err
+}
+
+// GetUnsettledTransactionIDs retrieves the IDs of completed transactions without a payout.
+func (r *PayoutRepository) GetUnsettledTransactionIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Joins("LEFT JOIN payouts ON payouts.transaction_id = transactions.id AND payouts.deleted_at IS NULL").
+		Where("transactions.transaction_status = ?", models.TransactionStatusCompleted).
+		Where("payouts.id IS NULL").
+		Pluck("transactions.id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *PayoutRepository) filterPayouts(ctx context.Context, sellerID *uint, from *time.Time, to *time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Payout{})
+	if sellerID != nil {
+		query = query.Where("seller_id = ?", *sellerID)
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	return query
+}
+
+// GetSellerPayouts retrieves the payouts of a seller in a date range, newest first.
+func (r *PayoutRepository) GetSellerPayouts(ctx context.Context, sellerID uint, from *time.Time, to *time.Time, limit int, offset int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	err := r.filterPayouts(ctx, &sellerID, from, to).
+		Preload("Fees").
+		Preload("Transaction.Product").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&payouts).
+		Error
+	return payouts, err
+}
+
+// SumSellerPayouts sums up the payouts of a seller in a date range.
+func (r *PayoutRepository) SumSellerPayouts(ctx context.Context, sellerID uint, from *time.Time, to *time.Time) (PayoutTotals, error) {
+	var totals PayoutTotals
+	err := r.filterPayouts(ctx, &sellerID, from, to).
+		Select("COUNT(*) AS count, COALESCE(SUM(gross_amount), 0) AS gross_amount, COALESCE(SUM(fee_amount), 0) AS fee_amount, COALESCE(SUM(net_amount), 0) AS net_amount").
+		Scan(&totals).
+		Error
+	return totals, err
+}
+
+// GetRevenueReport sums up all payouts in a date range, grouped by an interval
+// that Postgres' date_trunc understands (day, week, month).
+func (r *PayoutRepository) GetRevenueReport(ctx context.Context, interval string, from *time.Time, to *time.Time) ([]RevenueBucket, error) {
+	var buckets []RevenueBucket
+	err := r.filterPayouts(ctx, nil, from, to).
+		Select("date_trunc(?, created_at) AS period, COUNT(*) AS count, SUM(gross_amount) AS gross_amount, SUM(fee_amount) AS fee_amount, SUM(net_amount) AS net_amount", interval).
+		Group("1").
+		Order("1 ASC").
+		Scan(&buckets).
+		Error
+	return buckets, err
+}
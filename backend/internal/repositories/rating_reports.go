@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var (
+	ErrAlreadyReported = errors.New("already reported this rating")
+	ErrReportResolved  = errors.New("report is already resolved")
+)
+
+type RatingReportRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingReportRepository(db *gorm.DB) *RatingReportRepository {
+	return &RatingReportRepository{
+		db: db,
+	}
+}
+
+// CreateReport reports a rating, once per reporter.
+func (r *RatingReportRepository) CreateReport(ctx context.Context, report *models.RatingReport) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.RatingReport{}).
+			Where("rating_id = ? AND reporter_id = ?", report.RatingID, report.ReporterID).
+			Count(&count).
+			Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyReported
+		}
+
+		report.Status = models.RatingReportStatusOpen
+		return tx.Create(report).Error
+	})
+}
+
+func (r *RatingReportRepository) GetReportByID(ctx context.Context, id uint) (models.RatingReport, error) {
+	var report models.RatingReport
+	err := r.db.WithContext(ctx).
+		Model(&models.RatingReport{}).
+		Where("id = ?", id).
+		First(&report).
+		Error
+	return report, err
+}
+
+// GetReports retrieves reports with a status, oldest first so the queue is worked in order.
+// Removed ratings are still included so resolved reports make sense.
+func (r *RatingReportRepository) GetReports(ctx context.Context, status models.RatingReportStatus, limit int, offset int) ([]models.RatingReport, error) {
+	var reports []models.RatingReport
+	err := r.db.WithContext(ctx).
+		Model(&models.RatingReport{}).
+		Preload("Rating", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Rating.Reviewer").
+		Preload("Rating.Reviewee").
+		Preload("Reporter").
+		Preload("ResolvedBy").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reports).
+		Error
+	return reports, err
+}
+
+func (r *RatingReportRepository) CountReports(ctx context.Context, status models.RatingReportStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.RatingReport{}).
+		Where("status = ?", status).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// ModerateRating resolves an open report, applying the action to the reported rating and resolving
+// every other open report of it with the same reason. Removing a rating recomputes the reputation
+// of the reviewee. Reports of a rating that was deleted since are only resolved.
+func (r *RatingReportRepository) ModerateRating(
+	ctx context.Context,
+	reportID uint,
+	adminID uint,
+	action models.ModerationAction,
+	reason string,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var report models.RatingReport
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.RatingReport{}).
+			Where("id = ?", reportID).
+			First(&report).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if report.Status != models.RatingReportStatusOpen {
+			return ErrReportResolved
+		}
+
+		// The rating could have been deleted since, its reports still need resolving.
+		var rating models.Rating
+		err = tx.Unscoped().
+			Model(&models.Rating{}).
+			Where("id = ?", report.RatingID).
+			First(&rating).
+			Error
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case rating.DeletedAt.Valid:
+			// Already gone, there's nothing to apply.
+		case action == models.ModerationActionHide:
+			err = tx.Model(&rating).UpdateColumn("feedback_hidden_at", time.Now()).Error
+		case action == models.ModerationActionRemove:
+			if err = tx.Delete(&rating).Error; err == nil {
+				err = updateRatingCounters(tx, rating.RevieweeID)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RatingReport{}).
+			Where("rating_id = ? AND status = ?", rating.ID, models.RatingReportStatusOpen).
+			Updates(map[string]any{
+				"status":         models.RatingReportStatusResolved,
+				"action":         action,
+				"action_reason":  reason,
+				"resolved_by_id": adminID,
+				"resolved_at":    time.Now(),
+			}).
+			Error
+	})
+}
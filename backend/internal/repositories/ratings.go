@@ -2,11 +2,20 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
+var (
+	ErrRatingNotOpen       = errors.New("transaction can't be rated until it's completed or cancelled")
+	ErrNotTransactionParty = errors.New("not a party of the transaction")
+	ErrAlreadyRated        = errors.New("already rated this transaction")
+)
+
 type RatingRepostory struct {
 	db *gorm.DB
 }
@@ -19,6 +28,15 @@ func NewRatingRepository(
 	}
 }
 
+// updateRatingCounters recounts the likes and dislikes received by users.
+func updateRatingCounters(tx *gorm.DB, userIDs ...uint) error {
+	return tx.Exec(`
+		UPDATE users SET
+			positive_ratings = (SELECT COUNT(*) FROM ratings WHERE reviewee_id = users.id AND rating > 0 AND deleted_at IS NULL),
+			negative_ratings = (SELECT COUNT(*) FROM ratings WHERE reviewee_id = users.id AND rating < 0 AND deleted_at IS NULL)
+		WHERE id IN ?`, userIDs).Error
+}
+
 // GetMyRatings retrieves a user's list of ratings (they are the reviewer)
 func (r *RatingRepostory) GetMyRatings(ctx context.Context, userID uint, limit int, offset int) ([]models.Rating, error) {
 	var ratings []models.Rating
@@ -77,6 +95,22 @@ func (r *RatingRepostory) CountMyReviewedRatings(ctx context.Context, userID uin
 	return count, err
 }
 
+// GetRatingsByProducts retrieves all ratings left on a list of products.
+func (r *RatingRepostory) GetRatingsByProducts(ctx context.Context, productIDs []uint) ([]models.Rating, error) {
+	var ratings []models.Rating
+	if len(productIDs) == 0 {
+		return ratings, nil
+	}
+
+	err := r.db.
+		WithContext(ctx).
+		Model(&models.Rating{}).
+		Where("product_id IN ?", productIDs).
+		Find(&ratings).
+		Error
+	return ratings, err
+}
+
 func (r *RatingRepostory) GetRatingByID(ctx context.Context, id uint) (models.Rating, error) {
 	var rating models.Rating
 	err := r.db.WithContext(ctx).
@@ -94,20 +128,68 @@ func (r *RatingRepostory) CreateRating(ctx context.Context, rating *models.Ratin
 			return err
 		}
 
-		return tx.
-			Model(&models.User{}).
-			Where(rating.RevieweeID).
-			Update("average_rating", tx.Model(&models.Rating{}).Select("avg(rating)").Where("reviewee_id = ?", rating.RevieweeID)).
+		return updateRatingCounters(tx, rating.RevieweeID)
+	})
+}
+
+// RateTransaction lets a party of a completed or cancelled transaction rate the other party,
+// once. The product and reviewee are filled in from the transaction.
+func (r *RatingRepostory) RateTransaction(ctx context.Context, transactionID uint, rating *models.Rating) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locked so two ratings of the same side can't race each other.
+		var transaction models.Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Transaction{}).
+			Where("id = ?", transactionID).
+			First(&transaction).
 			Error
+		if err != nil {
+			return err
+		}
+
+		switch rating.ReviewerID {
+		case transaction.BuyerID:
+			rating.RevieweeID = transaction.SellerID
+		case transaction.SellerID:
+			rating.RevieweeID = transaction.BuyerID
+		default:
+			return ErrNotTransactionParty
+		}
+
+		if transaction.TransactionStatus != models.TransactionStatusCompleted && transaction.TransactionStatus != models.TransactionStatusCancelled {
+			return ErrRatingNotOpen
+		}
+
+		// Deleted ratings count too, so a rating removed by moderation can't just be posted again.
+		var count int64
+		err = tx.Unscoped().
+			Model(&models.Rating{}).
+			Where("transaction_id = ? AND reviewer_id = ?", transaction.ID, rating.ReviewerID).
+			Count(&count).
+			Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyRated
+		}
+
+		rating.TransactionID = &transaction.ID
+		rating.ProductID = transaction.ProductID
+		if err := tx.Create(rating).Error; err != nil {
+			return err
+		}
+
+		return updateRatingCounters(tx, rating.RevieweeID)
 	})
 }
 
 // UpdateRating updates an existing rating.
-func (r *RatingRepostory) UpdateRating(ctx context.Context, ratingID uint, newRating uint, newFeedback string) error {
+func (r *RatingRepostory) UpdateRating(ctx context.Context, ratingID uint, newRating int8, newFeedback string) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var rating models.Rating
 		err := tx.Model(&models.Rating{}).
-			Select("id = ?", ratingID).
+			Where("id = ?", ratingID).
 			First(&rating).
 			Error
 		if err != nil {
@@ -125,14 +207,23 @@ func (r *RatingRepostory) UpdateRating(ctx context.Context, ratingID uint, newRa
 			return err
 		}
 
-		return tx.
-			Model(&models.User{}).
-			Where(rating.RevieweeID).
-			Update("average_rating", tx.Model(&models.Rating{}).Select("avg(rating)").Where("reviewee_id = ?", rating.RevieweeID)).
-			Error
+		return updateRatingCounters(tx, rating.RevieweeID)
 	})
 }
 
+// ReplyToRating sets the reply of the reviewee on a rating. A rating only gets one reply,
+// so nothing happens if it already has one.
+func (r *RatingRepostory) ReplyToRating(ctx context.Context, ratingID uint, reply string) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Rating{}).
+		Where("id = ? AND reply IS NULL", ratingID).
+		UpdateColumns(map[string]any{
+			"reply":      reply,
+			"replied_at": time.Now(),
+		})
+	return db.RowsAffected, db.Error
+}
+
 // DeleteRatingByID deletes a rating by ID.
 func (r *RatingRepostory) DeleteRatingByID(ctx context.Context, ratingID uint) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -153,6 +244,6 @@ func (r *RatingRepostory) DeleteRatingByID(ctx context.Context, ratingID uint) e
 			return err
 		}
 
-		return tx.Exec("UPDATE users SET average_rating = (SELECT AVG(rating) FROM ratings WHERE reviewee_id = users.id) WHERE id IN (?, ?)", rating.RevieweeID, rating.ReviewerID).Error
+		return updateRatingCounters(tx, rating.RevieweeID)
 	})
 }
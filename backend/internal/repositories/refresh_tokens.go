@@ -2,24 +2,40 @@ package repositories
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
+var (
+	ErrRefreshTokenReused  = errors.New("refresh token was already used")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+)
+
+const refreshTokenExpiry = time.Hour * 24 * 30 * 3
+
 type RefreshTokenRepository struct {
 	DB *gorm.DB
 }
 
-// SaveUserToken saves the token mapped to the user_id provided.
+// SaveUserToken saves the token mapped to the user_id provided, starting a new session.
 // This function does not do the hashing, do it beforehand before passing into this function.
-func (repo *RefreshTokenRepository) SaveUserToken(ctx context.Context, id uint, token string) (models.RefreshToken, error) {
+func (repo *RefreshTokenRepository) SaveUserToken(ctx context.Context, id uint, token string, userAgent string, ip string) (models.RefreshToken, error) {
+	now := time.Now()
 	refreshToken := models.RefreshToken{
 		UserID:       id,
 		RefreshToken: token,
-		ExpiredAt:    time.Now().Add(time.Hour * 24 * 30 * 3),
+		FamilyID:     rand.Text(),
+		ExpiredAt:    now.Add(refreshTokenExpiry),
 		IsRevoked:    false,
+		UserAgent:    truncate(userAgent, 512),
+		IPAddress:    ip,
+		SignedInAt:   &now,
+		LastUsedAt:   &now,
 	}
 	err := gorm.G[models.RefreshToken](repo.DB).Create(ctx, &refreshToken)
 	return refreshToken, err
@@ -29,7 +45,7 @@ func (repo *RefreshTokenRepository) GetRefreshToken(ctx context.Context, token s
 	refreshToken, err := gorm.G[models.RefreshToken](repo.DB).
 		Preload("User.Roles", nil).
 		Preload("User.Subscriptions", func(db gorm.PreloadBuilder) error {
-			db.Where("expired_at > ?", time.Now()).Limit(1)
+			db.Where("expired_at > ?", time.Now()).Order("expired_at DESC").Limit(1)
 			return nil
 		}).
 		Where("refresh_token = ?", token).
@@ -37,8 +53,116 @@ func (repo *RefreshTokenRepository) GetRefreshToken(ctx context.Context, token s
 	return refreshToken, err
 }
 
+// RotateToken swaps a refresh token for a new one in the same family. Presenting a token that
+// was already rotated means it was stolen, or the session was, so the whole family is revoked.
+// Both tokens have to be hashed beforehand.
+func (repo *RefreshTokenRepository) RotateToken(ctx context.Context, token string, newToken string, userAgent string, ip string) (models.RefreshToken, error) {
+	reused := false
+	err := repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_token = ?", token).
+			First(&old).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// Returning the error would roll the revocation back.
+		if old.IsRevoked {
+			reused = true
+			return tx.Model(&models.RefreshToken{}).
+				Where("family_id = ? AND is_revoked = ?", old.FamilyID, false).
+				Update("is_revoked", true).
+				Error
+		}
+
+		now := time.Now()
+		if old.ExpiredAt.Before(now) {
+			return ErrRefreshTokenExpired
+		}
+
+		err = tx.Model(&old).Update("is_revoked", true).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.RefreshToken{
+			UserID:       old.UserID,
+			RefreshToken: newToken,
+			FamilyID:     old.FamilyID,
+			ExpiredAt:    now.Add(refreshTokenExpiry),
+			UserAgent:    truncate(userAgent, 512),
+			IPAddress:    ip,
+			SignedInAt:   old.SignedInAt,
+			LastUsedAt:   &now,
+		}).Error
+	})
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	if reused {
+		return models.RefreshToken{}, ErrRefreshTokenReused
+	}
+
+	return repo.GetRefreshToken(ctx, newToken)
+}
+
 // InvalidateToken invalidates a token by marking it as revoked.
 // This function does not hash the token before checking.
 func (repo *RefreshTokenRepository) InvalidateToken(ctx context.Context, token string) (int, error) {
 	return gorm.G[models.RefreshToken](repo.DB).Where("refresh_token = ?", token).Update(ctx, "is_revoked", true)
 }
+
+// GetSessions returns the live token of every session of a user, most recently used first.
+func (repo *RefreshTokenRepository) GetSessions(ctx context.Context, userID uint) ([]models.RefreshToken, error) {
+	return gorm.G[models.RefreshToken](repo.DB).
+		Where("user_id = ? AND is_revoked = ? AND expired_at > ?", userID, false, time.Now()).
+		Order("last_used_at DESC NULLS LAST").
+		Find(ctx)
+}
+
+// RevokeSession logs a user out of a single session.
+func (repo *RefreshTokenRepository) RevokeSession(ctx context.Context, userID uint, familyID string) (int, error) {
+	return gorm.G[models.RefreshToken](repo.DB).
+		Where("user_id = ? AND family_id = ? AND is_revoked = ?", userID, familyID, false).
+		Update(ctx, "is_revoked", true)
+}
+
+// RevokeUserTokens logs a user out everywhere, access tokens included.
+func (repo *RefreshTokenRepository) RevokeUserTokens(ctx context.Context, userID uint) (int, error) {
+	var rows int64
+	err := repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = ?", userID, false).
+			Update("is_revoked", true)
+		if db.Error != nil {
+			return db.Error
+		}
+		rows = db.RowsAffected
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Update("token_version", gorm.Expr("token_version + 1")).
+			Error
+	})
+	return int(rows), err
+}
+
+// IsSessionActive checks if a session still has a live refresh token.
+func (repo *RefreshTokenRepository) IsSessionActive(ctx context.Context, familyID string) (bool, error) {
+	var count int64
+	err := repo.DB.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND is_revoked = ? AND expired_at > ?", familyID, false, time.Now()).
+		Count(&count).
+		Error
+	return count > 0, err
+}
+
+func truncate(s string, size int) string {
+	if len(s) > size {
+		return s[:size]
+	}
+	return s
+}
@@ -1,13 +1,25 @@
 package repositories
 
 type RepositoryRegistry struct {
-	CategoryRepository     *CategoryRepository
-	UserRepository         *UserRepository
-	RoleRepository         *RoleRepository
-	RefreshTokenRepository *RefreshTokenRepository
-	ProductRepository      *ProductRepository
-	QuestionRepository     *QuestionRepository
-	ChatSessionRepository  *ChatSessionRepository
-	TransactionRepository  *TransactionRepository
-	RatingRepostory        *RatingRepostory
+	CategoryRepository      *CategoryRepository
+	UserRepository          *UserRepository
+	RoleRepository          *RoleRepository
+	RefreshTokenRepository  *RefreshTokenRepository
+	ProductRepository       *ProductRepository
+	QuestionRepository      *QuestionRepository
+	ChatSessionRepository   *ChatSessionRepository
+	TransactionRepository   *TransactionRepository
+	RatingRepostory         *RatingRepostory
+	PaymentRepository       *PaymentRepository
+	DisputeRepository       *DisputeRepository
+	PayoutRepository        *PayoutRepository
+	RatingReportRepository  *RatingReportRepository
+	PasswordResetRepository *PasswordResetRepository
+	TwoFactorRepository     *TwoFactorRepository
+	WebAuthnRepository      *WebAuthnRepository
+	AuthThrottleRepository  *AuthThrottleRepository
+	EmailChangeRepository   *EmailChangeRepository
+	SellerRequestRepository *SellerRequestRepository
+	SubscriptionRepository  *SubscriptionRepository
+	AuditLogRepository      *AuditLogRepository
 }
@@ -2,17 +2,36 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"slices"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
+var (
+	ErrRoleExists        = errors.New("role already exists")
+	ErrUnknownRole       = errors.New("role doesn't exist")
+	ErrUnknownPermission = errors.New("permission doesn't exist")
+)
+
 type RoleRepository struct {
 	DB *gorm.DB
 }
 
+// GetRoles retrieves every role with its permissions.
 func (r *RoleRepository) GetRoles(ctx context.Context) ([]models.Role, error) {
-	return gorm.G[models.Role](r.DB).Find(ctx)
+	var roles []models.Role
+	err := r.DB.WithContext(ctx).
+		Model(&models.Role{}).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Order("id ASC").
+		Find(&roles).
+		Error
+	return roles, err
 }
 
 func (r *RoleRepository) GetRoleByID(ctx context.Context, id string) (models.Role, error) {
@@ -22,3 +41,159 @@ func (r *RoleRepository) GetRoleByID(ctx context.Context, id string) (models.Rol
 func (r *RoleRepository) SaveRole(ctx context.Context, role *models.Role) error {
 	return gorm.G[models.Role](r.DB).Create(ctx, role)
 }
+
+func (r *RoleRepository) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	return gorm.G[models.Permission](r.DB).Order("id ASC").Find(ctx)
+}
+
+// CreateRole creates a role with a set of permissions.
+func (r *RoleRepository) CreateRole(ctx context.Context, role *models.Role, permissionIDs []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		permissions, err := findPermissions(tx, permissionIDs)
+		if err != nil {
+			return err
+		}
+
+		db := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(role)
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrRoleExists
+		}
+
+		role.Permissions = permissions
+		return tx.Model(role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+	})
+}
+
+// UpdateRole changes the description and permissions of a role. Access tokens only have the names
+// of the roles, so they don't have to be revoked.
+func (r *RoleRepository) UpdateRole(ctx context.Context, id string, description string, permissionIDs []string) (models.Role, error) {
+	var role models.Role
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&role).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownRole
+		}
+		if err != nil {
+			return err
+		}
+
+		permissions, err := findPermissions(tx, permissionIDs)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&role).Update("description", description).Error
+		if err != nil {
+			return err
+		}
+
+		role.Permissions = permissions
+		return tx.Model(&role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+	})
+	return role, err
+}
+
+// DeleteRole takes a role away from everyone, revoking their access tokens, then deletes it.
+func (r *RoleRepository) DeleteRole(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id IN (?)", tx.Table("user_roles").Select("user_id").Where("role_id = ?", id)).
+			Update("token_version", gorm.Expr("token_version + 1")).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM user_roles WHERE role_id = ?", id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error
+		if err != nil {
+			return err
+		}
+
+		db := tx.Where("id = ?", id).Delete(&models.Role{})
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrUnknownRole
+		}
+		return nil
+	})
+}
+
+// SetUserRoles replaces the roles of a user, who always keeps the default one. Their access tokens
+// are revoked, so the new roles take effect right away.
+func (r *RoleRepository) SetUserRoles(ctx context.Context, userID uint, roleIDs []string) (models.User, error) {
+	var user models.User
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		ids := append([]string{models.ROLE_USER}, roleIDs...)
+		var roles []models.Role
+		err = tx.Where("id IN ?", ids).Find(&roles).Error
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if !slices.ContainsFunc(roles, func(r models.Role) bool { return r.ID == id }) {
+				return ErrUnknownRole
+			}
+		}
+
+		err = tx.Model(&user).Omit("Roles.*").Association("Roles").Replace(roles)
+		if err != nil {
+			return err
+		}
+
+		user.Roles = roles
+		return tx.Model(&user).Update("token_version", gorm.Expr("token_version + 1")).Error
+	})
+	return user, err
+}
+
+// SetRequireTOTP changes whether a role requires two-factor authentication. Access tokens of
+// everyone with the role are revoked, so the role is dropped from or added back to them.
+func (r *RoleRepository) SetRequireTOTP(ctx context.Context, id string, required bool) (int, error) {
+	var rows int
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&models.Role{}).Where("id = ?", id).Update("require_totp", required)
+		if db.Error != nil || db.RowsAffected == 0 {
+			return db.Error
+		}
+		rows = int(db.RowsAffected)
+
+		return tx.Model(&models.User{}).
+			Where("id IN (?)", tx.Table("user_roles").Select("user_id").Where("role_id = ?", id)).
+			Update("token_version", gorm.Expr("token_version + 1")).
+			Error
+	})
+	return rows, err
+}
+
+// findPermissions looks up permissions by their IDs, making sure all of them exist.
+func findPermissions(tx *gorm.DB, ids []string) ([]models.Permission, error) {
+	permissions := make([]models.Permission, 0)
+	if len(ids) == 0 {
+		return permissions, nil
+	}
+
+	err := tx.Where("id IN ?", ids).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if !slices.ContainsFunc(permissions, func(p models.Permission) bool { return p.ID == id }) {
+			return nil, ErrUnknownPermission
+		}
+	}
+	return permissions, nil
+}
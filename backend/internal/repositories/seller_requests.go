@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+var (
+	ErrSellerRequestPending  = errors.New("already has a pending seller request")
+	ErrSellerRequestReviewed = errors.New("seller request is already reviewed")
+)
+
+type SellerRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewSellerRequestRepository(db *gorm.DB) *SellerRequestRepository {
+	return &SellerRequestRepository{
+		db: db,
+	}
+}
+
+// CreateSellerRequest creates a pending request, unless the user already has one.
+func (r *SellerRequestRepository) CreateSellerRequest(ctx context.Context, request *models.SellerRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the user keeps two requests from both passing the check.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.User{}).
+			Select("id").
+			Where("id = ?", request.UserID).
+			First(&models.User{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&models.SellerRequest{}).
+			Where("user_id = ? AND status = ?", request.UserID, models.SellerRequestStatusPending).
+			Count(&count).
+			Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSellerRequestPending
+		}
+
+		request.Status = models.SellerRequestStatusPending
+		return tx.Create(request).Error
+	})
+}
+
+// GetSellerRequests retrieves requests with a status, oldest first so the queue is worked in order.
+func (r *SellerRequestRepository) GetSellerRequests(ctx context.Context, status models.SellerRequestStatus, limit int, offset int) ([]models.SellerRequest, error) {
+	var requests []models.SellerRequest
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerRequest{}).
+		Preload("User").
+		Preload("Reviewer").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&requests).
+		Error
+	return requests, err
+}
+
+func (r *SellerRequestRepository) CountSellerRequests(ctx context.Context, status models.SellerRequestStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerRequest{}).
+		Where("status = ?", status).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// GetUserSellerRequests retrieves every request of a user, newest first.
+func (r *SellerRequestRepository) GetUserSellerRequests(ctx context.Context, userID uint) ([]models.SellerRequest, error) {
+	var requests []models.SellerRequest
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerRequest{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&requests).
+		Error
+	return requests, err
+}
+
+// GetLatestSellerRequest retrieves the newest request of a user.
+func (r *SellerRequestRepository) GetLatestSellerRequest(ctx context.Context, userID uint) (models.SellerRequest, error) {
+	var request models.SellerRequest
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerRequest{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&request).
+		Error
+	return request, err
+}
+
+func (r *SellerRequestRepository) GetSellerRequestByID(ctx context.Context, id uint) (models.SellerRequest, error) {
+	var request models.SellerRequest
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerRequest{}).
+		Where("id = ?", id).
+		First(&request).
+		Error
+	return request, err
+}
+
+// ApproveSellerRequest approves a pending request and gives its user a subscription on a plan,
+// after the one they have if it's a renewal. Returns the request with its user, and the subscription.
+func (r *SellerRequestRepository) ApproveSellerRequest(
+	ctx context.Context,
+	id uint,
+	adminID uint,
+	reason *string,
+	plan string,
+	duration time.Duration,
+) (models.SellerRequest, models.SellerSubscription, error) {
+	var request models.SellerRequest
+	var subscription models.SellerSubscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = reviewSellerRequest(tx, id, adminID, models.SellerRequestStatusApproved, reason)
+		if err != nil {
+			return err
+		}
+
+		subscription = models.SellerSubscription{
+			UserID:          request.UserID,
+			Plan:            plan,
+			Source:          models.SubscriptionSourceRequest,
+			SellerRequestID: &request.ID,
+			GrantedByID:     &adminID,
+			Note:            reason,
+		}
+		return extendSubscription(tx, &subscription, duration)
+	})
+	return request, subscription, err
+}
+
+// RejectSellerRequest rejects a pending request with a reason, returning the request with its user.
+func (r *SellerRequestRepository) RejectSellerRequest(ctx context.Context, id uint, adminID uint, reason string) (models.SellerRequest, error) {
+	var request models.SellerRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = reviewSellerRequest(tx, id, adminID, models.SellerRequestStatusRejected, &reason)
+		return err
+	})
+	return request, err
+}
+
+// reviewSellerRequest locks a pending request and marks it as reviewed.
+func reviewSellerRequest(
+	tx *gorm.DB,
+	id uint,
+	adminID uint,
+	status models.SellerRequestStatus,
+	reason *string,
+) (models.SellerRequest, error) {
+	var request models.SellerRequest
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Model(&models.SellerRequest{}).
+		Where("id = ?", id).
+		First(&request).
+		Error
+	if err != nil {
+		return request, err
+	}
+
+	if request.Status != models.SellerRequestStatusPending {
+		return request, ErrSellerRequestReviewed
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.ReviewerID = &adminID
+	request.Reason = reason
+	request.ReviewedAt = &now
+	err = tx.Model(&request).
+		Select("status", "reviewer_id", "reason", "reviewed_at").
+		Updates(&request).
+		Error
+	if err != nil {
+		return request, err
+	}
+
+	err = tx.Model(&models.User{}).Where("id = ?", request.UserID).First(&request.User).Error
+	return request, err
+}
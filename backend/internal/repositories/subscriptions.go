@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+type SubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db: db,
+	}
+}
+
+// GetUserSubscriptions retrieves every subscription of a user, latest first.
+func (r *SubscriptionRepository) GetUserSubscriptions(ctx context.Context, userID uint) ([]models.SellerSubscription, error) {
+	var subscriptions []models.SellerSubscription
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerSubscription{}).
+		Preload("GrantedBy").
+		Where("user_id = ?", userID).
+		Order("expired_at DESC").
+		Find(&subscriptions).
+		Error
+	return subscriptions, err
+}
+
+// ExtendSubscription adds a subscription for a while after the current one of the user, or from
+// now if they don't have one.
+func (r *SubscriptionRepository) ExtendSubscription(ctx context.Context, subscription *models.SellerSubscription, duration time.Duration) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return extendSubscription(tx, subscription, duration)
+	})
+}
+
+// GetExpiringSubscriptions retrieves the last subscription of each user that expires before a
+// time, if they weren't reminded of it yet.
+func (r *SubscriptionRepository) GetExpiringSubscriptions(ctx context.Context, before time.Time) ([]models.SellerSubscription, error) {
+	var subscriptions []models.SellerSubscription
+	err := r.db.WithContext(ctx).
+		Model(&models.SellerSubscription{}).
+		Preload("User").
+		Where("expired_at > ? AND expired_at <= ?", time.Now(), before).
+		Where("reminder_sent_at IS NULL").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM seller_subscriptions later
+			WHERE later.user_id = seller_subscriptions.user_id
+				AND later.expired_at > seller_subscriptions.expired_at
+				AND later.deleted_at IS NULL
+		)`).
+		Find(&subscriptions).
+		Error
+	return subscriptions, err
+}
+
+func (r *SubscriptionRepository) SetReminderSent(ctx context.Context, id uint) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.SellerSubscription{}).
+		Where("id = ?", id).
+		Update("reminder_sent_at", time.Now())
+	return int(db.RowsAffected), db.Error
+}
+
+// extendSubscription creates a subscription that starts when the last one of the user expires, so
+// they add up. Access tokens are revoked so the new expiry shows up.
+func extendSubscription(tx *gorm.DB, subscription *models.SellerSubscription, duration time.Duration) error {
+	// Locking the user keeps two extensions from starting at the same time.
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Model(&models.User{}).
+		Select("id").
+		Where("id = ?", subscription.UserID).
+		First(&models.User{}).
+		Error
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var last models.SellerSubscription
+	err = tx.Model(&models.SellerSubscription{}).
+		Where("user_id = ? AND expired_at > ?", subscription.UserID, start).
+		Order("expired_at DESC").
+		Limit(1).
+		Find(&last).
+		Error
+	if err != nil {
+		return err
+	}
+	if last.ID != 0 {
+		start = last.ExpiredAt
+	}
+
+	subscription.StartsAt = start
+	subscription.ExpiredAt = start.Add(duration)
+	if err := tx.Create(subscription).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.User{}).
+		Where("id = ?", subscription.UserID).
+		Update("token_version", gorm.Expr("token_version + 1")).
+		Error
+}
@@ -2,9 +2,10 @@ package repositories
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
@@ -47,50 +48,299 @@ func (r *TransactionRepository) GetTransactionByID(ctx context.Context, id uint)
 	return trans, err
 }
 
-func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, id uint, status models.TransactionStatus) (int64, error) {
+// UpdateTransactionStatus moves a transaction to another status, only if it is still in the status
+// it was read in. No rows are affected if someone else moved it first.
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, id uint, from models.TransactionStatus, to models.TransactionStatus) (int64, error) {
 	db := r.db.WithContext(ctx).
 		Model(&models.Transaction{}).
-		Where("id = ?", id).
-		Update("transaction_status", status)
+		Where("id = ? AND transaction_status = ?", id, from).
+		Update("transaction_status", to)
+	return db.RowsAffected, db.Error
+}
+
+// UpdateShippingAddress sets the address the buyer wants the product shipped to,
+// and marks it as confirmed as of now. No rows are affected once the product has been shipped.
+func (r *TransactionRepository) UpdateShippingAddress(ctx context.Context, id uint, address string) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ? AND transaction_status IN ?", id, []models.TransactionStatus{models.TransactionStatusPending, models.TransactionStatusWinnerPaid}).
+		Updates(map[string]any{
+			"shipping_address":      address,
+			"shipping_confirmed_at": time.Now(),
+		})
+	return db.RowsAffected, db.Error
+}
+
+// MarkTransactionDelivered moves the transaction to delivered, attaching the carrier
+// and tracking number if the seller provided them. No rows are affected if it isn't paid.
+func (r *TransactionRepository) MarkTransactionDelivered(ctx context.Context, id uint, carrier *string, trackingNumber *string) (int64, error) {
+	updates := map[string]any{
+		"transaction_status": models.TransactionStatusDelivered,
+		"delivered_at":       time.Now(),
+	}
+	if carrier != nil && trackingNumber != nil {
+		updates["shipping_carrier"] = *carrier
+		updates["tracking_number"] = *trackingNumber
+	}
+
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ? AND transaction_status = ?", id, models.TransactionStatusWinnerPaid).
+		Updates(updates)
 	return db.RowsAffected, db.Error
 }
 
-func (r *TransactionRepository) CancelTransactionStatus(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// CancelTransactionStatus cancels a pending transaction, finalizes the product and
+// gives the buyer a penalty rating for not paying. Returns 0 rows if it isn't pending anymore.
+func (r *TransactionRepository) CancelTransactionStatus(ctx context.Context, id uint) (int64, error) {
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Doesn't allow the thing to be cancelled if the winner has already paid.
 		var transaction models.Transaction
-		err := tx.Model(&models.Transaction{}).
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Transaction{}).
 			Where("id = ?", id).
-			First(&transaction).
+			Where("transaction_status = ?", models.TransactionStatusPending).
+			Find(&transaction).
 			Error
-		if err != nil {
+		if err != nil || transaction.ID == 0 {
 			return err
 		}
 
-		// Doesn't allow the thing to be cancelled if the winner has already paid.
-		if transaction.TransactionStatus != models.TransactionStatusPending {
-			return fmt.Errorf("transaction %d is not pending", id)
-		}
-
 		db := tx.Model(&models.Transaction{}).
 			Where("id = ?", id).
 			Update("transaction_status", models.TransactionStatusCancelled)
-		if db.Error != nil || db.RowsAffected == 0 {
-			return fmt.Errorf("transaction could not be set to cancelled")
+		if db.Error != nil {
+			return db.Error
+		}
+		rows = db.RowsAffected
+
+		err = tx.Model(&models.Product{}).
+			Where("id = ?", transaction.ProductID).
+			Update("finalized_at", time.Now()).
+			Error
+		if err != nil {
+			return err
 		}
 
 		// Mark the winner as bad.
 		rating := models.Rating{
-			ProductID:  transaction.ProductID,
-			ReviewerID: transaction.SellerID,
-			RevieweeID: transaction.SellerID,
-			Rating:     0,
-			Feedback:   "Did not follow through with payment",
+			TransactionID: &transaction.ID,
+			ProductID:     transaction.ProductID,
+			ReviewerID:    transaction.SellerID,
+			RevieweeID:    transaction.BuyerID,
+			Rating:        models.RatingNegative,
+			Feedback:      "Did not follow through with payment",
 		}
-		err = r.ratingRepo.CreateRating(ctx, &rating)
+		// Created in this transaction, the foreign key check would wait on the lock above otherwise.
+		err = tx.Create(&rating).Error
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return updateRatingCounters(tx, transaction.BuyerID)
+	})
+	return rows, err
+}
+
+// CompleteDeliveredTransaction completes a transaction that is still delivered, and finalizes the product.
+// Returns 0 rows if the transaction moved on in the meantime.
+func (r *TransactionRepository) CompleteDeliveredTransaction(ctx context.Context, id uint) (int64, error) {
+	var rows int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Model(&models.Transaction{}).
+			Where("id = ?", id).
+			Where("transaction_status = ?", models.TransactionStatusDelivered).
+			Find(&transaction).
+			Error
+		if err != nil || transaction.ID == 0 {
+			return err
+		}
+
+		db := tx.Model(&models.Transaction{}).
+			Where("id = ?", id).
+			Update("transaction_status", models.TransactionStatusCompleted)
+		if db.Error != nil {
+			return db.Error
+		}
+		rows = db.RowsAffected
+
+		return tx.Model(&models.Product{}).
+			Where("id = ?", transaction.ProductID).
+			Update("finalized_at", time.Now()).
+			Error
 	})
+	return rows, err
+}
+
+// GetPendingTransactionsCreatedBefore retrieves pending transactions created before a certain time.
+// If unreminded is set, only the ones without a payment reminder sent yet are returned.
+func (r *TransactionRepository) GetPendingTransactionsCreatedBefore(ctx context.Context, before time.Time, unreminded bool) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	query := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Preload("Product").
+		Preload("Buyer").
+		Preload("Seller").
+		Where("transaction_status = ?", models.TransactionStatusPending).
+		Where("created_at < ?", before)
+	if unreminded {
+		query = query.Where("payment_reminder_sent_at IS NULL")
+	}
+
+	err := query.Find(&transactions).Error
+	return transactions, err
+}
+
+// GetDeliveredTransactionsBefore retrieves delivered transactions that were delivered before a certain time.
+// Transactions delivered before the delivery time was tracked use their last update instead.
+// If unreminded is set, only the ones without a delivery reminder sent yet are returned.
+func (r *TransactionRepository) GetDeliveredTransactionsBefore(ctx context.Context, before time.Time, unreminded bool) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	query := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Preload("Product").
+		Preload("Buyer").
+		Preload("Seller").
+		Where("transaction_status = ?", models.TransactionStatusDelivered).
+		Where("COALESCE(delivered_at, updated_at) < ?", before)
+	if unreminded {
+		query = query.Where("delivery_reminder_sent_at IS NULL")
+	}
+
+	err := query.Find(&transactions).Error
+	return transactions, err
+}
+
+// SetPaymentReminderSent marks the payment reminder of a transaction as sent.
+func (r *TransactionRepository) SetPaymentReminderSent(ctx context.Context, id uint) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ?", id).
+		UpdateColumn("payment_reminder_sent_at", time.Now())
+	return db.RowsAffected, db.Error
+}
+
+// SetDeliveryReminderSent marks the delivery reminder of a transaction as sent.
+func (r *TransactionRepository) SetDeliveryReminderSent(ctx context.Context, id uint) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ?", id).
+		UpdateColumn("delivery_reminder_sent_at", time.Now())
+	return db.RowsAffected, db.Error
+}
+
+// GetTransactionByProductID retrieves the transaction of a product.
+func (r *TransactionRepository) GetTransactionByProductID(ctx context.Context, productID uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("product_id = ?", productID).
+		First(&transaction).
+		Error
+	return transaction, err
+}
+
+// GetTransactionForInvoice retrieves a transaction with both parties and the product.
+func (r *TransactionRepository) GetTransactionForInvoice(ctx context.Context, id uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Preload("Product").
+		Preload("Buyer").
+		Preload("Seller").
+		Where("id = ?", id).
+		First(&transaction).
+		Error
+	return transaction, err
+}
+
+// SetInvoiceKey records where the invoice of a transaction is stored.
+func (r *TransactionRepository) SetInvoiceKey(ctx context.Context, id uint, key string) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ?", id).
+		UpdateColumn("invoice_key", key)
+	return db.RowsAffected, db.Error
+}
+
+// SetInvoiceSent marks the invoice of a transaction as emailed.
+func (r *TransactionRepository) SetInvoiceSent(ctx context.Context, id uint) (int64, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("id = ?", id).
+		UpdateColumn("invoice_sent_at", time.Now())
+	return db.RowsAffected, db.Error
+}
+
+// GetUninvoicedTransactionIDs retrieves the IDs of settled transactions whose invoice hasn't been emailed.
+func (r *TransactionRepository) GetUninvoicedTransactionIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Joins("JOIN payouts ON payouts.transaction_id = transactions.id AND payouts.deleted_at IS NULL").
+		Where("transactions.transaction_status = ?", models.TransactionStatusCompleted).
+		Where("transactions.invoice_sent_at IS NULL").
+		Pluck("transactions.id", &ids).
+		Error
+	return ids, err
+}
+
+// TransactionFilter narrows down the transactions of a user, either as the buyer or as the seller.
+type TransactionFilter struct {
+	UserID   uint
+	AsSeller bool
+	Status   models.TransactionStatus
+	From     *time.Time
+	To       *time.Time
+}
+
+func (r *TransactionRepository) filterUserTransactions(ctx context.Context, filter TransactionFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Transaction{})
+	if filter.AsSeller {
+		query = query.Where("seller_id = ?", filter.UserID)
+	} else {
+		query = query.Where("buyer_id = ?", filter.UserID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("transaction_status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// GetUserTransactions retrieves the transactions of a user, newest first.
+func (r *TransactionRepository) GetUserTransactions(ctx context.Context, filter TransactionFilter, limit int, offset int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.filterUserTransactions(ctx, filter).
+		Preload("Product").
+		Preload("Buyer").
+		Preload("Seller").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&transactions).
+		Error
+	return transactions, err
+}
+
+// SumUserTransactions counts the transactions of a user, and sums up their final prices.
+func (r *TransactionRepository) SumUserTransactions(ctx context.Context, filter TransactionFilter) (int64, int64, error) {
+	var result struct {
+		Count  int64
+		Amount int64
+	}
+	err := r.filterUserTransactions(ctx, filter).
+		Select("COUNT(*) AS count, COALESCE(SUM(final_price), 0) AS amount").
+		Scan(&result).
+		Error
+	return result.Count, result.Amount, err
 }
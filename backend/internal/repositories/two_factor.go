@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: db,
+	}
+}
+
+// SetTOTPSecret keeps a new secret while setting up, as long as TOTP isn't enabled yet.
+func (r *TwoFactorRepository) SetTOTPSecret(ctx context.Context, userID uint, secret string) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_enabled = false", userID).
+		Update("totp_secret", secret)
+	return int(db.RowsAffected), db.Error
+}
+
+// EnableTOTP turns on TOTP with the step its confirmation code used, replacing any recovery codes.
+// Access tokens are revoked, so roles that require two-factor authentication are added to them.
+func (r *TwoFactorRepository) EnableTOTP(ctx context.Context, userID uint, step int64, codeHashes []string) (int, error) {
+	var rows int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		db := tx.Model(&models.User{}).
+			Where("id = ? AND totp_enabled = false AND totp_secret IS NOT NULL", userID).
+			Updates(map[string]any{
+				"totp_enabled":   true,
+				"totp_last_step": step,
+				"token_version":  gorm.Expr("token_version + 1"),
+			})
+		if db.Error != nil || db.RowsAffected == 0 {
+			return db.Error
+		}
+		rows = int(db.RowsAffected)
+
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	return rows, err
+}
+
+// DisableTOTP turns off TOTP and deletes the recovery codes. Access tokens are revoked since
+// roles that require two-factor authentication have to be dropped from them.
+func (r *TwoFactorRepository) DisableTOTP(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{
+				"totp_enabled":   false,
+				"totp_secret":    nil,
+				"totp_last_step": 0,
+				"token_version":  gorm.Expr("token_version + 1"),
+			}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes deletes the old recovery codes of a user for new ones.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	if err != nil {
+		return err
+	}
+
+	codes := make([]models.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+// CountRecoveryCodes counts the recovery codes a user has left.
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).
+		Error
+	return count, err
+}
+
+// UseTOTPStep marks a time step as used, failing if it or a later step was used before.
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_enabled = true AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return int(db.RowsAffected), db.Error
+}
+
+// UseRecoveryCode uses up a recovery code of a user, returns 0 rows if there's no such code.
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return int(db.RowsAffected), db.Error
+}
+
+func (r *TwoFactorRepository) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// AttemptChallenge counts an attempt at a challenge and returns it, as long as it's still
+// pending and has attempts left.
+func (r *TwoFactorRepository) AttemptChallenge(ctx context.Context, tokenHash string, maxAttempts int) (models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	db := r.db.WithContext(ctx).
+		Model(&models.TwoFactorChallenge{}).
+		Where("token_hash = ? AND used_at IS NULL AND expired_at > ? AND attempts < ?", tokenHash, time.Now(), maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if db.Error != nil {
+		return challenge, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return challenge, gorm.ErrRecordNotFound
+	}
+
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&challenge).Error
+	return challenge, err
+}
+
+// GetChallenge retrieves a challenge that can still be answered, without counting an attempt.
+func (r *TwoFactorRepository) GetChallenge(ctx context.Context, tokenHash string, maxAttempts int) (models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND used_at IS NULL AND expired_at > ? AND attempts < ?", tokenHash, time.Now(), maxAttempts).
+		First(&challenge).
+		Error
+	return challenge, err
+}
+
+// UseChallenge finishes a challenge so it can't sign in again.
+func (r *TwoFactorRepository) UseChallenge(ctx context.Context, id uint) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.TwoFactorChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return int(db.RowsAffected), db.Error
+}
+
+// DeleteStaleChallenges deletes challenges that expired before a point in time.
+func (r *TwoFactorRepository) DeleteStaleChallenges(ctx context.Context, before time.Time) (int, error) {
+	db := r.db.WithContext(ctx).Where("expired_at < ?", before).Delete(&models.TwoFactorChallenge{})
+	return int(db.RowsAffected), db.Error
+}
@@ -2,11 +2,11 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"luny.dev/cherryauctions/internal/models"
 )
 
@@ -21,7 +21,7 @@ func (repo *UserRepository) GetUserByID(ctx context.Context, id uint) (models.Us
 		Where("id = ?", id).
 		Preload("Roles", nil).
 		Preload("Subscriptions", func(db gorm.PreloadBuilder) error {
-			db.Where("expired_at > ?", time.Now()).Limit(1)
+			db.Where("expired_at > ?", time.Now()).Order("expired_at DESC").Limit(1)
 			return nil
 		}).
 		First(ctx)
@@ -34,24 +34,79 @@ func (repo *UserRepository) GetUserByEmail(ctx context.Context, email string) (m
 	return gorm.G[models.User](repo.DB).
 		Preload("Roles", nil).
 		Preload("Subscriptions", func(db gorm.PreloadBuilder) error {
-			db.Where("expired_at > ?", time.Now()).Limit(1)
+			db.Where("expired_at > ?", time.Now()).Order("expired_at DESC").Limit(1)
 			return nil
 		}).
 		Where("email ILIKE ?", strings.ToLower(email)).
 		First(ctx)
 }
 
-func (repo *UserRepository) GetUsers(ctx context.Context, limit int, offset int) ([]models.User, error) {
+// GetUserByOauthSubject returns the user linked to an account of an OAuth provider.
+func (repo *UserRepository) GetUserByOauthSubject(ctx context.Context, subject string) (models.User, error) {
 	return gorm.G[models.User](repo.DB).
 		Preload("Roles", nil).
 		Preload("Subscriptions", func(db gorm.PreloadBuilder) error {
-			db.Where("expired_at > ?", time.Now()).Limit(1)
+			db.Where("expired_at > ?", time.Now()).Order("expired_at DESC").Limit(1)
 			return nil
 		}).
+		Where("oauth_subject = ?", subject).
+		First(ctx)
+}
+
+// UserFilter narrows down the users admins search through, nil fields match everyone.
+type UserFilter struct {
+	Query     string
+	Role      string
+	Verified  *bool
+	Seller    *bool
+	Suspended *bool
+}
+
+func (repo *UserRepository) filterUsers(ctx context.Context, filter UserFilter) *gorm.DB {
+	now := time.Now()
+	query := repo.DB.WithContext(ctx).Model(&models.User{})
+	if filter.Query != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Query) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("id IN (?)", repo.DB.Table("user_roles").Select("user_id").Where("role_id = ?", filter.Role))
+	}
+	if filter.Verified != nil {
+		query = query.Where("verified = ?", *filter.Verified)
+	}
+	if filter.Seller != nil {
+		sellers := repo.DB.Model(&models.SellerSubscription{}).Select("user_id").Where("starts_at <= ? AND expired_at > ?", now, now)
+		if *filter.Seller {
+			query = query.Where("id IN (?)", sellers)
+		} else {
+			query = query.Where("id NOT IN (?)", sellers)
+		}
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query = query.Where("banned OR suspended_until > ?", now)
+		} else {
+			query = query.Where("NOT banned AND (suspended_until IS NULL OR suspended_until <= ?)", now)
+		}
+	}
+	return query
+}
+
+// GetUsers searches for users, by name or email.
+func (repo *UserRepository) GetUsers(ctx context.Context, filter UserFilter, limit int, offset int) ([]models.User, error) {
+	var users []models.User
+	err := repo.filterUsers(ctx, filter).
+		Preload("Roles").
+		Preload("Subscriptions", func(db *gorm.DB) *gorm.DB {
+			return db.Where("expired_at > ?", time.Now()).Order("expired_at DESC")
+		}).
 		Order("id").
 		Limit(limit).
 		Offset(offset).
-		Find(ctx)
+		Find(&users).
+		Error
+	return users, err
 }
 
 // RegisterNewUser registers a new user with a default role.
@@ -72,43 +127,79 @@ func (repo *UserRepository) RegisterNewUser(ctx context.Context, name string, em
 	return user, err
 }
 
-func (repo *UserRepository) CountUsers(ctx context.Context) (int64, error) {
-	return gorm.G[models.User](repo.DB).Count(ctx, "id")
-}
-
-// SaveUser creates a new user with the model passed in.
-// Returns an error if it can't be saved.
-func (repo *UserRepository) SaveUser(ctx context.Context, user *models.User) error {
-	return gorm.G[models.User](repo.DB).Create(ctx, user)
-}
+// RegisterOauthUser registers a new user from an OAuth provider, verified since the provider
+// already verified the email.
+func (repo *UserRepository) RegisterOauthUser(ctx context.Context, name string, email string, oauthType string, subject string, avatarURL *string) (models.User, error) {
+	defaultRole, err := repo.RoleRepository.GetRoleByID(ctx, "user")
+	if err != nil {
+		return models.User{}, err
+	}
 
-// RequestUserApproval marks a user as requesting approval.
-func (repo *UserRepository) RequestUserApproval(ctx context.Context, id uint) (int, error) {
-	return gorm.G[models.User](repo.DB).Where("id = ?", id).Update(ctx, "waiting_approval", true)
+	user := models.User{
+		Name:         &name,
+		Email:        &email,
+		AvatarURL:    avatarURL,
+		OauthType:    oauthType,
+		OauthSubject: &subject,
+		Verified:     true,
+		Roles:        []models.Role{defaultRole},
+	}
+	err = gorm.G[models.User](repo.DB).Create(ctx, &user)
+	return user, err
 }
 
-func (repo *UserRepository) ApproveUser(ctx context.Context, id uint) error {
-	return repo.DB.Transaction(func(tx *gorm.DB) error {
-		rows, err := gorm.G[models.User](tx).Where("id = ?", id).Update(ctx, "waiting_approval", false)
-		if err != nil {
+// LinkOauthAccount links an existing user to an account of an OAuth provider with the same
+// verified email. A verified user can still sign in the way they registered. An unverified one
+// was never proven to own the email, so whoever registered it loses the password, two-factor
+// authentication and every session.
+func (repo *UserRepository) LinkOauthAccount(ctx context.Context, id uint, subject string) (int, error) {
+	var rows int
+	err := repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND oauth_subject IS NULL", id).
+			Find(&user).
+			Error
+		if err != nil || user.ID == 0 {
 			return err
 		}
-		if rows != 1 {
-			return errors.New("couldn't mark as no longer waiting for approval")
-		}
 
-		// Add a subscription
-		subscription := models.SellerSubscription{
-			UserID:    id,
-			ExpiredAt: time.Now().Add(time.Minute * 60 * 24 * 7), // 7 days, should be made configured later
+		updates := map[string]any{"oauth_subject": subject, "verified": true}
+		if !user.Verified {
+			updates["password"] = nil
+			updates["totp_enabled"] = false
+			updates["totp_secret"] = nil
+			updates["totp_last_step"] = 0
+			updates["token_version"] = gorm.Expr("token_version + 1")
 		}
-		err = gorm.G[models.SellerSubscription](tx).Create(ctx, &subscription)
+		db := tx.Model(&user).Updates(updates)
+		rows = int(db.RowsAffected)
+		if db.Error != nil || user.Verified {
+			return db.Error
+		}
+
+		err = tx.Where("user_id = ?", id).Delete(&models.RecoveryCode{}).Error
 		if err != nil {
 			return err
 		}
-
-		return nil
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = ?", id, false).
+			Update("is_revoked", true).
+			Error
 	})
+	return rows, err
+}
+
+func (repo *UserRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	var count int64
+	err := repo.filterUsers(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// SaveUser creates a new user with the model passed in.
+// Returns an error if it can't be saved.
+func (repo *UserRepository) SaveUser(ctx context.Context, user *models.User) error {
+	return gorm.G[models.User](repo.DB).Create(ctx, user)
 }
 
 func (repo *UserRepository) UpdateAvatarURL(ctx context.Context, id uint, url string) (int, error) {
@@ -123,24 +214,119 @@ func (repo *UserRepository) UpdatePassword(ctx context.Context, id uint, passwor
 	return gorm.G[models.User](repo.DB).Where("id = ?", id).Update(ctx, "password", password)
 }
 
-// UpdateOTP updates the user's OTP to a new one.
-func (repo *UserRepository) UpdateOTP(ctx context.Context, id uint, otp string) (int, error) {
-	expiredAt := time.Now().Add(15 * time.Minute)
-	return gorm.G[models.User](repo.DB).
+// UpdateOTP replaces the user's OTP with a new one, by its hash.
+func (repo *UserRepository) UpdateOTP(ctx context.Context, id uint, otpHash string) (int, error) {
+	db := repo.DB.WithContext(ctx).
+		Model(&models.User{}).
 		Where("id = ?", id).
-		Updates(ctx, models.User{OTPCode: &otp, OTPExpiredAt: &expiredAt})
+		Updates(map[string]any{"otp_hash": otpHash, "otp_expired_at": time.Now().Add(15 * time.Minute), "otp_attempts": 0})
+	return int(db.RowsAffected), db.Error
 }
 
-// ClearOTP clears the user's OTP to an empty state to mark used.
-func (repo *UserRepository) ClearOTP(ctx context.Context, id uint) (int, error) {
+// ClearOTP clears the user's OTP to an empty state to mark used, as long as it's still the same one.
+func (repo *UserRepository) ClearOTP(ctx context.Context, id uint, otpHash string) (int, error) {
 	db := repo.DB.WithContext(ctx).
 		Model(&models.User{}).
-		Where("id = ?", id).
-		Select("otp_code", "otp_expired_at").
-		Updates(map[string]any{"otp_code": nil, "otp_expired_at": nil})
+		Where("id = ? AND otp_hash = ?", id, otpHash).
+		Updates(map[string]any{"otp_hash": nil, "otp_expired_at": nil, "otp_attempts": 0})
 	return int(db.RowsAffected), db.Error
 }
 
+// FailOTP counts a wrong code against the user's OTP, clearing it once it had maxAttempts.
+func (repo *UserRepository) FailOTP(ctx context.Context, id uint, maxAttempts int) (int, error) {
+	db := repo.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND otp_hash IS NOT NULL", id).
+		Updates(map[string]any{
+			"otp_attempts":   gorm.Expr("otp_attempts + 1"),
+			"otp_hash":       gorm.Expr("CASE WHEN otp_attempts + 1 >= ? THEN NULL ELSE otp_hash END", maxAttempts),
+			"otp_expired_at": gorm.Expr("CASE WHEN otp_attempts + 1 >= ? THEN NULL ELSE otp_expired_at END", maxAttempts),
+		})
+	return int(db.RowsAffected), db.Error
+}
+
+// UpdateUserVerified changes whether the user is verified, which revokes their access tokens.
 func (repo *UserRepository) UpdateUserVerified(ctx context.Context, id uint, verified bool) (int, error) {
-	return gorm.G[models.User](repo.DB).Where("id = ?", id).Update(ctx, "verified", verified)
+	db := repo.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"verified": verified, "token_version": gorm.Expr("token_version + 1")})
+	return int(db.RowsAffected), db.Error
+}
+
+// GetTokenState returns the version access tokens of the user need to be valid, and whether
+// they're suspended. Only those columns are filled in.
+func (repo *UserRepository) GetTokenState(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := repo.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Select("id", "token_version", "suspended_until", "banned").
+		Scan(&user).
+		Error
+	return user, err
+}
+
+// SuspendUser suspends a user until a time, or bans them if there's none. Their automated bids
+// stop, and bans also withdraw their bids from running auctions.
+func (repo *UserRepository) SuspendUser(ctx context.Context, id uint, until *time.Time, reason string) error {
+	return repo.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&user).Updates(map[string]any{
+			"suspended_until":   until,
+			"banned":            until == nil,
+			"suspension_reason": reason,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		running := tx.Model(&models.Product{}).Select("id").Where("product_state = ?", models.ProductStateActive)
+		// Intents are keyed by the product and user, a soft deleted one would be revived hidden.
+		err = tx.Unscoped().Where("user_id = ? AND product_id IN (?)", id, running).Delete(&models.BidIntent{}).Error
+		if err != nil || until != nil {
+			return err
+		}
+
+		var productIDs []uint
+		err = tx.Model(&models.Bid{}).
+			Where("user_id = ? AND product_id IN (?)", id, running).
+			Distinct().
+			Pluck("product_id", &productIDs).
+			Error
+		if err != nil || len(productIDs) == 0 {
+			return err
+		}
+
+		err = tx.Where("user_id = ? AND product_id IN ?", id, productIDs).Delete(&models.Bid{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE products SET
+				bids_count = (SELECT COUNT(*) FROM bids WHERE bids.product_id = products.id AND bids.deleted_at IS NULL),
+				current_highest_bid_id = (
+					SELECT id FROM bids
+					WHERE bids.product_id = products.id AND bids.deleted_at IS NULL
+					ORDER BY price DESC, created_at ASC
+					LIMIT 1
+				)
+			WHERE id IN ?`, productIDs).Error
+	})
+}
+
+// ReinstateUser lifts the suspension or ban of a user.
+func (repo *UserRepository) ReinstateUser(ctx context.Context, id uint) (int, error) {
+	db := repo.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Where("banned OR suspended_until > ?", time.Now()).
+		Updates(map[string]any{"suspended_until": nil, "banned": false, "suspension_reason": nil})
+	return int(db.RowsAffected), db.Error
 }
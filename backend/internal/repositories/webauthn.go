@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+)
+
+type WebAuthnRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) *WebAuthnRepository {
+	return &WebAuthnRepository{
+		db: db,
+	}
+}
+
+func (r *WebAuthnRepository) CreateChallenge(ctx context.Context, challenge *models.WebAuthnChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// UseChallenge marks a pending challenge of a ceremony as answered, and returns it. Registration
+// challenges also have to belong to the user. Returns gorm.ErrRecordNotFound if there's no such
+// challenge, or it was already answered.
+func (r *WebAuthnRepository) UseChallenge(ctx context.Context, challenge string, ceremony string, userID *uint) (models.WebAuthnChallenge, error) {
+	var found models.WebAuthnChallenge
+
+	query := r.db.WithContext(ctx).
+		Model(&models.WebAuthnChallenge{}).
+		Where("challenge = ? AND ceremony = ? AND used_at IS NULL AND expired_at > ?", challenge, ceremony, time.Now())
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	db := query.Update("used_at", time.Now())
+	if db.Error != nil {
+		return found, db.Error
+	}
+	if db.RowsAffected == 0 {
+		return found, gorm.ErrRecordNotFound
+	}
+
+	err := r.db.WithContext(ctx).Where("challenge = ?", challenge).First(&found).Error
+	return found, err
+}
+
+// DeleteStaleChallenges deletes challenges that expired before a point in time.
+func (r *WebAuthnRepository) DeleteStaleChallenges(ctx context.Context, before time.Time) (int, error) {
+	db := r.db.WithContext(ctx).Where("expired_at < ?", before).Delete(&models.WebAuthnChallenge{})
+	return int(db.RowsAffected), db.Error
+}
+
+func (r *WebAuthnRepository) CreateCredential(ctx context.Context, credential *models.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(credential).Error
+}
+
+func (r *WebAuthnRepository) GetCredentialByCredentialID(ctx context.Context, credentialID string) (models.WebAuthnCredential, error) {
+	var credential models.WebAuthnCredential
+	err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&credential).Error
+	return credential, err
+}
+
+// GetCredentials lists the passkeys of a user, newest first.
+func (r *WebAuthnRepository) GetCredentials(ctx context.Context, userID uint) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+// UseCredential stores the new sign count after a login, as long as nobody else logged in with
+// the credential since it was read.
+func (r *WebAuthnRepository) UseCredential(ctx context.Context, id uint, oldSignCount int64, signCount int64) (int, error) {
+	db := r.db.WithContext(ctx).
+		Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, oldSignCount).
+		Updates(map[string]any{
+			"sign_count":   signCount,
+			"last_used_at": time.Now(),
+		})
+	return int(db.RowsAffected), db.Error
+}
+
+// DeleteCredential deletes a passkey of a user, returns 0 rows if the user doesn't have it.
+func (r *WebAuthnRepository) DeleteCredential(ctx context.Context, userID uint, id uint) (int, error) {
+	db := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebAuthnCredential{})
+	return int(db.RowsAffected), db.Error
+}
@@ -1,5 +1,12 @@
 package auth
 
+import (
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/services"
+)
+
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8,max=64"`
@@ -9,6 +16,13 @@ type LoginResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
+type SuspendedResponse struct {
+	Error          string     `json:"error"`
+	Reason         *string    `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	Banned         bool       `json:"banned"`
+}
+
 type RegisterRequest struct {
 	Name         string `json:"name" binding:"required"`
 	Email        string `json:"email" binding:"required,email"`
@@ -16,6 +30,82 @@ type RegisterRequest struct {
 	CaptchaToken string `json:"captcha_token" binding:"required"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=64"`
+}
+
+type GoogleCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
 type PostOTPVerifyBody struct {
 	Code string `json:"code" binding:"required,len=6"`
 }
+
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6"`
+}
+
+// TwoFactorCodeRequest takes a code from the authenticator or a recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type RecoveryCodesResponse struct {
+	Codes []string `json:"codes"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled       bool  `json:"enabled"`
+	RecoveryCodes int64 `json:"recovery_codes"`
+}
+
+type PutRoleTwoFactorRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+type PasskeyRegisterRequest struct {
+	Name       string                        `json:"name" binding:"required,max=100"`
+	Credential services.WebAuthnRegistration `json:"credential" binding:"required"`
+}
+
+type PasskeyLoginRequest struct {
+	Credential services.WebAuthnAssertion `json:"credential" binding:"required"`
+}
+
+type PasskeyDTO struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func ToPasskeyDTO(credential *models.WebAuthnCredential) PasskeyDTO {
+	return PasskeyDTO{
+		ID:         credential.ID,
+		Name:       credential.Name,
+		CreatedAt:  credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}
+}
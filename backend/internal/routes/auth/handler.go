@@ -3,12 +3,15 @@ package auth
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"luny.dev/cherryauctions/internal/logging"
 	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
 	"luny.dev/cherryauctions/internal/routes/shared"
 	"luny.dev/cherryauctions/internal/services"
 )
@@ -16,17 +19,19 @@ import (
 // PostLogin POST /auth/login
 //
 //	@summary		Logins to an existing account
-//	@description	Logins to an account using a username and a password registered with the server.
+//	@description	Logins to an account using a username and a password registered with the server. Accounts with two-factor authentication get a challenge to answer at /auth/2fa/login instead.
 //	@tags			authentication
 //	@accept			json
 //	@produce		json
 //	@param			credentials	body		auth.LoginRequest		true	"Login credentials"
-//	@success		200			{object}	auth.LoginResponse		"Login successful"
+//	@success		200			{object}	auth.LoginResponse					"Login successful"
+//	@success		202			{object}	auth.TwoFactorChallengeResponse	"Two-factor authentication required"
 //	@failure		400			{object}	shared.ErrorResponse	"Bad username or password format"
 //	@failure		401			{object}	shared.ErrorResponse	"Wrong password"
-//	@failure		403			{object}	shared.ErrorResponse	"Account is not verified"
+//	@failure		403			{object}	shared.ErrorResponse	"Account is not verified, or is suspended"
 //	@failure		404			{object}	shared.ErrorResponse	"Account does not exist"
 //	@failure		421			{object}	shared.ErrorResponse	"Account uses oauth but tries to login with password"
+//	@failure		429			{object}	shared.ErrorResponse	"Too many failed logins for the account or from the IP, see Retry-After"
 //	@failure		500			{object}	shared.ErrorResponse	"Server couldn't complete the request"
 //	@router			/auth/login [POST]
 func (h *AuthHandler) PostLogin(g *gin.Context) {
@@ -43,6 +48,12 @@ func (h *AuthHandler) PostLogin(g *gin.Context) {
 	loggingBody := body
 	loggingBody.Password = "[REDACTED]"
 
+	// Every attempt from the IP is counted up front, the same as for the account below.
+	ipKey := services.LoginIPKey(g.ClientIP())
+	if _, refused := h.reserveThrottle(g, loggingBody, ipKey, services.LoginIPPolicy); refused {
+		return
+	}
+
 	// Check if it's in the DB yet.
 	user, err := h.UserRepo.GetUserByEmail(ctx, body.Email)
 	if err != nil || user.Email == nil {
@@ -58,6 +69,13 @@ func (h *AuthHandler) PostLogin(g *gin.Context) {
 		return
 	}
 
+	// Counted before checking, so concurrent guesses can't all get in before the lockout.
+	accountKey := services.LoginAccountKey(user.ID)
+	lockout, refused := h.reserveThrottle(g, loggingBody, accountKey, services.LoginAccountPolicy)
+	if refused {
+		return
+	}
+
 	// Check the password hash
 	ok, err := h.PasswordService.VerifyPassword(*user.Password, body.Password)
 	if err != nil {
@@ -67,17 +85,23 @@ func (h *AuthHandler) PostLogin(g *gin.Context) {
 	}
 
 	if !ok {
+		if lockout > 0 {
+			h.MailerService.SendAccountLockedEmail(&user, time.Now().Add(lockout))
+		}
+
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": "wrong password", "body": loggingBody})
 		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "wrong password"})
 		return
 	}
+	h.resetThrottle(g, accountKey)
+	h.resetThrottle(g, ipKey)
 
-	var subscription *time.Time
-	if len(user.Subscriptions) > 0 {
-		subscription = &user.Subscriptions[0].ExpiredAt
+	if h.challengeTwoFactor(g, loggingBody, user) {
+		return
 	}
+
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "user successfully logged in", "body": loggingBody})
-	h.assignJWTKeyPair(g, loggingBody, user.ID, *user.Name, *user.Email, h.toRoleString(user.Roles), subscription, user.Verified)
+	h.assignUserSession(g, loggingBody, user)
 }
 
 // PostRegister POST /auth/register
@@ -109,8 +133,9 @@ func (h *AuthHandler) PostRegister(g *gin.Context) {
 		return
 	}
 
-	if err := h.CaptchaService.CheckGrecaptcha(body.CaptchaToken, g.ClientIP()); err != nil {
+	if err := h.CaptchaService.Check(ctx, services.CaptchaRegister, body.CaptchaToken, g.ClientIP()); err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "captcha failed"})
 		return
 	}
 
@@ -149,6 +174,7 @@ func (h *AuthHandler) PostRegister(g *gin.Context) {
 //	@tags			authentication
 //	@success		200	{object}	auth.LoginResponse		"Refreshed successfully"
 //	@failure		401	{object}	shared.ErrorResponse	"Did not attach refresh token"
+//	@failure		403	{object}	auth.SuspendedResponse	"Account is suspended"
 //	@router			/auth/refresh [POST]
 func (h *AuthHandler) PostRefresh(g *gin.Context) {
 	ctx := g.Request.Context()
@@ -169,14 +195,34 @@ func (h *AuthHandler) PostRefresh(g *gin.Context) {
 		return
 	}
 
+	refreshToken, err := h.RandomService.GenerateSecretKey(64)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't generate jwt key pair"})
+		return
+	}
+
+	// Rotate the refresh token, this also catches tokens that were already used.
 	hashedCookie := sha256.Sum256(decodedCookie)
 	savedToken := base64.URLEncoding.EncodeToString(hashedCookie[:])
-	token, err := h.RefreshTokenRepo.GetRefreshToken(ctx, savedToken)
-	if err != nil || token.IsRevoked {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": "revoked token or non-existent token"})
+	hashedToken := sha256.Sum256(refreshToken)
+	token, err := h.RefreshTokenRepo.RotateToken(ctx, savedToken, base64.URLEncoding.EncodeToString(hashedToken[:]), g.Request.UserAgent(), g.ClientIP())
+	if errors.Is(err, repositories.ErrRefreshTokenReused) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": "refresh token reused, revoked its session"})
+		g.SetCookie("RefreshToken", "", -1, "/", h.Domain, h.CookieSecure, true)
 		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "invalid refresh token"})
 		return
 	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repositories.ErrRefreshTokenExpired) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "invalid refresh token"})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unexpected error while rotating token"})
+		return
+	}
 
 	// Make sure the users are checked.
 	if token.User.ID == 0 || token.User.Email == nil {
@@ -184,43 +230,23 @@ func (h *AuthHandler) PostRefresh(g *gin.Context) {
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "this should be not preloaded"})
 		return
 	}
-
-	// Invalidate the token.
-	_, err = h.RefreshTokenRepo.InvalidateToken(ctx, savedToken)
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unexpected error while rotating token"})
+	if h.refuseSuspended(g, nil, token.User) {
+		g.SetCookie("RefreshToken", "", -1, "/", h.Domain, h.CookieSecure, true)
 		return
 	}
 
-	// Generate a new JWT key pair.
+	// Generate a new access token.
 	var subscription *time.Time
 	if len(token.User.Subscriptions) > 0 {
 		subscription = &token.User.Subscriptions[0].ExpiredAt
 	}
-	accessToken, err := h.JWTService.SignJWT(token.User.ID, *token.User.Name, *token.User.Email, h.toRoleString(token.User.Roles), subscription, token.User.Verified)
+	accessToken, err := h.JWTService.SignJWT(token.User.ID, *token.User.Name, *token.User.Email, h.toRoleString(token.User), subscription, token.User.Verified, token.FamilyID, token.User.TokenVersion)
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't sign jwt"})
 		return
 	}
 
-	refreshToken, err := h.RandomService.GenerateSecretKey(64)
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't generate jwt key pair"})
-		return
-	}
-
-	// Save the refresh token.
-	hashedToken := sha256.Sum256(refreshToken)
-	_, err = h.RefreshTokenRepo.SaveUserToken(ctx, token.User.ID, base64.URLEncoding.EncodeToString(hashedToken[:]))
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't hash refresh token"})
-		return
-	}
-
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "returned an access token"})
 	g.SetCookieData(&http.Cookie{
 		Name:     "RefreshToken",
@@ -238,7 +264,7 @@ func (h *AuthHandler) PostRefresh(g *gin.Context) {
 // PostLogout POST /auth/logout
 //
 //	@summary		Logouts and invalidates the refresh token if available.
-//	@description	Logouts, and also invalidates the refresh token. This does not revoke access tokens.
+//	@description	Logouts, and also invalidates the refresh token and the access tokens of its session.
 //	@tags			authentication
 //	@success		204	{object}	shared.MessageResponse	"Any request, regardless of authentication status"
 //	@router			/auth/logout [POST]
@@ -251,10 +277,16 @@ func (h *AuthHandler) PostLogout(g *gin.Context) {
 		decodedCookie, err := base64.URLEncoding.DecodeString(cookie)
 		if err == nil {
 			hashedCookie := sha256.Sum256(decodedCookie)
-			_, err = h.RefreshTokenRepo.InvalidateToken(ctx, base64.URLEncoding.EncodeToString(hashedCookie[:]))
+			savedToken := base64.URLEncoding.EncodeToString(hashedCookie[:])
+			_, err = h.RefreshTokenRepo.InvalidateToken(ctx, savedToken)
 			if err != nil {
 				logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "can't invalidate refresh token, was ignored", "token": string(decodedCookie)})
 			}
+
+			// Access tokens of the session stop working too.
+			if token, err := h.RefreshTokenRepo.GetRefreshToken(ctx, savedToken); err == nil {
+				h.TokenStateService.InvalidateSession(token.FamilyID)
+			}
 		}
 	}
 
@@ -266,12 +298,13 @@ func (h *AuthHandler) PostLogout(g *gin.Context) {
 // PostVerifyCheck godoc
 //
 //	@summary		Verifies an OTP code.
-//	@description	Verifies a user's OTP code.
+//	@description	Verifies a user's OTP code. An OTP only takes 5 wrong codes before a new one has to be sent, and too many wrong codes lock verifying out for a while.
 //	@tags			authentication
 //	@success		200	{object}	shared.MessageResponse	"Verification successfully"
 //	@failure		400	{object}	shared.ErrorResponse	"Failed to verify"
 //	@failure		401	{object}	shared.ErrorResponse	"Not logged in"
 //	@failure		422	{object}	shared.ErrorResponse	"Token is valid but user does not exist"
+//	@failure		429	{object}	shared.ErrorResponse	"Too many wrong codes, see Retry-After"
 //	@failure		500	{object}	shared.ErrorResponse	"Internal server error"
 //	@router			/auth/verify/check [POST]
 func (h *AuthHandler) PostVerifyCheck(g *gin.Context) {
@@ -297,12 +330,24 @@ func (h *AuthHandler) PostVerifyCheck(g *gin.Context) {
 		return
 	}
 
+	otpKey := services.OTPKey(claims.UserID)
+	if h.throttled(g, gin.H{"user_id": claims.UserID}, otpKey) {
+		return
+	}
+
 	err := h.OTPService.VerifyOTP(ctx, claims.UserID, body.Code)
+	if errors.Is(err, services.ErrOTPWrongOTP) || errors.Is(err, services.ErrOTPInvalidated) {
+		h.failThrottle(g, otpKey, services.OTPPolicy)
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
 		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "failed to verify otp"})
 		return
 	}
+	h.resetThrottle(g, otpKey)
 
 	// Update the verified status
 	_, err = h.UserRepo.UpdateUserVerified(ctx, claims.UserID, true)
@@ -311,6 +356,7 @@ func (h *AuthHandler) PostVerifyCheck(g *gin.Context) {
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to update verified status"})
 		return
 	}
+	h.TokenStateService.InvalidateUser(claims.UserID)
 
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "verified successfully"})
 	g.JSON(http.StatusOK, shared.MessageResponse{Message: "verified successfully, please refresh for a different access token"})
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// The state, nonce and PKCE verifier of a sign in are kept in a cookie until the callback.
+const oauthStateCookie = "OAuthState"
+
+// GetGoogle godoc
+//
+//	@summary		Starts signing in with Google.
+//	@description	Redirects to Google to sign in. Google redirects back to the frontend, which finishes with the callback endpoint.
+//	@tags			authentication
+//	@success		302
+//	@failure		404	{object}	shared.ErrorResponse	"Sign in with Google isn't configured"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/auth/google [GET]
+func (h *AuthHandler) GetGoogle(g *gin.Context) {
+	ctx := g.Request.Context()
+	if h.GoogleProvider == nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "google isn't configured"})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "sign in with google isn't available"})
+		return
+	}
+
+	values := make([]string, 3)
+	for i := range values {
+		random, err := h.RandomService.GenerateSecretKey(32)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't start signing in"})
+			return
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(random)
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	url, err := h.GoogleProvider.AuthCodeURL(ctx, state, nonce, services.PKCEChallenge(verifier))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't reach google"})
+		return
+	}
+
+	g.SetCookieData(&http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    strings.Join(values, "."),
+		Path:     "/",
+		Expires:  time.Now().Add(10 * time.Minute),
+		Domain:   h.Domain,
+		HttpOnly: true,
+		Secure:   h.CookieSecure,
+		SameSite: http.SameSiteNoneMode,
+	})
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusFound, "message": "redirected to google"})
+	g.Redirect(http.StatusFound, url)
+}
+
+// PostGoogleCallback godoc
+//
+//	@summary		Finishes signing in with Google.
+//	@description	Exchanges the code Google redirected back with. Accounts are linked by their verified email, or registered if there's none.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@param			body	body		auth.GoogleCallbackRequest	true	"Code and state from the redirect"
+//	@success		200		{object}	auth.LoginResponse			"Login successful"
+//	@success		202		{object}	auth.TwoFactorChallengeResponse	"Two-factor authentication required"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body or state"
+//	@failure		401		{object}	shared.ErrorResponse		"Google refused the code or the ID token is invalid"
+//	@failure		403		{object}	shared.ErrorResponse		"The Google account has no verified email, or the account is suspended"
+//	@failure		404		{object}	shared.ErrorResponse		"Sign in with Google isn't configured"
+//	@failure		409		{object}	shared.ErrorResponse		"The account is linked to another Google account"
+//	@failure		429		{object}	shared.ErrorResponse		"Too many wrong two-factor codes, see Retry-After"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/google/callback [POST]
+func (h *AuthHandler) PostGoogleCallback(g *gin.Context) {
+	ctx := g.Request.Context()
+	if h.GoogleProvider == nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "google isn't configured"})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "sign in with google isn't available"})
+		return
+	}
+
+	var body GoogleCallbackRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	loggingBody := body
+	loggingBody.Code = "[REDACTED]"
+
+	// The state can only be used once.
+	cookie, _ := g.Cookie(oauthStateCookie)
+	g.SetCookie(oauthStateCookie, "", -1, "/", h.Domain, h.CookieSecure, true)
+	values := strings.Split(cookie, ".")
+	if len(values) != 3 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(body.State)) != 1 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "state doesn't match", "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid state, please sign in again"})
+		return
+	}
+	nonce, verifier := values[1], values[2]
+
+	identity, err := h.GoogleProvider.Exchange(ctx, body.Code, verifier, nonce)
+	if errors.Is(err, services.ErrOIDCExchangeFailed) || errors.Is(err, services.ErrOIDCInvalidToken) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "google refused to sign in"})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't reach google"})
+		return
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "email isn't verified", "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "the google account has no verified email"})
+		return
+	}
+
+	user, status, err := h.findGoogleUser(g, identity)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": status, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(status, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if h.challengeTwoFactor(g, loggingBody, user) {
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "user successfully logged in with google", "user_id": user.ID})
+	h.assignUserSession(g, loggingBody, user)
+}
+
+// findGoogleUser finds the user of a Google account, linking it by email or registering a new
+// user the first time. Returns the status to respond with if it fails.
+func (h *AuthHandler) findGoogleUser(g *gin.Context, identity *services.OIDCIdentity) (models.User, int, error) {
+	ctx := g.Request.Context()
+
+	user, err := h.UserRepo.GetUserByOauthSubject(ctx, identity.Subject)
+	if err == nil {
+		return user, http.StatusOK, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, http.StatusInternalServerError, errors.New("can't find account")
+	}
+
+	user, err = h.UserRepo.GetUserByEmail(ctx, identity.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		name := identity.Name
+		if name == "" {
+			name, _, _ = strings.Cut(identity.Email, "@")
+		}
+
+		var avatarURL *string
+		if identity.Picture != "" {
+			avatarURL = &identity.Picture
+		}
+
+		user, err = h.UserRepo.RegisterOauthUser(ctx, name, identity.Email, "google", identity.Subject, avatarURL)
+		if err != nil {
+			return user, http.StatusInternalServerError, errors.New("can't register account")
+		}
+		logging.LogMessage(g, logging.LOG_INFO, gin.H{"message": "registered user with google", "user_id": user.ID})
+	} else if err != nil {
+		return user, http.StatusInternalServerError, errors.New("can't find account")
+	} else {
+		rows, err := h.UserRepo.LinkOauthAccount(ctx, user.ID, identity.Subject)
+		if err != nil {
+			return user, http.StatusInternalServerError, errors.New("can't link account")
+		}
+		if rows == 0 {
+			return user, http.StatusConflict, errors.New("account is linked to another google account")
+		}
+		// Linking an unverified user drops the credentials of whoever registered the email.
+		logging.LogMessage(g, logging.LOG_INFO, gin.H{"message": "linked user to google", "user_id": user.ID, "was_verified": user.Verified})
+	}
+
+	// Reload for the roles and subscription.
+	user, err = h.UserRepo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return user, http.StatusInternalServerError, errors.New("can't find account")
+	}
+	return user, http.StatusOK, nil
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/ranges"
+)
+
+// PostPasskeyRegisterBegin godoc
+//
+//	@summary		Starts adding a passkey.
+//	@description	Creates the options for navigator.credentials.create, with binary fields base64url encoded. The challenge is valid for 5 minutes.
+//	@tags			authentication
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{object}	services.PasskeyCreationOptions	"Creation options"
+//	@failure		401	{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/auth/passkeys/register/begin [POST]
+func (h *AuthHandler) PostPasskeyRegisterBegin(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	user, err := h.UserRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't find account"})
+		return
+	}
+
+	options, err := h.PasskeyService.BeginRegistration(ctx, user)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't start adding a passkey"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "passkey registration started", "user_id": claims.UserID})
+	g.JSON(http.StatusOK, options)
+}
+
+// PostPasskeyRegisterFinish godoc
+//
+//	@summary		Adds a passkey.
+//	@description	Verifies the response of navigator.credentials.create and stores the passkey. Only the "none" attestation is accepted, and the user has to be verified by the authenticator.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		auth.PasskeyRegisterRequest	true	"Name and credential"
+//	@success		201		{object}	auth.PasskeyDTO				"Added passkey"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body, response or challenge"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		409		{object}	shared.ErrorResponse		"Passkey is already registered"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/passkeys/register/finish [POST]
+func (h *AuthHandler) PostPasskeyRegisterFinish(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body PasskeyRegisterRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	credential, err := h.PasskeyService.FinishRegistration(ctx, claims.UserID, body.Name, body.Credential)
+	if errors.Is(err, services.ErrPasskeyChallengeInvalid) || errors.Is(err, services.ErrWebAuthnInvalidResponse) || errors.Is(err, services.ErrWebAuthnUnsupportedKey) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrPasskeyExists) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't add passkey"})
+		return
+	}
+
+	response := ToPasskeyDTO(credential)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "message": "passkey added", "user_id": claims.UserID, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// PostPasskeyLoginBegin godoc
+//
+//	@summary		Starts logging in with a passkey.
+//	@description	Creates the options for navigator.credentials.get, with binary fields base64url encoded. The challenge is valid for 5 minutes.
+//	@tags			authentication
+//	@produce		json
+//	@success		200	{object}	services.PasskeyRequestOptions	"Request options"
+//	@failure		500	{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/auth/passkeys/login/begin [POST]
+func (h *AuthHandler) PostPasskeyLoginBegin(g *gin.Context) {
+	options, err := h.PasskeyService.BeginLogin(g.Request.Context())
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't start logging in with a passkey"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "passkey login started"})
+	g.JSON(http.StatusOK, options)
+}
+
+// PostPasskeyLoginFinish godoc
+//
+//	@summary		Logs in with a passkey.
+//	@description	Verifies the response of navigator.credentials.get and logs in like the password does. Passkeys verify the user themselves, so two-factor authentication isn't asked for.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@param			body	body		auth.PasskeyLoginRequest	true	"Credential"
+//	@success		200		{object}	auth.LoginResponse			"Login successful"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body"
+//	@failure		401		{object}	shared.ErrorResponse		"Invalid response, challenge, signature or passkey"
+//	@failure		403		{object}	auth.SuspendedResponse		"Account is suspended"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/passkeys/login/finish [POST]
+func (h *AuthHandler) PostPasskeyLoginFinish(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	var body PasskeyLoginRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	loggingBody := gin.H{"credential_id": body.Credential.RawID}
+
+	userID, err := h.PasskeyService.FinishLogin(ctx, body.Credential)
+	if errors.Is(err, services.ErrPasskeyChallengeInvalid) || errors.Is(err, services.ErrPasskeyUnknown) ||
+		errors.Is(err, services.ErrWebAuthnInvalidResponse) || errors.Is(err, services.ErrWebAuthnBadSignature) ||
+		errors.Is(err, services.ErrWebAuthnSignCount) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check passkey"})
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't find account"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "user successfully logged in with a passkey", "user_id": user.ID})
+	h.assignUserSession(g, loggingBody, user)
+}
+
+// GetPasskeys godoc
+//
+//	@summary		Lists my passkeys.
+//	@description	Lists the passkeys of the current user, newest first.
+//	@tags			authentication
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{array}		auth.PasskeyDTO			"Passkeys"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/auth/passkeys [GET]
+func (h *AuthHandler) GetPasskeys(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	credentials, err := h.WebAuthnRepo.GetCredentials(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't get passkeys"})
+		return
+	}
+
+	response := ranges.EachAddress(credentials, ToPasskeyDTO)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": claims.UserID, "count": len(response)})
+	g.JSON(http.StatusOK, response)
+}
+
+// DeletePasskey godoc
+//
+//	@summary		Removes a passkey.
+//	@description	Removes a passkey of the current user, so it can't log in anymore.
+//	@tags			authentication
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		int						true	"Passkey ID"
+//	@success		200	{object}	shared.MessageResponse	"Removed"
+//	@failure		400	{object}	shared.ErrorResponse	"Invalid ID"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		404	{object}	shared.ErrorResponse	"No such passkey"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/auth/passkeys/{id} [DELETE]
+func (h *AuthHandler) DeletePasskey(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	rows, err := h.WebAuthnRepo.DeleteCredential(ctx, claims.UserID, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't remove passkey"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "no such passkey", "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "no such passkey"})
+		return
+	}
+
+	response := shared.MessageResponse{Message: "passkey removed"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": claims.UserID, "passkey": id})
+	g.JSON(http.StatusOK, response)
+}
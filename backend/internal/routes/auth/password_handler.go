@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// PostForgotPassword godoc
+//
+//	@summary		Requests a password reset.
+//	@description	Emails a single-use link to reset the password, if the email belongs to an account with a password. The response is the same either way.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@param			body	body		auth.ForgotPasswordRequest	true	"Account email"
+//	@success		202		{object}	shared.MessageResponse		"Reset email sent if the account exists"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid email"
+//	@failure		429		{object}	shared.ErrorResponse		"Too many requests for this email or IP"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/password/forgot [POST]
+func (h *AuthHandler) PostForgotPassword(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	var body ForgotPasswordRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err := h.PasswordResetService.RequestReset(ctx, body.Email, g.ClientIP())
+	if errors.Is(err, services.ErrResetRateLimited) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusTooManyRequests, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusTooManyRequests, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't request password reset"})
+		return
+	}
+
+	response := shared.MessageResponse{Message: "if the account exists, a reset link has been sent"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusAccepted, "body": body, "response": response})
+	g.JSON(http.StatusAccepted, response)
+}
+
+// PostResetPassword godoc
+//
+//	@summary		Resets a password.
+//	@description	Sets a new password using the token from a reset email. Every session of the account is logged out.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@param			body	body		auth.ResetPasswordRequest	true	"Reset token and new password"
+//	@success		200		{object}	shared.MessageResponse		"Password was reset"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body, or invalid or expired token"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/password/reset [POST]
+func (h *AuthHandler) PostResetPassword(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	var body ResetPasswordRequest
+	err := g.ShouldBindBodyWithJSON(&body)
+	loggingBody := body
+	loggingBody.Token = "[REDACTED]"
+	loggingBody.Password = "[REDACTED]"
+
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, err := h.PasswordResetService.ResetPassword(ctx, body.Token, body.Password)
+	if errors.Is(err, repositories.ErrResetTokenInvalid) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't reset password"})
+		return
+	}
+
+	h.TokenStateService.InvalidateUser(userID)
+
+	response := shared.MessageResponse{Message: "password was reset, please log in again"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": userID, "body": loggingBody, "response": response})
+	g.SetCookie("RefreshToken", "", -1, "/", h.Domain, h.CookieSecure, true)
+	g.JSON(http.StatusOK, response)
+}
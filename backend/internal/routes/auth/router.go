@@ -3,6 +3,7 @@ package auth
 import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
 	"luny.dev/cherryauctions/internal/repositories"
 	"luny.dev/cherryauctions/internal/services"
 )
@@ -12,16 +13,28 @@ type AuthHandler struct {
 	CookieSecure bool
 	Domain       string
 
-	RandomService     *services.RandomService
-	JWTService        *services.JWTService
-	PasswordService   *services.PasswordService
-	CaptchaService    *services.CaptchaService
-	MailerService     *services.MailerService
-	MiddlewareService *services.MiddlewareService
-	OTPService        *services.OTPService
+	RandomService        *services.RandomService
+	JWTService           *services.JWTService
+	PasswordService      *services.PasswordService
+	CaptchaService       *services.CaptchaService
+	MailerService        *services.MailerService
+	MiddlewareService    *services.MiddlewareService
+	OTPService           *services.OTPService
+	PasswordResetService *services.PasswordResetService
+	TokenStateService    *services.TokenStateService
+	TOTPService          *services.TOTPService
+	PasskeyService       *services.PasskeyService
+	ThrottleService      *services.ThrottleService
+	AuditService         *services.AuditService
+	PermissionService    *services.PermissionService
+
+	// Nil when sign in with Google isn't configured.
+	GoogleProvider services.IdentityProvider
 
 	RefreshTokenRepo *repositories.RefreshTokenRepository
 	UserRepo         *repositories.UserRepository
+	RoleRepo         *repositories.RoleRepository
+	WebAuthnRepo     *repositories.WebAuthnRepository
 }
 
 func (h *AuthHandler) SetupRouter(group *gin.RouterGroup) {
@@ -33,4 +46,21 @@ func (h *AuthHandler) SetupRouter(group *gin.RouterGroup) {
 	router.POST("/refresh", h.PostRefresh)
 	router.POST("/verify", h.MiddlewareService.SoftAuthorizedRoute, h.PostVerify)
 	router.POST("/verify/check", h.MiddlewareService.SoftAuthorizedRoute, h.PostVerifyCheck)
+	router.POST("/password/forgot", h.PostForgotPassword)
+	router.POST("/password/reset", h.PostResetPassword)
+	router.GET("/google", h.GetGoogle)
+	router.POST("/google/callback", h.PostGoogleCallback)
+	router.POST("/2fa/login", h.PostTwoFactorLogin)
+	router.GET("/2fa", h.MiddlewareService.AuthorizedRoute(""), h.GetTwoFactor)
+	router.POST("/2fa/setup", h.MiddlewareService.AuthorizedRoute(""), h.PostTwoFactorSetup)
+	router.POST("/2fa/confirm", h.MiddlewareService.AuthorizedRoute(""), h.PostTwoFactorConfirm)
+	router.POST("/2fa/recovery-codes", h.MiddlewareService.AuthorizedRoute(""), h.PostTwoFactorRecoveryCodes)
+	router.POST("/2fa/disable", h.MiddlewareService.AuthorizedRoute(""), h.PostTwoFactorDisable)
+	router.PUT("/2fa/roles/:id", h.MiddlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.PutRoleTwoFactor)
+	router.POST("/passkeys/register/begin", h.MiddlewareService.AuthorizedRoute(""), h.PostPasskeyRegisterBegin)
+	router.POST("/passkeys/register/finish", h.MiddlewareService.AuthorizedRoute(""), h.PostPasskeyRegisterFinish)
+	router.POST("/passkeys/login/begin", h.PostPasskeyLoginBegin)
+	router.POST("/passkeys/login/finish", h.PostPasskeyLoginFinish)
+	router.GET("/passkeys", h.MiddlewareService.AuthorizedRoute(""), h.GetPasskeys)
+	router.DELETE("/passkeys/:id", h.MiddlewareService.AuthorizedRoute(""), h.DeletePasskey)
 }
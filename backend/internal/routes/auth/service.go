@@ -3,7 +3,9 @@ package auth
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"luny.dev/cherryauctions/internal/logging"
 	"luny.dev/cherryauctions/internal/models"
 	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
 )
 
 func (h *AuthHandler) assignJWTKeyPair(
@@ -20,15 +23,9 @@ func (h *AuthHandler) assignJWTKeyPair(
 	name, email, roles string,
 	subscription *time.Time,
 	verified bool,
+	tokenVersion int64,
 ) {
-	// Generate a JWT key pair.
-	accessToken, err := h.JWTService.SignJWT(id, name, email, roles, subscription, verified)
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": "server can't sign jwt", "body": loggingBody})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't sign jwt"})
-		return
-	}
-
+	// Generate a JWT key pair, starting a new session.
 	refreshToken, err := h.RandomService.GenerateSecretKey(64)
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
@@ -38,13 +35,20 @@ func (h *AuthHandler) assignJWTKeyPair(
 
 	// Save the refresh token.
 	hashedToken := sha256.Sum256(refreshToken)
-	_, err = h.RefreshTokenRepo.SaveUserToken(g.Request.Context(), id, base64.URLEncoding.EncodeToString(hashedToken[:]))
+	session, err := h.RefreshTokenRepo.SaveUserToken(g.Request.Context(), id, base64.URLEncoding.EncodeToString(hashedToken[:]), g.Request.UserAgent(), g.ClientIP())
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't hash refresh token"})
 		return
 	}
 
+	accessToken, err := h.JWTService.SignJWT(id, name, email, roles, subscription, verified, session.FamilyID, tokenVersion)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": "server can't sign jwt", "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "server can't sign jwt"})
+		return
+	}
+
 	g.SetCookieData(&http.Cookie{
 		Name:     "RefreshToken",
 		Value:    base64.URLEncoding.EncodeToString(refreshToken),
@@ -58,9 +62,125 @@ func (h *AuthHandler) assignJWTKeyPair(
 	g.JSON(http.StatusOK, LoginResponse{AccessToken: accessToken})
 }
 
-func (h *AuthHandler) toRoleString(roles []models.Role) string {
+// assignUserSession logs a user in with a new JWT key pair.
+func (h *AuthHandler) assignUserSession(g *gin.Context, loggingBody any, user models.User) {
+	if h.refuseSuspended(g, loggingBody, user) {
+		return
+	}
+
+	var subscription *time.Time
+	if len(user.Subscriptions) > 0 {
+		subscription = &user.Subscriptions[0].ExpiredAt
+	}
+	h.assignJWTKeyPair(g, loggingBody, user.ID, *user.Name, *user.Email, h.toRoleString(user), subscription, user.Verified, user.TokenVersion)
+}
+
+// challengeTwoFactor responds with a login challenge instead of a session if the user has
+// two-factor authentication. Returns whether it responded.
+func (h *AuthHandler) challengeTwoFactor(g *gin.Context, loggingBody any, user models.User) bool {
+	if !user.TOTPEnabled {
+		return false
+	}
+	if h.refuseSuspended(g, loggingBody, user) {
+		return true
+	}
+	// New challenges shouldn't get around the lockout from wrong codes.
+	if h.throttled(g, loggingBody, services.TOTPKey(user.ID)) {
+		return true
+	}
+
+	token, validFor, err := h.TOTPService.CreateChallenge(g.Request.Context(), user.ID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't start two-factor authentication"})
+		return true
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusAccepted, "message": "two-factor authentication required", "user_id": user.ID, "body": loggingBody})
+	g.JSON(http.StatusAccepted, TwoFactorChallengeResponse{ChallengeToken: token, ExpiresIn: int(validFor.Seconds())})
+	return true
+}
+
+// refuseSuspended responds with 403 and the reason if the user is suspended or banned.
+// Returns whether it responded.
+func (h *AuthHandler) refuseSuspended(g *gin.Context, loggingBody any, user models.User) bool {
+	if !user.IsSuspended(time.Now()) {
+		return false
+	}
+
+	logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "account is suspended", "user_id": user.ID, "body": loggingBody})
+	g.AbortWithStatusJSON(http.StatusForbidden, SuspendedResponse{
+		Error:          "account is suspended",
+		Reason:         user.SuspensionReason,
+		SuspendedUntil: user.SuspendedUntil,
+		Banned:         user.Banned,
+	})
+	return true
+}
+
+// throttled responds with 429 if any of the keys are locked out. Returns whether it responded.
+func (h *AuthHandler) throttled(g *gin.Context, loggingBody any, keys ...string) bool {
+	wait, err := h.ThrottleService.Check(g.Request.Context(), keys...)
+	if err != nil {
+		// Logging in shouldn't break because throttling does.
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "can't check throttle", "body": loggingBody})
+		return false
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	h.tooManyAttempts(g, loggingBody, wait)
+	return true
+}
+
+// reserveThrottle counts an attempt against a key before it's checked, responding with 429 if the
+// key is locked out. Returns how long the key is locked out for if the attempt fails, and whether
+// it responded. Reset the key if the attempt succeeds.
+func (h *AuthHandler) reserveThrottle(g *gin.Context, loggingBody any, key string, policy services.ThrottlePolicy) (time.Duration, bool) {
+	lockout, reserved, err := h.ThrottleService.Reserve(g.Request.Context(), key, policy)
+	if err != nil {
+		// Logging in shouldn't break because throttling does.
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "can't reserve throttle", "key": key, "body": loggingBody})
+		return 0, false
+	}
+	if reserved {
+		return lockout, false
+	}
+
+	h.tooManyAttempts(g, loggingBody, lockout)
+	return 0, true
+}
+
+func (h *AuthHandler) tooManyAttempts(g *gin.Context, loggingBody any, wait time.Duration) {
+	g.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusTooManyRequests, "error": "too many attempts", "retry_after": wait.String(), "body": loggingBody})
+	g.AbortWithStatusJSON(http.StatusTooManyRequests, shared.ErrorResponse{Error: "too many attempts, please try again later"})
+}
+
+// failThrottle counts a failure against a key, returning how long it's now locked out for.
+func (h *AuthHandler) failThrottle(g *gin.Context, key string, policy services.ThrottlePolicy) time.Duration {
+	lockout, err := h.ThrottleService.Fail(g.Request.Context(), key, policy)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "can't count failure", "key": key})
+	}
+	return lockout
+}
+
+func (h *AuthHandler) resetThrottle(g *gin.Context, key string) {
+	if err := h.ThrottleService.Reset(g.Request.Context(), key); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "can't reset throttle", "key": key})
+	}
+}
+
+// toRoleString lists the roles for an access token. Roles that require two-factor authentication
+// are left out until the user enables it.
+func (h *AuthHandler) toRoleString(user models.User) string {
 	names := make([]string, 0)
-	for _, role := range roles {
+	for _, role := range user.Roles {
+		if role.RequireTOTP && !user.TOTPEnabled {
+			continue
+		}
 		names = append(names, role.ID)
 	}
 	return strings.Join(names, " ")
@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// PostTwoFactorLogin godoc
+//
+//	@summary		Finishes logging in with two-factor authentication.
+//	@description	Answers the challenge from logging in with a code from the authenticator, or a recovery code. A challenge takes at most 5 codes, and wrong codes across challenges lock the account out of two-factor logins for a while.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@param			body	body		auth.TwoFactorLoginRequest	true	"Challenge token and code"
+//	@success		200		{object}	auth.LoginResponse			"Login successful"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body"
+//	@failure		401		{object}	shared.ErrorResponse		"Wrong code, or invalid or expired challenge"
+//	@failure		403		{object}	auth.SuspendedResponse		"Account is suspended"
+//	@failure		429		{object}	shared.ErrorResponse		"Too many wrong codes, see Retry-After"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/2fa/login [POST]
+func (h *AuthHandler) PostTwoFactorLogin(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	var body TwoFactorLoginRequest
+	err := g.ShouldBindBodyWithJSON(&body)
+	loggingBody := body
+	loggingBody.ChallengeToken = "[REDACTED]"
+	loggingBody.Code = "[REDACTED]"
+
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, err := h.TOTPService.ChallengeUserID(ctx, body.ChallengeToken)
+	if errors.Is(err, services.ErrChallengeInvalid) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check two-factor code"})
+		return
+	}
+
+	totpKey := services.TOTPKey(userID)
+	if _, refused := h.reserveThrottle(g, loggingBody, totpKey, services.TOTPPolicy); refused {
+		return
+	}
+
+	userID, err = h.TOTPService.AnswerChallenge(ctx, body.ChallengeToken, body.Code)
+	if errors.Is(err, services.ErrChallengeInvalid) || errors.Is(err, services.ErrTOTPWrongCode) || errors.Is(err, services.ErrTOTPNotEnabled) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusUnauthorized, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check two-factor code"})
+		return
+	}
+	h.resetThrottle(g, totpKey)
+
+	user, err := h.UserRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't find account"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "user successfully logged in with two-factor authentication", "user_id": user.ID})
+	h.assignUserSession(g, loggingBody, user)
+}
+
+// GetTwoFactor godoc
+//
+//	@summary		Shows my two-factor authentication.
+//	@description	Shows whether two-factor authentication is enabled, and how many recovery codes are left.
+//	@tags			authentication
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{object}	auth.TwoFactorStatusResponse	"Status"
+//	@failure		401	{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/auth/2fa [GET]
+func (h *AuthHandler) GetTwoFactor(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	enabled, codes, err := h.TOTPService.Status(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't get two-factor authentication"})
+		return
+	}
+
+	response := TwoFactorStatusResponse{Enabled: enabled, RecoveryCodes: codes}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": claims.UserID, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostTwoFactorSetup godoc
+//
+//	@summary		Sets up two-factor authentication.
+//	@description	Creates a new TOTP secret to add to an authenticator, which replaces any unconfirmed one. It isn't asked for until confirmed.
+//	@tags			authentication
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{object}	auth.TwoFactorSetupResponse	"Secret and otpauth URI"
+//	@failure		401	{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		409	{object}	shared.ErrorResponse		"Already enabled"
+//	@failure		500	{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/2fa/setup [POST]
+func (h *AuthHandler) PostTwoFactorSetup(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	user, err := h.UserRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't find account"})
+		return
+	}
+
+	secret, uri, err := h.TOTPService.Setup(ctx, &user)
+	if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't set up two-factor authentication"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "two-factor authentication set up", "user_id": claims.UserID})
+	g.JSON(http.StatusOK, TwoFactorSetupResponse{Secret: secret, URI: uri})
+}
+
+// PostTwoFactorConfirm godoc
+//
+//	@summary		Enables two-factor authentication.
+//	@description	Confirms the secret from setting up with a code from the authenticator. The recovery codes are only shown once. Access tokens are revoked so roles that require two-factor authentication can be added.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		auth.TwoFactorConfirmRequest	true	"Code from the authenticator"
+//	@success		200		{object}	auth.RecoveryCodesResponse		"Enabled, with recovery codes"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body, wrong code or not set up"
+//	@failure		401		{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		409		{object}	shared.ErrorResponse			"Already enabled"
+//	@failure		500		{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/auth/2fa/confirm [POST]
+func (h *AuthHandler) PostTwoFactorConfirm(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body TwoFactorConfirmRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := h.TOTPService.Confirm(ctx, claims.UserID, body.Code)
+	if errors.Is(err, services.ErrTOTPWrongCode) || errors.Is(err, services.ErrTOTPNotSetUp) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't enable two-factor authentication"})
+		return
+	}
+	h.TokenStateService.InvalidateUser(claims.UserID)
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "two-factor authentication enabled", "user_id": claims.UserID})
+	g.JSON(http.StatusOK, RecoveryCodesResponse{Codes: codes})
+}
+
+// PostTwoFactorRecoveryCodes godoc
+//
+//	@summary		Replaces my recovery codes.
+//	@description	Generates new recovery codes, the old ones stop working. Takes a code from the authenticator or a recovery code.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		auth.TwoFactorCodeRequest	true	"Code"
+//	@success		200		{object}	auth.RecoveryCodesResponse	"New recovery codes"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body or wrong code"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		409		{object}	shared.ErrorResponse		"Not enabled"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/2fa/recovery-codes [POST]
+func (h *AuthHandler) PostTwoFactorRecoveryCodes(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body TwoFactorCodeRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := h.TOTPService.RegenerateRecoveryCodes(ctx, claims.UserID, body.Code)
+	if status, ok := twoFactorCodeStatus(err); ok {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": status, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(status, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't replace recovery codes"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "message": "recovery codes replaced", "user_id": claims.UserID})
+	g.JSON(http.StatusOK, RecoveryCodesResponse{Codes: codes})
+}
+
+// PostTwoFactorDisable godoc
+//
+//	@summary		Disables two-factor authentication.
+//	@description	Turns off two-factor authentication and deletes the recovery codes. Takes a code from the authenticator or a recovery code.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		auth.TwoFactorCodeRequest	true	"Code"
+//	@success		200		{object}	shared.MessageResponse		"Disabled"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body or wrong code"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		409		{object}	shared.ErrorResponse		"Not enabled"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/auth/2fa/disable [POST]
+func (h *AuthHandler) PostTwoFactorDisable(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body TwoFactorCodeRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err := h.TOTPService.Disable(ctx, claims.UserID, body.Code)
+	if status, ok := twoFactorCodeStatus(err); ok {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": status, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(status, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": claims.UserID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't disable two-factor authentication"})
+		return
+	}
+	h.TokenStateService.InvalidateUser(claims.UserID)
+
+	response := shared.MessageResponse{Message: "two-factor authentication disabled"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": claims.UserID, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PutRoleTwoFactor godoc
+//
+//	@summary		Requires two-factor authentication for a role.
+//	@description	Changes whether a role requires two-factor authentication. Users without it lose the role from their access tokens until they enable it. The admin has to have it enabled first, and every permission of the role.
+//	@tags			authentication
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		string						true	"Role ID"
+//	@param			body	body		auth.PutRoleTwoFactorRequest	true	"Whether it's required"
+//	@success		200		{object}	shared.MessageResponse			"Changed"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body"
+//	@failure		401		{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse			"Missing the roles.write permission, or a permission of the role"
+//	@failure		404		{object}	shared.ErrorResponse			"Role doesn't exist"
+//	@failure		409		{object}	shared.ErrorResponse			"The admin doesn't have two-factor authentication"
+//	@failure		500		{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/auth/2fa/roles/{id} [PUT]
+func (h *AuthHandler) PutRoleTwoFactor(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	roleID := g.Param("id")
+
+	var body PutRoleTwoFactorRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Requiring two-factor authentication takes the role away from everyone without it, so it's
+	// only for those who have every permission of the role.
+	permissions, err := h.PermissionService.Permissions(ctx, roleID)
+	var missing string
+	if err == nil {
+		missing, err = h.PermissionService.MissingPermission(ctx, claims.Roles, permissions)
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check permissions"})
+		return
+	}
+	if missing != "" {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "changing a role with a missing permission", "permission": missing, "role": roleID})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "you can't change roles with permissions you don't have"})
+		return
+	}
+
+	// Otherwise admins could lock themselves out of the admin role.
+	if *body.Required {
+		enabled, _, err := h.TOTPService.Status(ctx, claims.UserID)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "role": roleID})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't get two-factor authentication"})
+			return
+		}
+		if !enabled {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "admin has no two-factor authentication", "role": roleID})
+			g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "enable two-factor authentication before requiring it"})
+			return
+		}
+	}
+
+	rows, err := h.RoleRepo.SetRequireTOTP(ctx, roleID, *body.Required)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't change role"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "role doesn't exist", "role": roleID})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "role doesn't exist"})
+		return
+	}
+	h.TokenStateService.InvalidateAll()
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID: claims.UserID,
+		Action:  models.AuditActionRoleTwoFactor,
+		Details: map[string]any{"role": roleID, "required": *body.Required},
+	})
+
+	response := shared.MessageResponse{Message: "role was changed"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "role": roleID, "required": *body.Required, "admin_id": claims.UserID, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// twoFactorCodeStatus is the status to respond with when a code is refused.
+func twoFactorCodeStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrTOTPWrongCode):
+		return http.StatusBadRequest, true
+	case errors.Is(err, services.ErrTOTPNotEnabled):
+		return http.StatusConflict, true
+	}
+	return 0, false
+}
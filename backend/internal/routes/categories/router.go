@@ -19,7 +19,7 @@ func (h *CategoriesHandler) SetupRouter(g *gin.RouterGroup) {
 	r := g.Group("/categories")
 
 	r.GET("", h.GetCategories)
-	r.POST("", h.MiddlewareService.AuthorizedRoute(models.ROLE_ADMIN), h.PostCategories)
-	r.PUT("/:id", h.MiddlewareService.AuthorizedRoute(models.ROLE_ADMIN), h.PutCategories)
-	r.DELETE("/:id", h.MiddlewareService.AuthorizedRoute(models.ROLE_ADMIN), h.DeleteCategories)
+	r.POST("", h.MiddlewareService.RequirePermission(models.PERMISSION_CATEGORIES_WRITE), h.PostCategories)
+	r.PUT("/:id", h.MiddlewareService.RequirePermission(models.PERMISSION_CATEGORIES_WRITE), h.PutCategories)
+	r.DELETE("/:id", h.MiddlewareService.RequirePermission(models.PERMISSION_CATEGORIES_WRITE), h.DeleteCategories)
 }
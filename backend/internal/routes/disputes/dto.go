@@ -0,0 +1,59 @@
+package disputes
+
+import (
+	"mime/multipart"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
+type PostDisputeRequest struct {
+	TransactionID uint   `json:"transaction_id" form:"transaction_id" binding:"required,gt=0"`
+	Reason        string `json:"reason" form:"reason" binding:"required,min=10,max=2000"`
+}
+
+type GetDisputesQuery struct {
+	shared.PaginationRequest
+	Status models.DisputeStatus `form:"status" binding:"omitempty,oneof=open resolved"`
+}
+
+type PostStatementRequest struct {
+	Content    string                `form:"content" json:"content" binding:"required,min=1,max=5000"`
+	Attachment *multipart.FileHeader `form:"attachment"`
+}
+
+type PostResolveRequest struct {
+	Resolution models.DisputeResolution `json:"resolution" form:"resolution" binding:"required,oneof=buyer seller"`
+	Note       string                   `json:"note" form:"note" binding:"required,min=1,max=2000"`
+}
+
+type DisputeStatementDTO struct {
+	ID            uint              `json:"id"`
+	Author        shared.ProfileDTO `json:"author"`
+	Content       string            `json:"content"`
+	AttachmentURL *string           `json:"attachment_url"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type DisputeDTO struct {
+	ID             uint                      `json:"id"`
+	Transaction    shared.TransactionDTO     `json:"transaction"`
+	OpenedBy       shared.ProfileDTO         `json:"opened_by"`
+	Reason         string                    `json:"reason"`
+	Status         models.DisputeStatus      `json:"status"`
+	PreviousStatus models.TransactionStatus  `json:"previous_status"`
+	Resolution     *models.DisputeResolution `json:"resolution"`
+	ResolutionNote *string                   `json:"resolution_note"`
+	ResolvedAt     *time.Time                `json:"resolved_at"`
+	Statements     []DisputeStatementDTO     `json:"statements"`
+	CreatedAt      time.Time                 `json:"created_at"`
+}
+
+type DisputesResponse struct {
+	Data       []DisputeDTO `json:"data"`
+	Total      int64        `json:"total"`
+	TotalPages int          `json:"total_pages"`
+	Page       int          `json:"page"`
+	PerPage    int          `json:"per_page"`
+}
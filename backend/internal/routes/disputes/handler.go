@@ -0,0 +1,431 @@
+package disputes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/closer"
+)
+
+// Evidence that can be attached to a statement, by sniffed content type.
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+	"application/pdf": "pdf",
+}
+
+// How long the links to evidence stay valid for.
+const attachmentURLValidFor = 15 * time.Minute
+
+// canAccess checks if the user is a party of the disputed transaction, or can resolve disputes.
+func (h *DisputesHandler) canAccess(g *gin.Context, sub *services.JWTSubject, dispute *models.Dispute) bool {
+	return sub.UserID == dispute.Transaction.BuyerID || sub.UserID == dispute.Transaction.SellerID ||
+		h.middlewareService.Can(g, sub, models.PERMISSION_DISPUTES_RESOLVE)
+}
+
+// presignAttachments hands out short-lived links to the evidence of each statement, which
+// only the parties and arbitrators get to see.
+func (h *DisputesHandler) presignAttachments(g *gin.Context, dispute *models.Dispute, dto *DisputeDTO) {
+	for i, statement := range dispute.Statements {
+		if statement.AttachmentKey == nil {
+			continue
+		}
+
+		filename := fmt.Sprintf("evidence-%d%s", statement.ID, path.Ext(*statement.AttachmentKey))
+		url, err := h.s3Service.PresignGetObject(g.Request.Context(), *statement.AttachmentKey, filename, attachmentURLValidFor)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "failed to presign attachment"})
+			continue
+		}
+		dto.Statements[i].AttachmentURL = &url
+	}
+}
+
+// GetDisputes godoc
+//
+//	@summary		Gets the dispute queue.
+//	@description	Gets disputes by status for admins to arbitrate, oldest first. Defaults to open disputes.
+//	@tags			disputes
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			status		query		string						false	"Dispute status"	Enums(open, resolved)
+//	@param			page		query		int							false	"Page Number"
+//	@param			per_page	query		int							false	"Items per Page"
+//	@success		200			{object}	disputes.DisputesResponse	"Successful query"
+//	@failure		400			{object}	shared.ErrorResponse		"Bad request"
+//	@failure		401			{object}	shared.ErrorResponse		"Unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse		"Missing the disputes.resolve permission"
+//	@failure		500			{object}	shared.ErrorResponse		"The server could not complete the request"
+//	@router			/disputes [get]
+func (h *DisputesHandler) GetDisputes(g *gin.Context) {
+	ctx := g.Request.Context()
+	query := GetDisputesQuery{
+		PaginationRequest: shared.PaginationRequest{
+			Page:    1,
+			PerPage: 20,
+		},
+		Status: models.DisputeStatusOpen,
+	}
+
+	if err := g.ShouldBind(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	disputes, err := h.disputeRepo.GetDisputes(ctx, query.Status, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't find disputes"})
+		return
+	}
+
+	count, err := h.disputeRepo.CountDisputes(ctx, query.Status)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't count disputes"})
+		return
+	}
+
+	dtos := make([]DisputeDTO, 0, len(disputes))
+	for _, dispute := range disputes {
+		dtos = append(dtos, ToDisputeDTO(&dispute))
+	}
+
+	response := DisputesResponse{
+		Data:       dtos,
+		Total:      count,
+		TotalPages: int(math.Ceil(float64(count) / float64(query.PerPage))),
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostDispute godoc
+//
+//	@summary		Opens a dispute on a transaction.
+//	@description	Lets the buyer or the seller dispute a paid or delivered transaction. The transaction is frozen until an admin resolves the dispute.
+//	@tags			disputes
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		disputes.PostDisputeRequest	true	"Body"
+//	@success		201		{object}	shared.IDResponse			"Successfully opened a dispute"
+//	@failure		400		{object}	shared.ErrorResponse		"Bad request"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse		"Not a party of the transaction, or it can't be disputed at this stage"
+//	@failure		404		{object}	shared.ErrorResponse		"Unknown transaction ID"
+//	@failure		500		{object}	shared.ErrorResponse		"The server could not complete the request"
+//	@router			/disputes [post]
+func (h *DisputesHandler) PostDispute(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	var body PostDisputeRequest
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	transaction, err := h.transactionRepo.GetTransactionByID(ctx, body.TransactionID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+		return
+	}
+
+	if sub.UserID != transaction.BuyerID && sub.UserID != transaction.SellerID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not a party of the transaction", "body": body})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not a party of the transaction"})
+		return
+	}
+
+	dispute := models.Dispute{
+		TransactionID: transaction.ID,
+		OpenedByID:    sub.UserID,
+		Reason:        body.Reason,
+	}
+	err = h.disputeRepo.OpenDispute(ctx, &dispute)
+	if errors.Is(err, repositories.ErrDisputeNotAllowed) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to open dispute"})
+		return
+	}
+
+	if transaction.Product.ChatSession != nil {
+		transaction.TransactionStatus = models.TransactionStatusDisputed
+		h.chatHandler.SendTransactionChangeNotification(transaction.Product.ChatSession.ID, &transaction)
+	}
+
+	response := shared.IDResponse{ID: dispute.ID}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "body": body, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// GetDispute godoc
+//
+//	@summary		Gets a dispute.
+//	@description	Gets a dispute with all of its statements. Only visible to the parties of the transaction and admins. Links to evidence expire after 15 minutes.
+//	@tags			disputes
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		int						true	"Dispute ID"
+//	@success		200	{object}	disputes.DisputeDTO		"Successful query"
+//	@failure		400	{object}	shared.ErrorResponse	"Invalid ID"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403	{object}	shared.ErrorResponse	"Not a party of the dispute"
+//	@failure		404	{object}	shared.ErrorResponse	"Unknown dispute ID"
+//	@router			/disputes/{id} [get]
+func (h *DisputesHandler) GetDispute(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	dispute, err := h.disputeRepo.GetDisputeByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown dispute"})
+		return
+	}
+
+	if !h.canAccess(g, sub, &dispute) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not a party of the dispute"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not a party of the dispute"})
+		return
+	}
+
+	response := ToDisputeDTO(&dispute)
+	h.presignAttachments(g, &dispute, &response)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostStatement godoc
+//
+//	@summary		Adds a statement to a dispute.
+//	@description	Adds a statement to an open dispute, optionally with an image or PDF attached as evidence.
+//	@tags			disputes
+//	@accept			mpfd
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id			path		int						true	"Dispute ID"
+//	@param			content		formData	string					true	"Statement"
+//	@param			attachment	formData	file					false	"Evidence, up to 10MB (JPEG, PNG, WebP or PDF)"
+//	@success		201			{object}	shared.IDResponse		"Successfully added a statement"
+//	@failure		400			{object}	shared.ErrorResponse	"Invalid ID, body or attachment format"
+//	@failure		401			{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse	"Not a party of the dispute, or the dispute is resolved"
+//	@failure		404			{object}	shared.ErrorResponse	"Unknown dispute ID"
+//	@failure		413			{object}	shared.ErrorResponse	"Attachment too heavy"
+//	@failure		500			{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/disputes/{id}/statements [post]
+func (h *DisputesHandler) PostStatement(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	dispute, err := h.disputeRepo.GetDisputeByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown dispute"})
+		return
+	}
+
+	if !h.canAccess(g, sub, &dispute) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not a party of the dispute"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not a party of the dispute"})
+		return
+	}
+
+	if dispute.Status != models.DisputeStatusOpen {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "dispute is resolved"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "dispute is resolved"})
+		return
+	}
+
+	var body PostStatementRequest
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	var attachmentKey *string
+	if body.Attachment != nil {
+		if body.Attachment.Size > (10 << 20) /* 10MB */ {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "attachment too large", "status": http.StatusRequestEntityTooLarge, "size": body.Attachment.Size})
+			g.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, shared.ErrorResponse{Error: "max 10MB allowed"})
+			return
+		}
+
+		file, err := body.Attachment.Open()
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusBadRequest})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "can't read attachment"})
+			return
+		}
+		defer closer.CloseResources(file)
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusBadRequest})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "can't read attachment"})
+			return
+		}
+
+		// Don't trust the client's content type, sniff it instead.
+		ext, ok := attachmentExtensions[http.DetectContentType(data)]
+		if !ok {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "unsupported attachment format", "status": http.StatusBadRequest})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid attachment format"})
+			return
+		}
+
+		hash := sha256.Sum256(data)
+		key := fmt.Sprintf("disputes/%s.%s", hex.EncodeToString(hash[:]), ext)
+		if err := h.s3Service.PutPrivateObject(ctx, key, bytes.NewReader(data), http.DetectContentType(data)); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "storage upload failed"})
+			return
+		}
+
+		attachmentKey = &key
+	}
+
+	statement := models.DisputeStatement{
+		DisputeID:     dispute.ID,
+		AuthorID:      sub.UserID,
+		Content:       body.Content,
+		AttachmentKey: attachmentKey,
+	}
+	if err := h.disputeRepo.CreateDisputeStatement(ctx, &statement); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "statement creation failed"})
+		return
+	}
+
+	response := shared.IDResponse{ID: statement.ID}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// PostResolve godoc
+//
+//	@summary		Resolves a dispute.
+//	@description	Rules a dispute in favour of one side. Ruling for the seller completes the transaction, ruling for the buyer cancels and refunds it. The losing side gets a negative rating.
+//	@tags			disputes
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int							true	"Dispute ID"
+//	@param			body	body		disputes.PostResolveRequest	true	"Ruling"
+//	@success		200		{object}	shared.MessageResponse		"Successfully resolved the dispute"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse		"Missing the disputes.resolve permission"
+//	@failure		404		{object}	shared.ErrorResponse		"Unknown dispute ID"
+//	@failure		409		{object}	shared.ErrorResponse		"Dispute is already resolved"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/disputes/{id}/resolve [post]
+func (h *DisputesHandler) PostResolve(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PostResolveRequest
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	dispute, err := h.disputeRepo.GetDisputeByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown dispute"})
+		return
+	}
+
+	err = h.disputeRepo.ResolveDispute(ctx, dispute.ID, sub.UserID, body.Resolution, body.Note)
+	if errors.Is(err, repositories.ErrDisputeResolved) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to resolve dispute"})
+		return
+	}
+
+	transaction := dispute.Transaction
+	transaction.TransactionStatus = models.TransactionStatusCompleted
+	if body.Resolution == models.DisputeResolutionBuyer {
+		transaction.TransactionStatus = models.TransactionStatusCancelled
+
+		if err := h.paymentService.RefundTransaction(ctx, transaction.ID); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "resolved dispute, but failed to refund payment"})
+			return
+		}
+	} else if err := h.feeService.SettleTransaction(ctx, transaction.ID); err != nil {
+		// The scheduler catches up on this later.
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "failed to settle transaction"})
+	} else {
+		h.invoiceService.QueueInvoice(transaction.ID)
+	}
+
+	if transaction.Product.ChatSession != nil {
+		h.chatHandler.SendTransactionChangeNotification(transaction.Product.ChatSession.ID, &transaction)
+	}
+
+	response := shared.MessageResponse{Message: "resolved dispute"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
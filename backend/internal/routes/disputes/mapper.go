@@ -0,0 +1,32 @@
+package disputes
+
+import (
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
+func ToDisputeDTO(m *models.Dispute) DisputeDTO {
+	statements := make([]DisputeStatementDTO, 0, len(m.Statements))
+	for _, statement := range m.Statements {
+		statements = append(statements, DisputeStatementDTO{
+			ID:        statement.ID,
+			Author:    shared.ToProfileDTO(&statement.Author),
+			Content:   statement.Content,
+			CreatedAt: statement.CreatedAt,
+		})
+	}
+
+	return DisputeDTO{
+		ID:             m.ID,
+		Transaction:    shared.ToTransactionDTO(&m.Transaction),
+		OpenedBy:       shared.ToProfileDTO(&m.OpenedBy),
+		Reason:         m.Reason,
+		Status:         m.Status,
+		PreviousStatus: m.PreviousStatus,
+		Resolution:     m.Resolution,
+		ResolutionNote: m.ResolutionNote,
+		ResolvedAt:     m.ResolvedAt,
+		Statements:     statements,
+		CreatedAt:      m.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+// Package disputes provides endpoints for disputing a transaction and for admins to arbitrate them.
+package disputes
+
+import (
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/chat"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+type DisputesHandler struct {
+	disputeRepo       *repositories.DisputeRepository
+	transactionRepo   *repositories.TransactionRepository
+	paymentService    *services.PaymentService
+	feeService        *services.FeeService
+	invoiceService    *services.InvoiceService
+	s3Service         *services.S3Service
+	middlewareService *services.MiddlewareService
+	chatHandler       *chat.ChatHandler
+}
+
+func NewDisputesHandler(
+	disputeRepo *repositories.DisputeRepository,
+	transactionRepo *repositories.TransactionRepository,
+	paymentService *services.PaymentService,
+	feeService *services.FeeService,
+	invoiceService *services.InvoiceService,
+	s3Service *services.S3Service,
+	middlewareService *services.MiddlewareService,
+	chatHandler *chat.ChatHandler,
+) *DisputesHandler {
+	return &DisputesHandler{
+		disputeRepo:       disputeRepo,
+		transactionRepo:   transactionRepo,
+		paymentService:    paymentService,
+		feeService:        feeService,
+		invoiceService:    invoiceService,
+		s3Service:         s3Service,
+		middlewareService: middlewareService,
+		chatHandler:       chatHandler,
+	}
+}
+
+func (h *DisputesHandler) SetupRouter(g *gin.RouterGroup) {
+	r := g.Group("/disputes")
+
+	r.GET("", h.middlewareService.RequirePermission(models.PERMISSION_DISPUTES_RESOLVE), h.GetDisputes)
+	r.POST("", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PostDispute)
+	r.GET("/:id", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.GetDispute)
+	r.POST("/:id/statements", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PostStatement)
+	r.POST("/:id/resolve", h.middlewareService.RequirePermission(models.PERMISSION_DISPUTES_RESOLVE), h.PostResolve)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// GetJWKS publishes the public keys access tokens are signed with at /.well-known/jwks.json, which
+// is outside of the versioned API. Keys are empty when signing with HS256.
+func GetJWKS(jwtService *services.JWTService) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		g.Header("Cache-Control", "public, max-age=300")
+		g.JSON(http.StatusOK, jwtService.JWKS())
+	}
+}
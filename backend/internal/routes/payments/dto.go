@@ -0,0 +1,5 @@
+package payments
+
+type PostMockCheckoutRequest struct {
+	Outcome string `json:"outcome" binding:"required,oneof=succeeded failed"`
+}
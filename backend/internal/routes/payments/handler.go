@@ -0,0 +1,145 @@
+package payments
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+const signatureHeader = "X-Payment-Signature"
+
+// PostWebhook godoc
+//
+//	@summary		Receives payment provider webhooks.
+//	@description	Receives a signed event from the payment provider. Confirmed payments move the transaction to paid. Retried events are acknowledged without being applied twice.
+//	@tags			payments
+//	@accept			json
+//	@produce		json
+//	@param			X-Payment-Signature	header		string					true	"Webhook signature"
+//	@success		200					{object}	shared.MessageResponse	"Event was processed, or was already processed"
+//	@failure		400					{object}	shared.ErrorResponse	"Invalid signature or payload"
+//	@failure		404					{object}	shared.ErrorResponse	"Unknown payment reference"
+//	@failure		500					{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/payments/webhook [post]
+func (h *PaymentsHandler) PostWebhook(g *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(g.Request.Body, 1<<20))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "can't read body"})
+		return
+	}
+
+	h.applyWebhook(g, payload, g.GetHeader(signatureHeader))
+}
+
+// PostMockCheckout godoc
+//
+//	@summary		Completes a payment on the mock gateway.
+//	@description	Only available with the mock payment provider. Simulates the buyer paying (or failing to pay), and delivers a signed webhook for it.
+//	@tags			payments
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			reference	path		string							true	"Payment reference"
+//	@param			body		body		payments.PostMockCheckoutRequest	true	"Outcome of the payment"
+//	@success		200			{object}	shared.MessageResponse			"Event was processed"
+//	@failure		400			{object}	shared.ErrorResponse			"Invalid body"
+//	@failure		401			{object}	shared.ErrorResponse			"Unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse			"Not the buyer of the transaction"
+//	@failure		404			{object}	shared.ErrorResponse			"Mock gateway disabled or unknown payment reference"
+//	@failure		500			{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/payments/mock/{reference} [post]
+func (h *PaymentsHandler) PostMockCheckout(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	provider, ok := h.paymentService.Provider().(*services.MockPaymentProvider)
+	if !ok {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "mock gateway is disabled"})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "mock gateway is disabled"})
+		return
+	}
+
+	var body PostMockCheckoutRequest
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	intent, err := h.paymentService.GetPaymentIntent(ctx, g.Param("reference"))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown payment"})
+		return
+	}
+
+	if intent.Transaction.BuyerID != sub.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not the buyer"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not the buyer"})
+		return
+	}
+
+	eventID, err := h.randomService.GenerateSecretKey(16)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't generate event"})
+		return
+	}
+
+	eventType := services.PaymentEventSucceeded
+	if body.Outcome == "failed" {
+		eventType = services.PaymentEventFailed
+	}
+
+	payload, _ := json.Marshal(services.PaymentEvent{
+		ID:        "mock_evt_" + hex.EncodeToString(eventID),
+		Type:      eventType,
+		Reference: intent.Reference,
+	})
+	h.applyWebhook(g, payload, provider.SignWebhook(payload, time.Now()))
+}
+
+// applyWebhook runs a webhook payload through the payment service and writes the response.
+func (h *PaymentsHandler) applyWebhook(g *gin.Context, payload []byte, signature string) {
+	intent, err := h.paymentService.HandleWebhook(g.Request.Context(), payload, signature)
+	switch {
+	case errors.Is(err, repositories.ErrPaymentEventProcessed):
+		response := shared.MessageResponse{Message: "already processed"}
+		logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+		g.JSON(http.StatusOK, response)
+		return
+	case errors.Is(err, services.ErrPaymentInvalidSignature), errors.Is(err, services.ErrPaymentInvalidPayload):
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown payment"})
+		return
+	case err != nil:
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to process event"})
+		return
+	}
+
+	if intent.Status == models.PaymentIntentStatusSucceeded && intent.Transaction.Product.ChatSession != nil {
+		h.chatHandler.SendTransactionChangeNotification(intent.Transaction.Product.ChatSession.ID, &intent.Transaction)
+	}
+
+	response := shared.MessageResponse{Message: "processed"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response, "payment_id": intent.ID})
+	g.JSON(http.StatusOK, response)
+}
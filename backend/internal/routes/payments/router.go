@@ -0,0 +1,44 @@
+// Package payments provides endpoints for payment provider webhooks and the local mock gateway.
+package payments
+
+import (
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/chat"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+type PaymentsHandler struct {
+	paymentService    *services.PaymentService
+	randomService     *services.RandomService
+	middlewareService *services.MiddlewareService
+	chatHandler       *chat.ChatHandler
+	mockCheckout      bool
+}
+
+func NewPaymentsHandler(
+	paymentService *services.PaymentService,
+	randomService *services.RandomService,
+	middlewareService *services.MiddlewareService,
+	chatHandler *chat.ChatHandler,
+	mockCheckout bool,
+) *PaymentsHandler {
+	return &PaymentsHandler{
+		paymentService:    paymentService,
+		randomService:     randomService,
+		middlewareService: middlewareService,
+		chatHandler:       chatHandler,
+		mockCheckout:      mockCheckout,
+	}
+}
+
+func (h *PaymentsHandler) SetupRouter(g *gin.RouterGroup) {
+	r := g.Group("/payments")
+
+	if h.paymentService.Enabled() {
+		r.POST("/webhook", h.PostWebhook)
+	}
+	if h.mockCheckout {
+		r.POST("/mock/:reference", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PostMockCheckout)
+	}
+}
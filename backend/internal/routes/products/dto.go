@@ -11,11 +11,13 @@ type ProductImageDTO struct {
 }
 
 type ProfileDTO struct {
-	ID            uint    `json:"id"`
-	Name          *string `json:"name"`
-	Email         *string `json:"email"`
-	AvatarURL     *string `json:"avatar_url"`
-	AverageRating float64 `json:"average_rating"`
+	ID              uint    `json:"id"`
+	Name            *string `json:"name"`
+	Email           *string `json:"email"`
+	AvatarURL       *string `json:"avatar_url"`
+	PositiveRatings int64   `json:"positive_ratings"`
+	NegativeRatings int64   `json:"negative_ratings"`
+	Reputation      float64 `json:"reputation"`
 }
 
 type CategoryDTO struct {
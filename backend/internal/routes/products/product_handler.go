@@ -318,7 +318,7 @@ func (h *ProductsHandler) PostProduct(g *gin.Context) {
 	claimsAny, _ := g.Get("claims")
 	claims := claimsAny.(*services.JWTSubject)
 
-	// Make sure the user has the permission.
+	// Make sure the user has the permission. Lapsed sellers keep their live auctions, but can't post.
 	if claims.SubscriptionExpiredAt == nil || claims.SubscriptionExpiredAt.Before(time.Now()) {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "user can't post"})
 		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "you can't post"})
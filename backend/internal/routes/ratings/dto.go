@@ -1,13 +1,74 @@
 package ratings
 
+import (
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
 type PostRatingBody struct {
-	Rating     uint   `json:"rating" binding:"min=0,max=1"`
-	Feedback   string `json:"feedback" binding:"required"`
-	RevieweeID uint   `json:"reviewee_id" binding:"required"`
-	ProductID  uint   `json:"product_id" binding:"required"`
+	// 1 for a like, -1 for a dislike.
+	Rating   int8   `json:"rating" binding:"required,oneof=-1 1"`
+	Feedback string `json:"feedback" binding:"required"`
+	// The transaction to rate, or the product it's for.
+	TransactionID uint `json:"transaction_id" binding:"required_without=ProductID"`
+	ProductID     uint `json:"product_id" binding:"required_without=TransactionID"`
 }
 
 type PutRatingBody struct {
-	Rating   uint   `json:"rating" binding:"min=0,max=1"`
+	// 1 for a like, -1 for a dislike.
+	Rating   int8   `json:"rating" binding:"required,oneof=-1 1"`
 	Feedback string `json:"feedback" binding:"required"`
 }
+
+type PostReplyBody struct {
+	Reply string `json:"reply" binding:"required,max=2000"`
+}
+
+type PostReportBody struct {
+	Reason string `json:"reason" binding:"required,min=5,max=2000"`
+}
+
+type GetReportsQuery struct {
+	shared.PaginationRequest
+	Status models.RatingReportStatus `form:"status" binding:"omitempty,oneof=open resolved"`
+}
+
+type PostModerateBody struct {
+	Action models.ModerationAction `json:"action" binding:"required,oneof=dismiss hide remove"`
+	Reason string                  `json:"reason" binding:"required,min=1,max=2000"`
+}
+
+// ReportedRatingDTO is a reported rating as admins see it, with the original feedback.
+type ReportedRatingDTO struct {
+	ID             uint              `json:"id"`
+	Rating         int8              `json:"rating"`
+	Feedback       string            `json:"feedback"`
+	FeedbackHidden bool              `json:"feedback_hidden"`
+	Removed        bool              `json:"removed"`
+	Reviewer       shared.ProfileDTO `json:"reviewer"`
+	Reviewee       shared.ProfileDTO `json:"reviewee"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+type RatingReportDTO struct {
+	ID           uint                      `json:"id"`
+	Rating       ReportedRatingDTO         `json:"rating"`
+	Reporter     shared.ProfileDTO         `json:"reporter"`
+	Reason       string                    `json:"reason"`
+	Status       models.RatingReportStatus `json:"status"`
+	Action       *models.ModerationAction  `json:"action"`
+	ActionReason *string                   `json:"action_reason"`
+	ResolvedBy   *shared.ProfileDTO        `json:"resolved_by"`
+	ResolvedAt   *time.Time                `json:"resolved_at"`
+	CreatedAt    time.Time                 `json:"created_at"`
+}
+
+type RatingReportsResponse struct {
+	Data       []RatingReportDTO `json:"data"`
+	Total      int64             `json:"total"`
+	TotalPages int               `json:"total_pages"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"per_page"`
+}
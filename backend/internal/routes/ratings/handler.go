@@ -1,12 +1,15 @@
 package ratings
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"luny.dev/cherryauctions/internal/logging"
 	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
 	"luny.dev/cherryauctions/internal/routes/shared"
 	"luny.dev/cherryauctions/internal/services"
 )
@@ -19,14 +22,17 @@ func (h *RatingHandler) Claims(g *gin.Context) *services.JWTSubject {
 // PostRating godoc
 //
 //	@summary		Creates a rating.
-//	@description	Posts a rating and feedback onto a users profile.
+//	@description	Rates the other party of a completed or cancelled transaction the user took part in. Each side can rate the other once per transaction.
 //	@tags			ratings
 //	@produce		json
 //	@accept			json
 //	@param			rating	body		ratings.PostRatingBody	true	"Rating context"
 //	@success		201		{object}	shared.MessageResponse	"Successfully rated person"
-//	@failure		400		{object}	shared.ErrorResponse	"Invalid reviewee ID or invalid format"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid format"
 //	@failure		401		{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse	"Not a party of the transaction, or it isn't finished yet"
+//	@failure		404		{object}	shared.ErrorResponse	"Unknown transaction"
+//	@failure		409		{object}	shared.ErrorResponse	"Already rated this transaction"
 //	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
 //	@router			/ratings [POST]
 func (h *RatingHandler) PostRating(g *gin.Context) {
@@ -39,20 +45,38 @@ func (h *RatingHandler) PostRating(g *gin.Context) {
 		return
 	}
 
-	if body.RevieweeID == claims.UserID {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "can't rate self"})
-		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "can't rate self"})
-		return
+	transactionID := body.TransactionID
+	if transactionID == 0 {
+		transaction, err := h.transactionRepo.GetTransactionByProductID(ctx, body.ProductID)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+			g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+			return
+		}
+		transactionID = transaction.ID
 	}
 
 	rating := models.Rating{
-		ProductID:  body.ProductID,
 		ReviewerID: claims.UserID,
-		RevieweeID: body.RevieweeID,
 		Rating:     body.Rating,
 		Feedback:   body.Feedback,
 	}
-	err := h.ratingRepo.CreateRating(ctx, &rating)
+	err := h.ratingRepo.RateTransaction(ctx, transactionID, &rating)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+		return
+	}
+	if errors.Is(err, repositories.ErrNotTransactionParty) || errors.Is(err, repositories.ErrRatingNotOpen) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrAlreadyRated) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't create rating"})
@@ -135,10 +159,75 @@ func (h *RatingHandler) PutRating(g *gin.Context) {
 	g.JSON(http.StatusOK, response)
 }
 
+// PostReply godoc
+//
+//	@summary		Replies to a rating.
+//	@description	Lets the reviewee of a rating reply to it publicly. A rating can only be replied to once.
+//	@tags			ratings
+//	@produce		json
+//	@accept			json
+//	@security		ApiKeyAuth
+//	@param			id		path		int						true	"Rating ID"
+//	@param			reply	body		ratings.PostReplyBody	true	"Reply"
+//	@success		201		{object}	shared.MessageResponse	"Successfully replied"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid ID or format"
+//	@failure		401		{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse	"Not a rating of you"
+//	@failure		404		{object}	shared.ErrorResponse	"Rating ID not found"
+//	@failure		409		{object}	shared.ErrorResponse	"Already replied"
+//	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/ratings/{id}/reply [POST]
+func (h *RatingHandler) PostReply(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims := h.Claims(g)
+	var body PostReplyBody
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "invalid id"})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	rating, err := h.ratingRepo.GetRatingByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown rating"})
+		return
+	}
+
+	if rating.RevieweeID != claims.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not a rating of you"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not a rating of you"})
+		return
+	}
+
+	rows, err := h.ratingRepo.ReplyToRating(ctx, rating.ID, body.Reply)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't reply to rating"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "already replied"})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "already replied"})
+		return
+	}
+
+	response := shared.MessageResponse{Message: "replied to rating"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "body": body, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
 // DeleteRating godoc
 //
 //	@summary		Deletes a rating.
-//	@description	Deletes a rating.
+//	@description	Deletes a rating. The transaction can't be rated again afterwards.
 //	@tags			ratings
 //	@produce		json
 //	@param			id	path		int						true	"Rating ID"
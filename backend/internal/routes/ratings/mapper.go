@@ -0,0 +1,36 @@
+package ratings
+
+import (
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
+func ToRatingReportDTO(m *models.RatingReport) RatingReportDTO {
+	var resolvedBy *shared.ProfileDTO
+	if m.ResolvedBy != nil {
+		dto := shared.ToProfileDTO(m.ResolvedBy)
+		resolvedBy = &dto
+	}
+
+	return RatingReportDTO{
+		ID: m.ID,
+		Rating: ReportedRatingDTO{
+			ID:             m.Rating.ID,
+			Rating:         m.Rating.Rating,
+			Feedback:       m.Rating.Feedback,
+			FeedbackHidden: m.Rating.FeedbackHiddenAt != nil,
+			Removed:        m.Rating.DeletedAt.Valid,
+			Reviewer:       shared.ToProfileDTO(&m.Rating.Reviewer),
+			Reviewee:       shared.ToProfileDTO(&m.Rating.Reviewee),
+			CreatedAt:      m.Rating.CreatedAt,
+		},
+		Reporter:     shared.ToProfileDTO(&m.Reporter),
+		Reason:       m.Reason,
+		Status:       m.Status,
+		Action:       m.Action,
+		ActionReason: m.ActionReason,
+		ResolvedBy:   resolvedBy,
+		ResolvedAt:   m.ResolvedAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
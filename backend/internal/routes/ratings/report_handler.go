@@ -0,0 +1,198 @@
+package ratings
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
+// PostReport godoc
+//
+//	@summary		Reports a rating.
+//	@description	Flags a rating as abusive for the admins to review. Every user can report a rating once.
+//	@tags			ratings
+//	@produce		json
+//	@accept			json
+//	@security		ApiKeyAuth
+//	@param			id		path		int						true	"Rating ID"
+//	@param			body	body		ratings.PostReportBody	true	"Report"
+//	@success		201		{object}	shared.IDResponse		"Successfully reported"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid ID or format"
+//	@failure		401		{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		404		{object}	shared.ErrorResponse	"Rating ID not found"
+//	@failure		409		{object}	shared.ErrorResponse	"Already reported"
+//	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/ratings/{id}/reports [POST]
+func (h *RatingHandler) PostReport(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims := h.Claims(g)
+	var body PostReportBody
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "invalid id"})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	rating, err := h.ratingRepo.GetRatingByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown rating"})
+		return
+	}
+
+	report := models.RatingReport{
+		RatingID:   rating.ID,
+		ReporterID: claims.UserID,
+		Reason:     body.Reason,
+	}
+	err = h.reportRepo.CreateReport(ctx, &report)
+	if errors.Is(err, repositories.ErrAlreadyReported) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't report rating"})
+		return
+	}
+
+	response := shared.IDResponse{ID: report.ID}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "body": body, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// GetReports godoc
+//
+//	@summary		Gets the moderation queue of reported ratings.
+//	@description	Lists reports of ratings with a status, oldest first. Admins see the original feedback, even if it was hidden or removed.
+//	@tags			ratings
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			status		query		string							false	"Report status, defaults to open"	Enums(open, resolved)
+//	@param			page		query		int								false	"Page number"
+//	@param			per_page	query		int								false	"Items per page"
+//	@success		200			{object}	ratings.RatingReportsResponse	"The reports"
+//	@failure		400			{object}	shared.ErrorResponse			"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse			"Unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse			"Missing the ratings.moderate permission"
+//	@failure		500			{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/ratings/reports [GET]
+func (h *RatingHandler) GetReports(g *gin.Context) {
+	ctx := g.Request.Context()
+	query := GetReportsQuery{
+		PaginationRequest: shared.PaginationRequest{
+			Page:    1,
+			PerPage: 20,
+		},
+		Status: models.RatingReportStatusOpen,
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	reports, err := h.reportRepo.GetReports(ctx, query.Status, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't find reports"})
+		return
+	}
+
+	count, err := h.reportRepo.CountReports(ctx, query.Status)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't count reports"})
+		return
+	}
+
+	dtos := make([]RatingReportDTO, 0, len(reports))
+	for _, report := range reports {
+		dtos = append(dtos, ToRatingReportDTO(&report))
+	}
+
+	response := RatingReportsResponse{
+		Data:       dtos,
+		Total:      count,
+		TotalPages: int(math.Ceil(float64(count) / float64(query.PerPage))),
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostModerate godoc
+//
+//	@summary		Moderates a reported rating.
+//	@description	Resolves a report by dismissing it, hiding the feedback of the rating, or removing the rating entirely. Every open report of the same rating is resolved with it.
+//	@tags			ratings
+//	@produce		json
+//	@accept			json
+//	@security		ApiKeyAuth
+//	@param			id		path		int							true	"Report ID"
+//	@param			body	body		ratings.PostModerateBody	true	"Moderation"
+//	@success		200		{object}	shared.MessageResponse		"Successfully moderated"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid ID or format"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse		"Missing the ratings.moderate permission"
+//	@failure		404		{object}	shared.ErrorResponse		"Report ID not found"
+//	@failure		409		{object}	shared.ErrorResponse		"Report already resolved"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/ratings/reports/{id}/moderate [POST]
+func (h *RatingHandler) PostModerate(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims := h.Claims(g)
+	var body PostModerateBody
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "invalid id"})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	err = h.reportRepo.ModerateRating(ctx, uint(id), claims.UserID, body.Action, body.Reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown report"})
+		return
+	}
+	if errors.Is(err, repositories.ErrReportResolved) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't moderate rating"})
+		return
+	}
+
+	// Keep a trace of who did what and why, on top of the report itself.
+	response := shared.MessageResponse{Message: "moderated rating"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "report_id": id, "admin_id": claims.UserID, "action": body.Action, "reason": body.Reason, "response": response})
+	g.JSON(http.StatusOK, response)
+}
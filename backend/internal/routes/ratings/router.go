@@ -9,17 +9,23 @@ import (
 )
 
 type RatingHandler struct {
-	ratingRepo *repositories.RatingRepostory
-	middleware *services.MiddlewareService
+	ratingRepo      *repositories.RatingRepostory
+	transactionRepo *repositories.TransactionRepository
+	reportRepo      *repositories.RatingReportRepository
+	middleware      *services.MiddlewareService
 }
 
 func NewRatingRouter(
 	ratingRepo *repositories.RatingRepostory,
+	transactionRepo *repositories.TransactionRepository,
+	reportRepo *repositories.RatingReportRepository,
 	middleware *services.MiddlewareService,
 ) *RatingHandler {
 	return &RatingHandler{
-		ratingRepo: ratingRepo,
-		middleware: middleware,
+		ratingRepo:      ratingRepo,
+		transactionRepo: transactionRepo,
+		reportRepo:      reportRepo,
+		middleware:      middleware,
 	}
 }
 
@@ -28,4 +34,8 @@ func (h *RatingHandler) SetupRouter(g *gin.RouterGroup) {
 
 	r.POST("", h.middleware.AuthorizedRoute(models.ROLE_USER), h.PostRating)
 	r.PUT("/:id", h.middleware.AuthorizedRoute(models.ROLE_USER), h.PutRating)
+	r.POST("/:id/reply", h.middleware.AuthorizedRoute(models.ROLE_USER), h.PostReply)
+	r.POST("/:id/reports", h.middleware.AuthorizedRoute(models.ROLE_USER), h.PostReport)
+	r.GET("/reports", h.middleware.RequirePermission(models.PERMISSION_RATINGS_MODERATE), h.GetReports)
+	r.POST("/reports/:id/moderate", h.middleware.RequirePermission(models.PERMISSION_RATINGS_MODERATE), h.PostModerate)
 }
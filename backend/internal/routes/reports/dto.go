@@ -0,0 +1,28 @@
+package reports
+
+import "time"
+
+type GetRevenueQuery struct {
+	Interval string    `form:"interval" json:"interval" binding:"omitempty,oneof=day week month"`
+	From     time.Time `form:"from" json:"from" time_format:"2006-01-02"`
+	To       time.Time `form:"to" json:"to" time_format:"2006-01-02"`
+}
+
+type RevenueBucketDTO struct {
+	Period       time.Time `json:"period"`
+	Transactions int64     `json:"transactions"`
+	GrossAmount  int64     `json:"gross_amount"`
+	FeeAmount    int64     `json:"fee_amount"`
+	NetAmount    int64     `json:"net_amount"`
+}
+
+// RevenueResponse is the platform revenue over a date range. FeeAmount is what the platform earned,
+// NetAmount is what was paid out to sellers.
+type RevenueResponse struct {
+	Interval     string             `json:"interval"`
+	Data         []RevenueBucketDTO `json:"data"`
+	Transactions int64              `json:"transactions"`
+	GrossAmount  int64              `json:"gross_amount"`
+	FeeAmount    int64              `json:"fee_amount"`
+	NetAmount    int64              `json:"net_amount"`
+}
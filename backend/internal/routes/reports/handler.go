@@ -0,0 +1,77 @@
+package reports
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/routes/shared"
+)
+
+// GetRevenue godoc
+//
+//	@summary		Gets the platform revenue report.
+//	@description	Aggregates the fees earned and payouts owed on settled transactions over a date range, grouped by day, week or month.
+//	@tags			reports
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			interval	query		string					false	"Grouping interval, defaults to day"	Enums(day, week, month)
+//	@param			from		query		string					false	"Settled on or after this date (YYYY-MM-DD)"
+//	@param			to			query		string					false	"Settled on or before this date (YYYY-MM-DD)"
+//	@success		200			{object}	reports.RevenueResponse	"Successful query"
+//	@failure		400			{object}	shared.ErrorResponse	"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse	"Missing the reports.read permission"
+//	@failure		500			{object}	shared.ErrorResponse	"The server could not complete the request"
+//	@router			/reports/revenue [get]
+func (h *ReportsHandler) GetRevenue(g *gin.Context) {
+	ctx := g.Request.Context()
+	query := GetRevenueQuery{
+		Interval: "day",
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	var from, to *time.Time
+	if !query.From.IsZero() {
+		from = &query.From
+	}
+	if !query.To.IsZero() {
+		// The end date is inclusive.
+		end := query.To.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	buckets, err := h.payoutRepo.GetRevenueReport(ctx, query.Interval, from, to)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't aggregate revenue"})
+		return
+	}
+
+	response := RevenueResponse{
+		Interval: query.Interval,
+		Data:     make([]RevenueBucketDTO, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		response.Data = append(response.Data, RevenueBucketDTO{
+			Period:       bucket.Period,
+			Transactions: bucket.Count,
+			GrossAmount:  bucket.GrossAmount,
+			FeeAmount:    bucket.FeeAmount,
+			NetAmount:    bucket.NetAmount,
+		})
+		response.Transactions += bucket.Count
+		response.GrossAmount += bucket.GrossAmount
+		response.FeeAmount += bucket.FeeAmount
+		response.NetAmount += bucket.NetAmount
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "query": query, "response": response})
+	g.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,30 @@
+// Package reports provides reporting endpoints for admins.
+package reports
+
+import (
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+type ReportsHandler struct {
+	payoutRepo        *repositories.PayoutRepository
+	middlewareService *services.MiddlewareService
+}
+
+func NewReportsHandler(
+	payoutRepo *repositories.PayoutRepository,
+	middlewareService *services.MiddlewareService,
+) *ReportsHandler {
+	return &ReportsHandler{
+		payoutRepo:        payoutRepo,
+		middlewareService: middlewareService,
+	}
+}
+
+func (h *ReportsHandler) SetupRouter(g *gin.RouterGroup) {
+	r := g.Group("/reports")
+
+	r.GET("/revenue", h.middlewareService.RequirePermission(models.PERMISSION_REPORTS_READ), h.GetRevenue)
+}
@@ -0,0 +1,24 @@
+package roles
+
+type PermissionDTO struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+type RoleDTO struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	RequireTOTP bool     `json:"require_totp"`
+	Permissions []string `json:"permissions"`
+}
+
+type PostRoleRequest struct {
+	ID          string   `json:"id" binding:"required,max=50,lowercase,alphanum"`
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions" binding:"max=100"`
+}
+
+type PutRoleRequest struct {
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions" binding:"max=100"`
+}
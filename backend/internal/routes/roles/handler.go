@@ -0,0 +1,265 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// GetRoles godoc
+//
+//	@summary		Gets every role.
+//	@description	Lists the roles with the permissions they grant.
+//	@tags			roles
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{array}		roles.RoleDTO			"Successful"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		403	{object}	shared.ErrorResponse	"Missing the roles.write permission"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/roles [GET]
+func (h *RolesHandler) GetRoles(g *gin.Context) {
+	roles, err := h.roleRepo.GetRoles(g.Request.Context())
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't get roles"})
+		return
+	}
+
+	response := make([]RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, ToRoleDTO(&role))
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// GetPermissions godoc
+//
+//	@summary		Gets every permission.
+//	@description	Lists the permissions that can be granted to roles.
+//	@tags			roles
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{array}		roles.PermissionDTO		"Successful"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		403	{object}	shared.ErrorResponse	"Missing the roles.write permission"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/roles/permissions [GET]
+func (h *RolesHandler) GetPermissions(g *gin.Context) {
+	permissions, err := h.roleRepo.GetPermissions(g.Request.Context())
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't get permissions"})
+		return
+	}
+
+	response := make([]PermissionDTO, 0, len(permissions))
+	for _, permission := range permissions {
+		response = append(response, ToPermissionDTO(&permission))
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostRole godoc
+//
+//	@summary		Creates a role.
+//	@description	Creates a role granting a set of permissions, which can then be assigned to users. Only permissions you have can be granted.
+//	@tags			roles
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		roles.PostRoleRequest	true	"The role"
+//	@success		201		{object}	roles.RoleDTO			"Created"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid body or unknown permission"
+//	@failure		401		{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse	"Missing the roles.write permission, or a permission being granted"
+//	@failure		409		{object}	shared.ErrorResponse	"Role already exists"
+//	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/roles [POST]
+func (h *RolesHandler) PostRole(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body PostRoleRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if h.refuseEscalation(g, claims, body.Permissions) {
+		return
+	}
+
+	role := models.Role{ID: body.ID, Description: body.Description}
+	err := h.roleRepo.CreateRole(g.Request.Context(), &role, body.Permissions)
+	if errors.Is(err, repositories.ErrUnknownPermission) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrRoleExists) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't create role"})
+		return
+	}
+	h.permissionService.Invalidate()
+	h.auditService.Record(g.Request.Context(), models.AuditLog{
+		ActorID: claims.UserID,
+		Action:  models.AuditActionRoleCreate,
+		Details: map[string]any{"role": role.ID, "permissions": body.Permissions},
+	})
+
+	response := ToRoleDTO(&role)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// PutRole godoc
+//
+//	@summary		Changes a role.
+//	@description	Replaces the description and permissions of a role, taking effect for everyone with it. Only permissions you have can be granted. The admin role always has every permission.
+//	@tags			roles
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		string					true	"Role ID"
+//	@param			body	body		roles.PutRoleRequest	true	"The role"
+//	@success		200		{object}	roles.RoleDTO			"Changed"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid body or unknown permission"
+//	@failure		401		{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse	"Missing the roles.write permission, or a permission being granted"
+//	@failure		404		{object}	shared.ErrorResponse	"Role doesn't exist"
+//	@failure		409		{object}	shared.ErrorResponse	"The admin role can't be changed"
+//	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/roles/{id} [PUT]
+func (h *RolesHandler) PutRole(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	roleID := g.Param("id")
+
+	var body PutRoleRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// It gets every permission back on startup anyway.
+	if roleID == models.ROLE_ADMIN {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "can't change admin role", "role": roleID})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "the admin role always has every permission"})
+		return
+	}
+	if h.refuseEscalation(g, claims, body.Permissions) {
+		return
+	}
+
+	role, err := h.roleRepo.UpdateRole(g.Request.Context(), roleID, body.Description, body.Permissions)
+	if errors.Is(err, repositories.ErrUnknownPermission) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "role": roleID, "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrUnknownRole) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "role": roleID, "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't change role"})
+		return
+	}
+	h.permissionService.Invalidate()
+	h.auditService.Record(g.Request.Context(), models.AuditLog{
+		ActorID: claims.UserID,
+		Action:  models.AuditActionRoleUpdate,
+		Details: map[string]any{"role": roleID, "permissions": body.Permissions},
+	})
+
+	response := ToRoleDTO(&role)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// DeleteRole godoc
+//
+//	@summary		Deletes a role.
+//	@description	Takes a role away from everyone who has it, then deletes it. The default and admin roles can't be deleted.
+//	@tags			roles
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		string					true	"Role ID"
+//	@success		200	{object}	shared.MessageResponse	"Deleted"
+//	@failure		401	{object}	shared.ErrorResponse	"Unauthenticated"
+//	@failure		403	{object}	shared.ErrorResponse	"Missing the roles.write permission"
+//	@failure		404	{object}	shared.ErrorResponse	"Role doesn't exist"
+//	@failure		409	{object}	shared.ErrorResponse	"The role can't be deleted"
+//	@failure		500	{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/roles/{id} [DELETE]
+func (h *RolesHandler) DeleteRole(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	roleID := g.Param("id")
+
+	if roleID == models.ROLE_USER || roleID == models.ROLE_ADMIN {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "can't delete built-in role", "role": roleID})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "the default and admin roles can't be deleted"})
+		return
+	}
+
+	err := h.roleRepo.DeleteRole(g.Request.Context(), roleID)
+	if errors.Is(err, repositories.ErrUnknownRole) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "role": roleID})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't delete role"})
+		return
+	}
+	h.permissionService.Invalidate()
+	h.tokenStateService.InvalidateAll()
+	h.auditService.Record(g.Request.Context(), models.AuditLog{
+		ActorID: claims.UserID,
+		Action:  models.AuditActionRoleDelete,
+		Details: map[string]any{"role": roleID},
+	})
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "role": roleID})
+	g.JSON(http.StatusOK, shared.MessageResponse{Message: "deleted role"})
+}
+
+// refuseEscalation responds with 403 if a role would grant a permission the caller doesn't have,
+// so roles.write can't be turned into every other permission. Returns whether it responded.
+func (h *RolesHandler) refuseEscalation(g *gin.Context, claims *services.JWTSubject, permissions []string) bool {
+	missing, err := h.permissionService.MissingPermission(g.Request.Context(), claims.Roles, permissions)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check permissions"})
+		return true
+	}
+	if missing != "" {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "granting a missing permission", "permission": missing})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "you can't grant permissions you don't have"})
+		return true
+	}
+	return false
+}
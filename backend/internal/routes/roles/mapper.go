@@ -0,0 +1,24 @@
+package roles
+
+import "luny.dev/cherryauctions/internal/models"
+
+func ToPermissionDTO(permission *models.Permission) PermissionDTO {
+	return PermissionDTO{
+		ID:          permission.ID,
+		Description: permission.Description,
+	}
+}
+
+func ToRoleDTO(role *models.Role) RoleDTO {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.ID)
+	}
+
+	return RoleDTO{
+		ID:          role.ID,
+		Description: role.Description,
+		RequireTOTP: role.RequireTOTP,
+		Permissions: permissions,
+	}
+}
@@ -0,0 +1,43 @@
+// Package roles provides endpoints for admins to manage roles and what they're allowed to do.
+package roles
+
+import (
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+type RolesHandler struct {
+	roleRepo          *repositories.RoleRepository
+	middlewareService *services.MiddlewareService
+	permissionService *services.PermissionService
+	tokenStateService *services.TokenStateService
+	auditService      *services.AuditService
+}
+
+func NewRolesHandler(
+	roleRepo *repositories.RoleRepository,
+	middlewareService *services.MiddlewareService,
+	permissionService *services.PermissionService,
+	tokenStateService *services.TokenStateService,
+	auditService *services.AuditService,
+) *RolesHandler {
+	return &RolesHandler{
+		roleRepo:          roleRepo,
+		middlewareService: middlewareService,
+		permissionService: permissionService,
+		tokenStateService: tokenStateService,
+		auditService:      auditService,
+	}
+}
+
+func (h *RolesHandler) SetupRouter(g *gin.RouterGroup) {
+	r := g.Group("/roles")
+
+	r.GET("", h.middlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.GetRoles)
+	r.GET("/permissions", h.middlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.GetPermissions)
+	r.POST("", h.middlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.PostRole)
+	r.PUT("/:id", h.middlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.PutRole)
+	r.DELETE("/:id", h.middlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.DeleteRole)
+}
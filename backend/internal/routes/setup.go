@@ -18,9 +18,13 @@ import (
 	"luny.dev/cherryauctions/internal/routes/auth"
 	"luny.dev/cherryauctions/internal/routes/categories"
 	"luny.dev/cherryauctions/internal/routes/chat"
+	"luny.dev/cherryauctions/internal/routes/disputes"
+	"luny.dev/cherryauctions/internal/routes/payments"
 	"luny.dev/cherryauctions/internal/routes/products"
 	"luny.dev/cherryauctions/internal/routes/questions"
 	"luny.dev/cherryauctions/internal/routes/ratings"
+	"luny.dev/cherryauctions/internal/routes/reports"
+	"luny.dev/cherryauctions/internal/routes/roles"
 	"luny.dev/cherryauctions/internal/routes/transactions"
 	"luny.dev/cherryauctions/internal/routes/users"
 	"luny.dev/cherryauctions/internal/services"
@@ -57,30 +61,53 @@ func SetupRoutes(server *gin.Engine, deps ServerDependency) {
 	versionedGroup := server.Group(deps.Version)
 
 	authHandler := auth.AuthHandler{
-		DB:                deps.DB,
-		CookieSecure:      deps.Config.CookieSecure,
-		Domain:            deps.Config.Domain,
-		JWTService:        deps.Services.JWTService,
-		RandomService:     deps.Services.RandomService,
-		PasswordService:   deps.Services.PasswordService,
-		CaptchaService:    deps.Services.CaptchaService,
-		MailerService:     deps.Services.MailerService,
-		MiddlewareService: deps.Services.MiddlewareService,
-		OTPService:        deps.Services.OTPService,
-		UserRepo:          deps.Repositories.UserRepository,
-		RefreshTokenRepo:  deps.Repositories.RefreshTokenRepository,
+		DB:                   deps.DB,
+		CookieSecure:         deps.Config.CookieSecure,
+		Domain:               deps.Config.Domain,
+		JWTService:           deps.Services.JWTService,
+		RandomService:        deps.Services.RandomService,
+		PasswordService:      deps.Services.PasswordService,
+		CaptchaService:       deps.Services.CaptchaService,
+		MailerService:        deps.Services.MailerService,
+		MiddlewareService:    deps.Services.MiddlewareService,
+		OTPService:           deps.Services.OTPService,
+		PasswordResetService: deps.Services.PasswordResetService,
+		TokenStateService:    deps.Services.TokenStateService,
+		TOTPService:          deps.Services.TOTPService,
+		PasskeyService:       deps.Services.PasskeyService,
+		ThrottleService:      deps.Services.ThrottleService,
+		AuditService:         deps.Services.AuditService,
+		PermissionService:    deps.Services.PermissionService,
+		GoogleProvider:       deps.Services.GoogleProvider,
+		UserRepo:             deps.Repositories.UserRepository,
+		RefreshTokenRepo:     deps.Repositories.RefreshTokenRepository,
+		RoleRepo:             deps.Repositories.RoleRepository,
+		WebAuthnRepo:         deps.Repositories.WebAuthnRepository,
 	}
 	authHandler.SetupRouter(versionedGroup)
 
 	usersHandler := users.UsersHandler{
-		DB:                deps.DB,
-		MiddlewareService: deps.Services.MiddlewareService,
-		PasswordService:   deps.Services.PasswordService,
-		UserRepo:          deps.Repositories.UserRepository,
-		ProductRepo:       deps.Repositories.ProductRepository,
-		RatingRepo:        deps.Repositories.RatingRepostory,
-		S3Service:         deps.Services.S3Service,
-		S3PermURL:         deps.Config.AWS.S3PermURL,
+		DB:                  deps.DB,
+		MiddlewareService:   deps.Services.MiddlewareService,
+		PasswordService:     deps.Services.PasswordService,
+		TokenStateService:   deps.Services.TokenStateService,
+		EmailChangeService:  deps.Services.EmailChangeService,
+		MailerService:       deps.Services.MailerService,
+		SubscriptionService: deps.Services.SubscriptionService,
+		PermissionService:   deps.Services.PermissionService,
+		AuditService:        deps.Services.AuditService,
+		UserRepo:            deps.Repositories.UserRepository,
+		ProductRepo:         deps.Repositories.ProductRepository,
+		RatingRepo:          deps.Repositories.RatingRepostory,
+		TransactionRepo:     deps.Repositories.TransactionRepository,
+		PayoutRepo:          deps.Repositories.PayoutRepository,
+		RefreshTokenRepo:    deps.Repositories.RefreshTokenRepository,
+		SellerRequestRepo:   deps.Repositories.SellerRequestRepository,
+		RoleRepo:            deps.Repositories.RoleRepository,
+		SubscriptionRepo:    deps.Repositories.SubscriptionRepository,
+		AuditLogRepo:        deps.Repositories.AuditLogRepository,
+		S3Service:           deps.Services.S3Service,
+		S3PermURL:           deps.Config.AWS.S3PermURL,
 	}
 	usersHandler.SetupRouter(versionedGroup)
 
@@ -110,6 +137,8 @@ func SetupRoutes(server *gin.Engine, deps ServerDependency) {
 
 	ratingHandler := ratings.NewRatingRouter(
 		deps.Repositories.RatingRepostory,
+		deps.Repositories.TransactionRepository,
+		deps.Repositories.RatingReportRepository,
 		deps.Services.MiddlewareService,
 	)
 	ratingHandler.SetupRouter(versionedGroup)
@@ -126,13 +155,54 @@ func SetupRoutes(server *gin.Engine, deps ServerDependency) {
 	transactionHandler := transactions.NewTransactionHandler(
 		deps.Repositories.TransactionRepository,
 		deps.Repositories.ProductRepository,
-		deps.Repositories.RatingRepostory,
+		deps.Repositories.UserRepository,
 		deps.Services.MiddlewareService,
 		chatHandler,
+		deps.Services.TrackingProvider,
+		deps.Services.PaymentService,
+		deps.Services.FeeService,
+		deps.Services.InvoiceService,
 	)
 	transactionHandler.SetupRouter(versionedGroup)
 
+	paymentsHandler := payments.NewPaymentsHandler(
+		deps.Services.PaymentService,
+		deps.Services.RandomService,
+		deps.Services.MiddlewareService,
+		chatHandler,
+		deps.Config.DevMode,
+	)
+	paymentsHandler.SetupRouter(versionedGroup)
+
+	disputesHandler := disputes.NewDisputesHandler(
+		deps.Repositories.DisputeRepository,
+		deps.Repositories.TransactionRepository,
+		deps.Services.PaymentService,
+		deps.Services.FeeService,
+		deps.Services.InvoiceService,
+		deps.Services.S3Service,
+		deps.Services.MiddlewareService,
+		chatHandler,
+	)
+	disputesHandler.SetupRouter(versionedGroup)
+
+	reportsHandler := reports.NewReportsHandler(
+		deps.Repositories.PayoutRepository,
+		deps.Services.MiddlewareService,
+	)
+	reportsHandler.SetupRouter(versionedGroup)
+
+	rolesHandler := roles.NewRolesHandler(
+		deps.Repositories.RoleRepository,
+		deps.Services.MiddlewareService,
+		deps.Services.PermissionService,
+		deps.Services.TokenStateService,
+		deps.Services.AuditService,
+	)
+	rolesHandler.SetupRouter(versionedGroup)
+
 	versionedGroup.GET("/health", GetHealth)
+	server.GET("/.well-known/jwks.json", GetJWKS(deps.Services.JWTService))
 
 	// Setup GIN swagger
 	server.GET("/swagger", func(g *gin.Context) {
@@ -10,11 +10,13 @@ func ToProfileDTO(m *models.User) ProfileDTO {
 	}
 
 	return ProfileDTO{
-		ID:            m.ID,
-		Name:          m.Name,
-		Email:         m.Email,
-		AvatarURL:     m.AvatarURL,
-		AverageRating: m.AverageRating,
+		ID:              m.ID,
+		Name:            m.Name,
+		Email:           m.Email,
+		AvatarURL:       m.AvatarURL,
+		PositiveRatings: m.PositiveRatings,
+		NegativeRatings: m.NegativeRatings,
+		Reputation:      m.Reputation(),
 	}
 }
 
@@ -1,15 +1,57 @@
 package transactions
 
-import "luny.dev/cherryauctions/internal/models"
+import (
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/services"
+)
 
 type PostTransactionRequest struct {
 	ProductID uint `json:"product_id" form:"product_id" binding:"gt=0"`
 }
 
 type PutTransactionRequest struct {
-	Status models.TransactionStatus `json:"status" binding:"required,oneof=pending paid delivered completed cancelled"`
+	Status         models.TransactionStatus `json:"status" binding:"required,oneof=pending paid delivered completed cancelled"`
+	Carrier        *string                  `json:"carrier" binding:"required_with=TrackingNumber,omitempty,min=2,max=100"`
+	TrackingNumber *string                  `json:"tracking_number" binding:"required_with=Carrier,omitempty,min=2,max=100"`
+}
+
+// PutShippingRequest submits a shipping address. Leaving the address out confirms
+// the address currently saved on the buyer's profile.
+type PutShippingRequest struct {
+	Address *string `json:"address" form:"address" binding:"omitempty,min=2,max=500"`
+}
+
+type ShippingDTO struct {
+	Address        *string                 `json:"address"`
+	ConfirmedAt    *time.Time              `json:"confirmed_at"`
+	Carrier        *string                 `json:"carrier"`
+	TrackingNumber *string                 `json:"tracking_number"`
+	TrackingStatus services.TrackingStatus `json:"tracking_status"`
 }
 
 type TransactionStatusResponse struct {
-	Status models.TransactionStatus `json:"status"`
+	Status   models.TransactionStatus `json:"status"`
+	Shipping *ShippingDTO             `json:"shipping"`
+}
+
+type PaymentIntentDTO struct {
+	ID          uint                       `json:"id"`
+	Provider    string                     `json:"provider"`
+	Reference   string                     `json:"reference"`
+	Amount      int64                      `json:"amount"`
+	Status      models.PaymentIntentStatus `json:"status"`
+	CheckoutURL string                     `json:"checkout_url"`
+	CreatedAt   time.Time                  `json:"created_at"`
+}
+
+type GetInvoiceQuery struct {
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=pdf html"`
+}
+
+// InvoiceURLResponse links to a private invoice document, for as long as the link works.
+type InvoiceURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresIn int    `json:"expires_in"`
 }
@@ -1,8 +1,10 @@
 package transactions
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"luny.dev/cherryauctions/internal/logging"
@@ -95,6 +97,9 @@ func (h *TransactionHandler) PostTransaction(g *gin.Context) {
 //	@router			/transactions/{id} [get]
 func (h *TransactionHandler) GetTransactionStatus(g *gin.Context) {
 	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
 	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
@@ -110,6 +115,12 @@ func (h *TransactionHandler) GetTransactionStatus(g *gin.Context) {
 	}
 
 	response := TransactionStatusResponse{Status: transaction.TransactionStatus}
+
+	// Shipping details are personal, only the two parties get to see them.
+	if sub.UserID == transaction.SellerID || sub.UserID == transaction.BuyerID {
+		response.Shipping = h.toShippingDTO(g, &transaction)
+	}
+
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
 	g.JSON(http.StatusOK, response)
 }
@@ -117,7 +128,7 @@ func (h *TransactionHandler) GetTransactionStatus(g *gin.Context) {
 // PutTransaction godoc
 //
 //	@summary		Updates a transaction.
-//	@description	Updates a transaction status.
+//	@description	Updates a transaction status. Only admins with the payments.confirm permission can mark a pending transaction as paid, otherwise it's paid through the payment provider.
 //	@tags			transactions
 //	@accept			json
 //	@produce		json
@@ -129,6 +140,7 @@ func (h *TransactionHandler) GetTransactionStatus(g *gin.Context) {
 //	@failure		401		{object}	shared.ErrorResponse				"Unauthorized"
 //	@failure		403		{object}	shared.ErrorResponse				"Only seller can create this transaction"
 //	@failure		404		{object}	shared.ErrorResponse				"Unknown transaction ID"
+//	@failure		409		{object}	shared.ErrorResponse				"Transaction status changed concurrently"
 //	@failure		500		{object}	shared.ErrorResponse				"The server failed to complete the request"
 //	@router			/transactions/{id} [put]
 func (h *TransactionHandler) PutTransaction(g *gin.Context) {
@@ -159,7 +171,8 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 
 	// I want to allow the seller to cancel only if the current status = pending
 	// Pending -> Paid -> Delivered -> Completed
-	// Only seller can update it to paid/delivered
+	// Only admins can update it to paid by hand, buyers pay through the provider
+	// Only seller can update it to delivered
 	// Only buyer can update it to completed (from delivered)
 	// If the transaction is already cancelled, nothing to do.
 	if transaction.TransactionStatus == models.TransactionStatusCancelled {
@@ -174,6 +187,7 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 	isBuyer := sub.UserID == transaction.BuyerID
 
 	// 2. State Machine Logic
+	previousStatus := transaction.TransactionStatus
 	switch body.Status {
 	case models.TransactionStatusCancelled:
 		// Seller can cancel only if Pending
@@ -185,12 +199,12 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 			return
 		}
 	case models.TransactionStatusWinnerPaid:
-		// Only seller can move from Pending -> Paid
-		if isSeller && transaction.TransactionStatus == models.TransactionStatusPending {
+		// Only admins can move from Pending -> Paid, for payments made outside of the platform
+		if h.middlewareService.Can(g, sub, models.PERMISSION_PAYMENTS_CONFIRM) && transaction.TransactionStatus == models.TransactionStatusPending {
 			transaction.TransactionStatus = models.TransactionStatusWinnerPaid
 		} else {
-			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "only seller can mark as paid from pending"})
-			g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "only seller can mark as paid from pending"})
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "only admins can mark as paid from pending"})
+			g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "only admins can mark as paid from pending"})
 			return
 		}
 
@@ -221,14 +235,37 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 	}
 
 	// 3. Save to DB and Notify Chat
-	if _, err := h.transactionRepo.UpdateTransactionStatus(ctx, transaction.ID, transaction.TransactionStatus); err != nil {
+	// Cancelling finalizes the product and gives the buyer a penalty rating in the same transaction.
+	var rows int64
+	switch transaction.TransactionStatus {
+	case models.TransactionStatusCancelled:
+		rows, err = h.transactionRepo.CancelTransactionStatus(ctx, transaction.ID)
+	case models.TransactionStatusDelivered:
+		rows, err = h.transactionRepo.MarkTransactionDelivered(ctx, transaction.ID, body.Carrier, body.TrackingNumber)
+	default:
+		rows, err = h.transactionRepo.UpdateTransactionStatus(ctx, transaction.ID, previousStatus, transaction.TransactionStatus)
+	}
+	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to update"})
 		return
 	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "transaction status changed"})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "transaction status changed"})
+		return
+	}
+
+	// Give back whatever the buyer has already paid through the provider, only once the cancellation
+	// is saved. If this fails, the scheduler catches up later.
+	if transaction.TransactionStatus == models.TransactionStatusCancelled {
+		if err := h.paymentService.RefundTransaction(ctx, transaction.ID); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "failed to refund payment"})
+		}
+	}
 
 	// Mark as finalized
-	if transaction.TransactionStatus == models.TransactionStatusCompleted || transaction.TransactionStatus == models.TransactionStatusCancelled {
+	if transaction.TransactionStatus == models.TransactionStatusCompleted {
 		if _, err := h.productRepo.FinalizeProduct(ctx, transaction.ProductID); err != nil {
 			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
 			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to update finalized status"})
@@ -236,19 +273,12 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 		}
 	}
 
-	// Auto add rating to user
-	if transaction.TransactionStatus == models.TransactionStatusCancelled {
-		rating := models.Rating{
-			ProductID:  transaction.ProductID,
-			ReviewerID: transaction.SellerID,
-			RevieweeID: transaction.BuyerID,
-			Rating:     0,
-			Feedback:   "Did not follow through",
-		}
-		if err := h.ratingRepo.CreateRating(ctx, &rating); err != nil {
-			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
-			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to update rating"})
-			return
+	// Record the platform fees and the seller's payout. If this fails, the scheduler catches up later.
+	if transaction.TransactionStatus == models.TransactionStatusCompleted {
+		if err := h.feeService.SettleTransaction(ctx, transaction.ID); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "message": "failed to settle transaction"})
+		} else {
+			h.invoiceService.QueueInvoice(transaction.ID)
 		}
 	}
 
@@ -256,3 +286,255 @@ func (h *TransactionHandler) PutTransaction(g *gin.Context) {
 	h.chatHandler.SendTransactionChangeNotification(transaction.Product.ChatSession.ID, &transaction)
 	g.JSON(http.StatusOK, shared.IDResponse{ID: uint(id)})
 }
+
+// PutShipping godoc
+//
+//	@summary		Submits or confirms a shipping address.
+//	@description	Lets the buyer submit a shipping address for the transaction, or confirm the one on their profile if no address is given.
+//	@tags			transactions
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int								true	"Transaction ID"
+//	@param			body	body		transactions.PutShippingRequest	true	"Shipping data"
+//	@success		200		{object}	shared.IDResponse				"Successfully saved the shipping address"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body or no address to confirm"
+//	@failure		401		{object}	shared.ErrorResponse			"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse			"Only the buyer can submit an address before delivery"
+//	@failure		404		{object}	shared.ErrorResponse			"Unknown transaction ID"
+//	@failure		409		{object}	shared.ErrorResponse			"The product was shipped in the meantime"
+//	@failure		500		{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/transactions/{id}/shipping [put]
+func (h *TransactionHandler) PutShipping(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PutShippingRequest
+	if err := g.ShouldBind(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	transaction, err := h.transactionRepo.GetTransactionByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+		return
+	}
+
+	if transaction.BuyerID != sub.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "only buyer can submit shipping address"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "only buyer can submit shipping address"})
+		return
+	}
+
+	// The address can only change while the product hasn't been shipped out yet.
+	if transaction.TransactionStatus != models.TransactionStatusPending && transaction.TransactionStatus != models.TransactionStatusWinnerPaid {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "can't change shipping address at this stage"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "can't change shipping address at this stage"})
+		return
+	}
+
+	address := body.Address
+	if address == nil {
+		buyer, err := h.userRepo.GetUserByID(ctx, sub.UserID)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+			return
+		}
+		address = buyer.Address
+	}
+
+	if address == nil || strings.TrimSpace(*address) == "" {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": "no address to confirm"})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "no address to confirm"})
+		return
+	}
+
+	rows, err := h.transactionRepo.UpdateShippingAddress(ctx, transaction.ID, strings.TrimSpace(*address))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to update shipping address"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "transaction status changed"})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "transaction status changed"})
+		return
+	}
+
+	response := shared.IDResponse{ID: transaction.ID}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostPayment godoc
+//
+//	@summary		Starts paying for a transaction.
+//	@description	Creates a payment intent with the payment provider for a pending transaction. If there is one still open, that one is returned instead.
+//	@tags			transactions
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		int								true	"Transaction ID"
+//	@success		201	{object}	transactions.PaymentIntentDTO	"Payment intent to complete at the checkout URL"
+//	@failure		400	{object}	shared.ErrorResponse			"Invalid ID"
+//	@failure		401	{object}	shared.ErrorResponse			"Unauthorized"
+//	@failure		403	{object}	shared.ErrorResponse			"Only the buyer can pay for a pending transaction"
+//	@failure		404	{object}	shared.ErrorResponse			"Unknown transaction ID, or payments are disabled"
+//	@failure		500	{object}	shared.ErrorResponse			"The server failed to complete the request"
+//	@router			/transactions/{id}/payments [post]
+func (h *TransactionHandler) PostPayment(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	transaction, err := h.transactionRepo.GetTransactionByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+		return
+	}
+
+	if transaction.BuyerID != sub.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "only buyer can pay"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "only buyer can pay"})
+		return
+	}
+
+	intent, err := h.paymentService.StartPayment(ctx, &transaction)
+	if errors.Is(err, services.ErrPaymentsDisabled) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrPaymentNotPending) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to start payment"})
+		return
+	}
+
+	response := PaymentIntentDTO{
+		ID:          intent.ID,
+		Provider:    intent.Provider,
+		Reference:   intent.Reference,
+		Amount:      intent.Amount,
+		Status:      intent.Status,
+		CheckoutURL: intent.CheckoutURL,
+		CreatedAt:   intent.CreatedAt,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// GetInvoice godoc
+//
+//	@summary		Links to the invoice of a transaction.
+//	@description	Returns a link to the invoice of a completed transaction as a PDF or HTML document, listing the final price and the platform fees. Invoices are private, so the link expires after a few minutes. Only the buyer and the seller can get it.
+//	@tags			transactions
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int								true	"Transaction ID"
+//	@param			format	query		string							false	"Document format, defaults to pdf"	Enums(pdf, html)
+//	@success		200		{object}	transactions.InvoiceURLResponse	"Link to the invoice"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid ID or format"
+//	@failure		401		{object}	shared.ErrorResponse	"Unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse	"Not a party of the transaction"
+//	@failure		404		{object}	shared.ErrorResponse	"Unknown transaction ID"
+//	@failure		409		{object}	shared.ErrorResponse	"The transaction isn't completed and settled yet"
+//	@failure		500		{object}	shared.ErrorResponse	"The server failed to complete the request"
+//	@router			/transactions/{id}/invoice [get]
+func (h *TransactionHandler) GetInvoice(g *gin.Context) {
+	ctx := g.Request.Context()
+	claims, _ := g.Get("claims")
+	sub := claims.(*services.JWTSubject)
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	query := GetInvoiceQuery{Format: string(services.InvoiceFormatPDF)}
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid format"})
+		return
+	}
+
+	transaction, err := h.transactionRepo.GetTransactionByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown transaction"})
+		return
+	}
+
+	if sub.UserID != transaction.BuyerID && sub.UserID != transaction.SellerID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "not a party of the transaction"})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not a party of the transaction"})
+		return
+	}
+
+	format := services.InvoiceFormat(query.Format)
+	url, validFor, err := h.invoiceService.GetInvoiceURL(ctx, transaction.ID, format)
+	if errors.Is(err, services.ErrInvoiceNotReady) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to get invoice"})
+		return
+	}
+
+	// The URL is a bearer credential, so it stays out of the logs.
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "format": format, "transaction_id": transaction.ID})
+	g.JSON(http.StatusOK, InvoiceURLResponse{URL: url, ExpiresIn: int(validFor.Seconds())})
+}
+
+// toShippingDTO maps the shipping details of a transaction, asking the tracking
+// provider for the parcel status if the seller attached a tracking number.
+func (h *TransactionHandler) toShippingDTO(g *gin.Context, transaction *models.Transaction) *ShippingDTO {
+	dto := &ShippingDTO{
+		Address:        transaction.ShippingAddress,
+		ConfirmedAt:    transaction.ShippingConfirmedAt,
+		Carrier:        transaction.ShippingCarrier,
+		TrackingNumber: transaction.TrackingNumber,
+		TrackingStatus: services.TrackingStatusUnknown,
+	}
+
+	if transaction.ShippingCarrier != nil && transaction.TrackingNumber != nil {
+		status, err := h.trackingProvider.GetTrackingStatus(g.Request.Context(), *transaction.ShippingCarrier, *transaction.TrackingNumber)
+		if err != nil {
+			// Not being able to track shouldn't fail the whole request.
+			logging.LogMessage(g, logging.LOG_WARN, gin.H{"error": err.Error(), "transaction_id": transaction.ID})
+		} else {
+			dto.TrackingStatus = status
+		}
+	}
+
+	return dto
+}
@@ -11,24 +11,36 @@ import (
 type TransactionHandler struct {
 	transactionRepo   *repositories.TransactionRepository
 	productRepo       *repositories.ProductRepository
-	ratingRepo        *repositories.RatingRepostory
+	userRepo          *repositories.UserRepository
 	middlewareService *services.MiddlewareService
 	chatHandler       *chat.ChatHandler
+	trackingProvider  services.TrackingProvider
+	paymentService    *services.PaymentService
+	feeService        *services.FeeService
+	invoiceService    *services.InvoiceService
 }
 
 func NewTransactionHandler(
 	transactionRepo *repositories.TransactionRepository,
 	productRepo *repositories.ProductRepository,
-	ratingRepo *repositories.RatingRepostory,
+	userRepo *repositories.UserRepository,
 	middlewareService *services.MiddlewareService,
 	chatHandler *chat.ChatHandler,
+	trackingProvider services.TrackingProvider,
+	paymentService *services.PaymentService,
+	feeService *services.FeeService,
+	invoiceService *services.InvoiceService,
 ) *TransactionHandler {
 	return &TransactionHandler{
 		transactionRepo:   transactionRepo,
 		productRepo:       productRepo,
-		ratingRepo:        ratingRepo,
+		userRepo:          userRepo,
 		middlewareService: middlewareService,
 		chatHandler:       chatHandler,
+		trackingProvider:  trackingProvider,
+		paymentService:    paymentService,
+		feeService:        feeService,
+		invoiceService:    invoiceService,
 	}
 }
 
@@ -38,4 +50,7 @@ func (h *TransactionHandler) SetupRouter(g *gin.RouterGroup) {
 	r.POST("", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PostTransaction)
 	r.GET("/:id", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.GetTransactionStatus)
 	r.PUT("/:id", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PutTransaction)
+	r.PUT("/:id/shipping", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PutShipping)
+	r.POST("/:id/payments", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.PostPayment)
+	r.GET("/:id/invoice", h.middlewareService.AuthorizedRoute(models.ROLE_USER), h.GetInvoice)
 }
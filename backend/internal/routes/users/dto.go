@@ -15,21 +15,43 @@ type QuestionDTO struct {
 }
 
 type SubscriptionDTO struct {
+	ID        uint      `json:"id"`
+	Plan      string    `json:"plan"`
+	Source    string    `json:"source"`
+	Note      *string   `json:"note"`
+	StartsAt  time.Time `json:"starts_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type SubscriptionPlanDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Days int    `json:"days"`
+}
+
+type PutUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"max=20"`
+}
+
+type PostExtendSubscriptionRequest struct {
+	Days int     `json:"days" binding:"required,gte=1,lte=365"`
+	Note *string `json:"note" binding:"omitempty,max=1000"`
+}
+
 type ProductImageDTO struct {
 	URL     string `json:"url"`
 	AltText string `json:"alt"`
 }
 
 type ProfileDTO struct {
-	ID            uint    `json:"id"`
-	Name          *string `json:"name"`
-	Email         *string `json:"email"`
-	AvatarURL     *string `json:"avatar_url"`
-	AverageRating float64 `json:"average_rating"`
+	ID              uint    `json:"id"`
+	Name            *string `json:"name"`
+	Email           *string `json:"email"`
+	AvatarURL       *string `json:"avatar_url"`
+	PositiveRatings int64   `json:"positive_ratings"`
+	NegativeRatings int64   `json:"negative_ratings"`
+	Reputation      float64 `json:"reputation"`
 }
 
 type CategoryDTO struct {
@@ -105,23 +127,40 @@ type UserDTO struct {
 	AvatarURL       *string          `json:"avatar_url"`
 	Verified        bool             `json:"verified"`
 	CreatedAt       time.Time        `json:"created_at"`
-	AverageRating   float64          `json:"average_rating"`
-	WaitingApproval bool             `json:"waiting_approval"`
+	PositiveRatings int64            `json:"positive_ratings"`
+	NegativeRatings int64            `json:"negative_ratings"`
+	Reputation      float64          `json:"reputation"`
 	Roles           []string         `json:"roles"`
 	Subscription    *SubscriptionDTO `json:"subscription"`
+	// The latest seller request and what your roles allow, only shown for your own profile.
+	SellerRequest *SellerRequestDTO `json:"seller_request"`
+	Permissions   []string          `json:"permissions,omitempty"`
+	// Banned users stay suspended forever.
+	SuspendedUntil   *time.Time `json:"suspended_until"`
+	Banned           bool       `json:"banned"`
+	SuspensionReason *string    `json:"suspension_reason"`
 }
 
 type RatingDTO struct {
-	Rating   uint       `json:"rating"`
-	Feedback string     `json:"feedback"`
-	Reviewer ProfileDTO `json:"reviewer"`
-	Reviewee ProfileDTO `json:"reviewee"`
+	ID             uint       `json:"id"`
+	Rating         int8       `json:"rating"`
+	Feedback       string     `json:"feedback"`
+	FeedbackHidden bool       `json:"feedback_hidden"`
+	Reviewer       ProfileDTO `json:"reviewer"`
+	Reviewee       ProfileDTO `json:"reviewee"`
+	Reply          *string    `json:"reply"`
+	RepliedAt      *time.Time `json:"replied_at"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 type GetUsersQuery struct {
-	Query   string `form:"query" json:"query"`
-	Page    int    `form:"page" binding:"number,gt=0,omitempty" json:"page"`
-	PerPage int    `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
+	Query     string `form:"query" json:"query" binding:"max=200"`
+	Role      string `form:"role" json:"role" binding:"max=50"`
+	Verified  *bool  `form:"verified" json:"verified"`
+	Seller    *bool  `form:"seller" json:"seller"`
+	Suspended *bool  `form:"suspended" json:"suspended"`
+	Page      int    `form:"page" binding:"number,gt=0,omitempty" json:"page"`
+	PerPage   int    `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
 }
 
 type GetUsersResponse struct {
@@ -132,8 +171,44 @@ type GetUsersResponse struct {
 	PerPage    int       `json:"per_page"`
 }
 
-type PostApproveRequest struct {
-	ID int `json:"id" binding:"number,gt=0"`
+type PostSellerRequestRequest struct {
+	Message string `json:"message" binding:"max=1000"`
+	// Defaults to the first plan.
+	Plan string `json:"plan" binding:"max=50"`
+}
+
+type PostApproveSellerRequestRequest struct {
+	Reason *string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+type PostRejectSellerRequestRequest struct {
+	Reason string `json:"reason" binding:"required,min=2,max=1000"`
+}
+
+type GetSellerRequestsQuery struct {
+	Status  string `form:"status" json:"status" binding:"omitempty,oneof=pending approved rejected"`
+	Page    int    `form:"page" binding:"number,gt=0,omitempty" json:"page"`
+	PerPage int    `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
+}
+
+// SellerRequestDTO is a request for seller privileges. The user is only shown to admins.
+type SellerRequestDTO struct {
+	ID         uint        `json:"id"`
+	User       *ProfileDTO `json:"user,omitempty"`
+	Message    string      `json:"message"`
+	Plan       string      `json:"plan"`
+	Status     string      `json:"status"`
+	Reason     *string     `json:"reason"`
+	ReviewedAt *time.Time  `json:"reviewed_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type GetSellerRequestsResponse struct {
+	Data       []SellerRequestDTO `json:"data"`
+	Total      int64              `json:"total"`
+	TotalPages int                `json:"total_pages"`
+	Page       int                `json:"page"`
+	PerPage    int                `json:"per_page"`
 }
 
 type PostAvatarRequest struct {
@@ -161,3 +236,220 @@ type GetRatingsResponse struct {
 	Page       int         `json:"page"`
 	PerPage    int         `json:"per_page"`
 }
+
+type GetMyTransactionsQuery struct {
+	Page    int       `form:"page" binding:"number,gt=0,omitempty" json:"page"`
+	PerPage int       `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
+	Status  string    `form:"status" json:"status" binding:"omitempty,oneof=pending paid delivered completed cancelled disputed"`
+	From    time.Time `form:"from" json:"from" time_format:"2006-01-02"`
+	To      time.Time `form:"to" json:"to" time_format:"2006-01-02"`
+}
+
+type TransactionProductDTO struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+type TransactionRatingDTO struct {
+	Rating   int8   `json:"rating"`
+	Feedback string `json:"feedback"`
+}
+
+// MyTransactionDTO is a transaction from the point of view of one of its parties.
+type MyTransactionDTO struct {
+	ID           uint                  `json:"id"`
+	Product      TransactionProductDTO `json:"product"`
+	Counterparty ProfileDTO            `json:"counterparty"`
+	FinalPrice   int64                 `json:"final_price"`
+	Status       string                `json:"status"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	// The rating I left the counterparty, and the one they left me, if any.
+	MyRating    *TransactionRatingDTO `json:"my_rating"`
+	TheirRating *TransactionRatingDTO `json:"their_rating"`
+}
+
+type GetTransactionsResponse struct {
+	Data        []MyTransactionDTO `json:"data"`
+	Total       int64              `json:"total"`
+	TotalAmount int64              `json:"total_amount"`
+	TotalPages  int                `json:"total_pages"`
+	Page        int                `json:"page"`
+	PerPage     int                `json:"per_page"`
+}
+
+type GetMyPayoutsQuery struct {
+	Page    int       `form:"page" binding:"number,gt=0,omitempty" json:"page"`
+	PerPage int       `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
+	From    time.Time `form:"from" json:"from" time_format:"2006-01-02"`
+	To      time.Time `form:"to" json:"to" time_format:"2006-01-02"`
+}
+
+type FeeLineItemDTO struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	RateBPS     int64  `json:"rate_bps"`
+	Amount      int64  `json:"amount"`
+}
+
+type PayoutDTO struct {
+	ID            uint                  `json:"id"`
+	TransactionID uint                  `json:"transaction_id"`
+	Product       TransactionProductDTO `json:"product"`
+	GrossAmount   int64                 `json:"gross_amount"`
+	FeeAmount     int64                 `json:"fee_amount"`
+	NetAmount     int64                 `json:"net_amount"`
+	Fees          []FeeLineItemDTO      `json:"fees"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// GetPayoutsResponse is a payout statement, totals cover every payout in the date range.
+type GetPayoutsResponse struct {
+	Data        []PayoutDTO `json:"data"`
+	GrossAmount int64       `json:"gross_amount"`
+	FeeAmount   int64       `json:"fee_amount"`
+	NetAmount   int64       `json:"net_amount"`
+	Total       int64       `json:"total"`
+	TotalPages  int         `json:"total_pages"`
+	Page        int         `json:"page"`
+	PerPage     int         `json:"per_page"`
+}
+
+// PublicUserDTO is what anyone can see of another user, without their email.
+type PublicUserDTO struct {
+	ID        uint    `json:"id"`
+	Name      *string `json:"name"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+type PublicRatingDTO struct {
+	ID             uint          `json:"id"`
+	Rating         int8          `json:"rating"`
+	Feedback       string        `json:"feedback"`
+	FeedbackHidden bool          `json:"feedback_hidden"`
+	Reviewer       PublicUserDTO `json:"reviewer"`
+	Reply          *string       `json:"reply"`
+	RepliedAt      *time.Time    `json:"replied_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type PublicListingDTO struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CurrentPrice int64     `json:"current_price"`
+	BINPrice     *int64    `json:"bin_price"`
+	BidsCount    int       `json:"bids_count"`
+	ExpiredAt    time.Time `json:"expired_at"`
+}
+
+// PublicProfileResponse is the public reputation page of a user.
+type PublicProfileResponse struct {
+	ID              uint               `json:"id"`
+	Name            *string            `json:"name"`
+	AvatarURL       *string            `json:"avatar_url"`
+	MemberSince     time.Time          `json:"member_since"`
+	PositiveRatings int64              `json:"positive_ratings"`
+	NegativeRatings int64              `json:"negative_ratings"`
+	Reputation      float64            `json:"reputation"`
+	RecentRatings   []PublicRatingDTO  `json:"recent_ratings"`
+	ActiveListings  []PublicListingDTO `json:"active_listings"`
+	ActiveCount     int64              `json:"active_count"`
+}
+
+// SessionDTO is a device the user is logged in on. The ID stays the same across refreshes.
+type SessionDTO struct {
+	ID         string     `json:"id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	SignedInAt *time.Time `json:"signed_in_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiredAt  time.Time  `json:"expired_at"`
+	Current    bool       `json:"current"`
+}
+
+type GetSessionsResponse struct {
+	Data []SessionDTO `json:"data"`
+}
+
+type PostEmailChangeRequest struct {
+	Email           string `json:"email" binding:"required,email,max=200"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type PostEmailConfirmRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type PostSuspendUserRequest struct {
+	Hours  int    `json:"hours" binding:"required,gte=1,lte=8760"`
+	Reason string `json:"reason" binding:"required,min=2,max=1000"`
+}
+
+type PostBanUserRequest struct {
+	Reason string `json:"reason" binding:"required,min=2,max=1000"`
+}
+
+type PostReinstateUserRequest struct {
+	Reason *string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+type GetAuditLogsQuery struct {
+	UserID  uint   `form:"user_id" json:"user_id"`
+	ActorID uint   `form:"actor_id" json:"actor_id"`
+	Action  string `form:"action" json:"action" binding:"max=50"`
+	Page    int    `form:"page" binding:"number,gt=0,omitempty" json:"page"`
+	PerPage int    `form:"per_page" binding:"number,gt=0,omitempty" json:"per_page"`
+}
+
+type AuditLogDTO struct {
+	ID         uint           `json:"id"`
+	Actor      ProfileDTO     `json:"actor"`
+	Action     string         `json:"action"`
+	TargetUser *ProfileDTO    `json:"target_user"`
+	Reason     *string        `json:"reason"`
+	Details    map[string]any `json:"details"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type GetAuditLogsResponse struct {
+	Data       []AuditLogDTO `json:"data"`
+	Total      int64         `json:"total"`
+	TotalPages int           `json:"total_pages"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"per_page"`
+}
+
+// DossierProductsDTO is the most recent of some products of a user, like their ended listings.
+type DossierProductsDTO struct {
+	State string       `json:"state"`
+	Data  []ProductDTO `json:"data"`
+	Total int64        `json:"total"`
+}
+
+type DossierRatingsDTO struct {
+	Data  []RatingDTO `json:"data"`
+	Total int64       `json:"total"`
+}
+
+type DossierTransactionsDTO struct {
+	Data        []MyTransactionDTO `json:"data"`
+	Total       int64              `json:"total"`
+	TotalAmount int64              `json:"total_amount"`
+}
+
+// UserDossierResponse is everything staff look at before acting on a user, with only the most
+// recent of every list.
+type UserDossierResponse struct {
+	User           UserDTO                `json:"user"`
+	Listings       []DossierProductsDTO   `json:"listings"`
+	Bids           []DossierProductsDTO   `json:"bids"`
+	Ratings        DossierRatingsDTO      `json:"ratings"`
+	Rated          DossierRatingsDTO      `json:"rated"`
+	Purchases      DossierTransactionsDTO `json:"purchases"`
+	Sales          DossierTransactionsDTO `json:"sales"`
+	SellerRequests []SellerRequestDTO     `json:"seller_requests"`
+	Subscriptions  []SubscriptionDTO      `json:"subscriptions"`
+	AuditLogs      []AuditLogDTO          `json:"audit_logs"`
+}
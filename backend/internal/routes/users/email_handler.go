@@ -0,0 +1,125 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// PostEmailChange godoc
+//
+//	@summary		Requests an email change
+//	@description	Sends a confirmation link to the new email, and lets the current one know. The current email stays until the link is used.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		users.PostEmailChangeRequest	true	"New email and current password"
+//	@success		202		{object}	shared.MessageResponse			"Confirmation link was sent"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body, or the email is already yours"
+//	@failure		401		{object}	shared.ErrorResponse			"When unauthorized"
+//	@failure		403		{object}	shared.ErrorResponse			"Incorrect current password"
+//	@failure		409		{object}	shared.ErrorResponse			"Email is already in use"
+//	@failure		421		{object}	shared.ErrorResponse			"Account uses OAuth"
+//	@failure		429		{object}	shared.ErrorResponse			"Too many email change requests"
+//	@failure		500		{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/me/email [POST]
+func (h *UsersHandler) PostEmailChange(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	var body PostEmailChangeRequest
+	err := g.ShouldBindBodyWithJSON(&body)
+	loggingBody := body
+	loggingBody.CurrentPassword = "[REDACTED]"
+
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	err = h.EmailChangeService.RequestChange(ctx, claims.UserID, strings.TrimSpace(body.Email), body.CurrentPassword)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "can't request an email change"
+		switch {
+		case errors.Is(err, services.ErrEmailChangeSame):
+			status, message = http.StatusBadRequest, err.Error()
+		case errors.Is(err, services.ErrEmailChangePassword):
+			status, message = http.StatusForbidden, err.Error()
+		case errors.Is(err, repositories.ErrEmailInUse):
+			status, message = http.StatusConflict, err.Error()
+		case errors.Is(err, services.ErrEmailChangeOAuth):
+			status, message = http.StatusMisdirectedRequest, err.Error()
+		case errors.Is(err, services.ErrEmailChangeRateLimited):
+			status, message = http.StatusTooManyRequests, err.Error()
+		}
+
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": status, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(status, shared.ErrorResponse{Error: message})
+		return
+	}
+
+	response := shared.MessageResponse{Message: "a confirmation link was sent to the new email"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusAccepted, "body": loggingBody, "response": response})
+	g.JSON(http.StatusAccepted, response)
+}
+
+// PostEmailConfirm godoc
+//
+//	@summary		Confirms an email change
+//	@description	Changes the email using the token from a confirmation email. Access tokens are revoked since they carry the old email, so clients should refresh.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@param			body	body		users.PostEmailConfirmRequest	true	"Confirmation token"
+//	@success		200		{object}	shared.MessageResponse			"Email was changed"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body, or invalid or expired token"
+//	@failure		409		{object}	shared.ErrorResponse			"Email was taken in the meantime"
+//	@failure		500		{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/me/email/confirm [POST]
+func (h *UsersHandler) PostEmailConfirm(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	var body PostEmailConfirmRequest
+	err := g.ShouldBindBodyWithJSON(&body)
+	loggingBody := body
+	loggingBody.Token = "[REDACTED]"
+
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	userID, err := h.EmailChangeService.ConfirmChange(ctx, body.Token)
+	if errors.Is(err, repositories.ErrEmailChangeInvalid) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrEmailInUse) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": loggingBody})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't change email"})
+		return
+	}
+
+	h.TokenStateService.InvalidateUser(userID)
+
+	response := shared.MessageResponse{Message: "email was changed"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": userID, "body": loggingBody, "response": response})
+	g.JSON(http.StatusOK, response)
+}
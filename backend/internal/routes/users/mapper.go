@@ -7,6 +7,11 @@ import (
 
 func ToSubscriptionDTO(m models.SellerSubscription) SubscriptionDTO {
 	return SubscriptionDTO{
+		ID:        m.ID,
+		Plan:      m.Plan,
+		Source:    string(m.Source),
+		Note:      m.Note,
+		StartsAt:  m.StartsAt,
 		ExpiredAt: m.ExpiredAt,
 		CreatedAt: m.CreatedAt,
 	}
@@ -27,22 +32,28 @@ func ToUserDTO(m *models.User) UserDTO {
 		AvatarURL:       m.AvatarURL,
 		Verified:        m.Verified,
 		CreatedAt:       m.CreatedAt,
-		AverageRating:   m.AverageRating,
-		WaitingApproval: m.WaitingApproval,
+		PositiveRatings: m.PositiveRatings,
+		NegativeRatings: m.NegativeRatings,
+		Reputation:      m.Reputation(),
 		Roles: ranges.Each(m.Roles, func(r models.Role) string {
 			return r.ID
 		}),
-		Subscription: subscription,
+		Subscription:     subscription,
+		SuspendedUntil:   m.SuspendedUntil,
+		Banned:           m.Banned,
+		SuspensionReason: m.SuspensionReason,
 	}
 }
 
 func ToProfileDTO(m models.User) ProfileDTO {
 	return ProfileDTO{
-		ID:            m.ID,
-		Name:          m.Name,
-		Email:         m.Email,
-		AvatarURL:     m.AvatarURL,
-		AverageRating: m.AverageRating,
+		ID:              m.ID,
+		Name:            m.Name,
+		Email:           m.Email,
+		AvatarURL:       m.AvatarURL,
+		PositiveRatings: m.PositiveRatings,
+		NegativeRatings: m.NegativeRatings,
+		Reputation:      m.Reputation(),
 	}
 }
 
@@ -130,9 +141,179 @@ func ToProductDTO(m *models.Product) ProductDTO {
 
 func ToRatingDTO(m *models.Rating) RatingDTO {
 	return RatingDTO{
+		ID:             m.ID,
+		Rating:         m.Rating,
+		Feedback:       m.VisibleFeedback(),
+		FeedbackHidden: m.FeedbackHiddenAt != nil,
+		Reviewer:       ToProfileDTO(m.Reviewer),
+		Reviewee:       ToProfileDTO(m.Reviewee),
+		Reply:          m.Reply,
+		RepliedAt:      m.RepliedAt,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func ToPublicUserDTO(m models.User) PublicUserDTO {
+	return PublicUserDTO{
+		ID:        m.ID,
+		Name:      m.Name,
+		AvatarURL: m.AvatarURL,
+	}
+}
+
+func ToPublicRatingDTO(m *models.Rating) PublicRatingDTO {
+	return PublicRatingDTO{
+		ID:             m.ID,
+		Rating:         m.Rating,
+		Feedback:       m.VisibleFeedback(),
+		FeedbackHidden: m.FeedbackHiddenAt != nil,
+		Reviewer:       ToPublicUserDTO(m.Reviewer),
+		Reply:          m.Reply,
+		RepliedAt:      m.RepliedAt,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func ToPublicListingDTO(m *models.Product) PublicListingDTO {
+	price := m.StartingBid
+	if m.CurrentHighestBid != nil {
+		price = m.CurrentHighestBid.Price
+	}
+
+	return PublicListingDTO{
+		ID:           m.ID,
+		Name:         m.Name,
+		ThumbnailURL: m.ThumbnailURL,
+		CurrentPrice: price,
+		BINPrice:     m.BINPrice,
+		BidsCount:    m.BidsCount,
+		ExpiredAt:    m.ExpiredAt,
+	}
+}
+
+func ToTransactionRatingDTO(m *models.Rating) *TransactionRatingDTO {
+	if m == nil {
+		return nil
+	}
+
+	return &TransactionRatingDTO{
 		Rating:   m.Rating,
-		Feedback: m.Feedback,
-		Reviewer: ToProfileDTO(m.Reviewer),
-		Reviewee: ToProfileDTO(m.Reviewee),
+		Feedback: m.VisibleFeedback(),
+	}
+}
+
+// ToMyTransactionDTO maps a transaction as seen by userID, picking out the ratings
+// between both parties from the ratings left on the product.
+func ToMyTransactionDTO(m *models.Transaction, userID uint, ratings []models.Rating) MyTransactionDTO {
+	counterparty := m.Seller
+	if m.SellerID == userID {
+		counterparty = m.Buyer
+	}
+
+	var mine, theirs *models.Rating
+	for i := range ratings {
+		rating := &ratings[i]
+		if rating.ProductID != m.ProductID {
+			continue
+		}
+
+		if rating.ReviewerID == userID && rating.RevieweeID == counterparty.ID {
+			mine = rating
+		} else if rating.ReviewerID == counterparty.ID && rating.RevieweeID == userID {
+			theirs = rating
+		}
+	}
+
+	return MyTransactionDTO{
+		ID: m.ID,
+		Product: TransactionProductDTO{
+			ID:           m.Product.ID,
+			Name:         m.Product.Name,
+			ThumbnailURL: m.Product.ThumbnailURL,
+		},
+		Counterparty: ToProfileDTO(counterparty),
+		FinalPrice:   m.FinalPrice,
+		Status:       string(m.TransactionStatus),
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+		MyRating:     ToTransactionRatingDTO(mine),
+		TheirRating:  ToTransactionRatingDTO(theirs),
+	}
+}
+
+func ToFeeLineItemDTO(m models.FeeLineItem) FeeLineItemDTO {
+	return FeeLineItemDTO{
+		Kind:        string(m.Kind),
+		Description: m.Description,
+		RateBPS:     m.RateBPS,
+		Amount:      m.Amount,
+	}
+}
+
+func ToPayoutDTO(m *models.Payout) PayoutDTO {
+	return PayoutDTO{
+		ID:            m.ID,
+		TransactionID: m.TransactionID,
+		Product: TransactionProductDTO{
+			ID:           m.Transaction.Product.ID,
+			Name:         m.Transaction.Product.Name,
+			ThumbnailURL: m.Transaction.Product.ThumbnailURL,
+		},
+		GrossAmount: m.GrossAmount,
+		FeeAmount:   m.FeeAmount,
+		NetAmount:   m.NetAmount,
+		Fees:        ranges.Each(m.Fees, ToFeeLineItemDTO),
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func ToSessionDTO(m *models.RefreshToken, currentFamily string) SessionDTO {
+	return SessionDTO{
+		ID:         m.FamilyID,
+		UserAgent:  m.UserAgent,
+		IPAddress:  m.IPAddress,
+		SignedInAt: m.SignedInAt,
+		LastUsedAt: m.LastUsedAt,
+		ExpiredAt:  m.ExpiredAt,
+		Current:    m.FamilyID == currentFamily,
+	}
+}
+
+func ToSellerRequestDTO(m *models.SellerRequest) SellerRequestDTO {
+	return SellerRequestDTO{
+		ID:         m.ID,
+		Message:    m.Message,
+		Plan:       m.Plan,
+		Status:     string(m.Status),
+		Reason:     m.Reason,
+		ReviewedAt: m.ReviewedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// ToAdminSellerRequestDTO also shows who is asking, the user has to be preloaded.
+func ToAdminSellerRequestDTO(m *models.SellerRequest) SellerRequestDTO {
+	dto := ToSellerRequestDTO(m)
+	user := ToProfileDTO(m.User)
+	dto.User = &user
+	return dto
+}
+
+// ToAuditLogDTO maps an entry of the audit trail, the users have to be preloaded.
+func ToAuditLogDTO(m *models.AuditLog) AuditLogDTO {
+	var target *ProfileDTO
+	if m.TargetUser != nil {
+		dto := ToProfileDTO(*m.TargetUser)
+		target = &dto
+	}
+
+	return AuditLogDTO{
+		ID:         m.ID,
+		Actor:      ToProfileDTO(m.Actor),
+		Action:     string(m.Action),
+		TargetUser: target,
+		Reason:     m.Reason,
+		Details:    m.Details,
+		CreatedAt:  m.CreatedAt,
 	}
 }
@@ -1,12 +1,16 @@
 package users
 
 import (
+	"errors"
 	"math"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"luny.dev/cherryauctions/internal/logging"
 	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
 	"luny.dev/cherryauctions/internal/routes/shared"
 	"luny.dev/cherryauctions/internal/services"
 	"luny.dev/cherryauctions/pkg/ranges"
@@ -220,6 +224,25 @@ func (h *UsersHandler) GetMe(g *gin.Context) {
 	}
 
 	response := ToUserDTO(&user)
+	request, err := h.SellerRequestRepo.GetLatestSellerRequest(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for seller request"})
+		return
+	}
+	if err == nil {
+		dto := ToSellerRequestDTO(&request)
+		response.SellerRequest = &dto
+	}
+
+	// From the roles in the token, so ones waiting on two-factor authentication are left out.
+	response.Permissions, err = h.PermissionService.Permissions(ctx, claims.Roles)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for permissions"})
+		return
+	}
+
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
 	g.JSON(http.StatusOK, response)
 }
@@ -386,3 +409,179 @@ func (h *UsersHandler) GetMyRated(g *gin.Context) {
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "query": query, "response": response})
 	g.JSON(http.StatusOK, response)
 }
+
+// GetMyPurchases godoc
+//
+//	@summary		Gets my purchases.
+//	@description	Retrieves the transactions where I am the buyer, newest first, with the seller and ratings between us.
+//	@tags			users
+//	@security		ApiKeyAuth
+//	@produce		json
+//	@param			status		query		string							false	"Transaction status"	Enums(pending, paid, delivered, completed, cancelled, disputed)
+//	@param			from		query		string							false	"Created on or after this date (YYYY-MM-DD)"
+//	@param			to			query		string							false	"Created on or before this date (YYYY-MM-DD)"
+//	@param			page		query		int								false	"Page number"
+//	@param			per_page	query		int								false	"Items per page"
+//	@success		200			{object}	users.GetTransactionsResponse	"Success"
+//	@failure		400			{object}	shared.ErrorResponse			"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		500			{object}	shared.ErrorResponse			"The server could not complete the request"
+//	@router			/users/me/purchases [GET]
+func (h *UsersHandler) GetMyPurchases(g *gin.Context) {
+	h.getMyTransactions(g, false)
+}
+
+// GetMySales godoc
+//
+//	@summary		Gets my sales.
+//	@description	Retrieves the transactions where I am the seller, newest first, with the buyer and ratings between us.
+//	@tags			users
+//	@security		ApiKeyAuth
+//	@produce		json
+//	@param			status		query		string							false	"Transaction status"	Enums(pending, paid, delivered, completed, cancelled, disputed)
+//	@param			from		query		string							false	"Created on or after this date (YYYY-MM-DD)"
+//	@param			to			query		string							false	"Created on or before this date (YYYY-MM-DD)"
+//	@param			page		query		int								false	"Page number"
+//	@param			per_page	query		int								false	"Items per page"
+//	@success		200			{object}	users.GetTransactionsResponse	"Success"
+//	@failure		400			{object}	shared.ErrorResponse			"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse			"Unauthenticated"
+//	@failure		500			{object}	shared.ErrorResponse			"The server could not complete the request"
+//	@router			/users/me/sales [GET]
+func (h *UsersHandler) GetMySales(g *gin.Context) {
+	h.getMyTransactions(g, true)
+}
+
+func (h *UsersHandler) getMyTransactions(g *gin.Context, asSeller bool) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	query := GetMyTransactionsQuery{
+		Page:    1,
+		PerPage: 20,
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	filter := repositories.TransactionFilter{
+		UserID:   claims.UserID,
+		AsSeller: asSeller,
+		Status:   models.TransactionStatus(query.Status),
+	}
+	if !query.From.IsZero() {
+		filter.From = &query.From
+	}
+	if !query.To.IsZero() {
+		// The end date is inclusive.
+		to := query.To.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	transactions, err := h.TransactionRepo.GetUserTransactions(ctx, filter, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for transactions"})
+		return
+	}
+
+	count, amount, err := h.TransactionRepo.SumUserTransactions(ctx, filter)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count transactions"})
+		return
+	}
+
+	productIDs := ranges.Each(transactions, func(m models.Transaction) uint { return m.ProductID })
+	ratings, err := h.RatingRepo.GetRatingsByProducts(ctx, productIDs)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to read ratings"})
+		return
+	}
+
+	response := GetTransactionsResponse{
+		Data: ranges.EachAddress(transactions, func(m *models.Transaction) MyTransactionDTO {
+			return ToMyTransactionDTO(m, claims.UserID, ratings)
+		}),
+		Total:       count,
+		TotalAmount: amount,
+		TotalPages:  int(math.Ceil(float64(count) / float64(query.PerPage))),
+		Page:        query.Page,
+		PerPage:     query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "query": query, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// GetMyPayouts godoc
+//
+//	@summary		Gets my payout statement.
+//	@description	Retrieves what I was paid out for my completed sales, with the fees taken off each one, newest first.
+//	@tags			users
+//	@security		ApiKeyAuth
+//	@produce		json
+//	@param			from		query		string						false	"Settled on or after this date (YYYY-MM-DD)"
+//	@param			to			query		string						false	"Settled on or before this date (YYYY-MM-DD)"
+//	@param			page		query		int							false	"Page number"
+//	@param			per_page	query		int							false	"Items per page"
+//	@success		200			{object}	users.GetPayoutsResponse	"Success"
+//	@failure		400			{object}	shared.ErrorResponse		"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		500			{object}	shared.ErrorResponse		"The server could not complete the request"
+//	@router			/users/me/payouts [GET]
+func (h *UsersHandler) GetMyPayouts(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	query := GetMyPayoutsQuery{
+		Page:    1,
+		PerPage: 20,
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	var from, to *time.Time
+	if !query.From.IsZero() {
+		from = &query.From
+	}
+	if !query.To.IsZero() {
+		// The end date is inclusive.
+		end := query.To.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	payouts, err := h.PayoutRepo.GetSellerPayouts(ctx, claims.UserID, from, to, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for payouts"})
+		return
+	}
+
+	totals, err := h.PayoutRepo.SumSellerPayouts(ctx, claims.UserID, from, to)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to sum payouts"})
+		return
+	}
+
+	response := GetPayoutsResponse{
+		Data:        ranges.EachAddress(payouts, ToPayoutDTO),
+		GrossAmount: totals.GrossAmount,
+		FeeAmount:   totals.FeeAmount,
+		NetAmount:   totals.NetAmount,
+		Total:       totals.Count,
+		TotalPages:  int(math.Ceil(float64(totals.Count) / float64(query.PerPage))),
+		Page:        query.Page,
+		PerPage:     query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "query": query, "response": response})
+	g.JSON(http.StatusOK, response)
+}
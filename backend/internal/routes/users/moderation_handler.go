@@ -0,0 +1,459 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/ranges"
+)
+
+// How much of every list the dossier of a user shows.
+const dossierItems = 10
+
+// GetUserDossier godoc
+//
+//	@summary		Gets the dossier of a user.
+//	@description	Gets a user with their most recent listings, bids, ratings, purchases, sales, seller requests, subscriptions and what staff did to them.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		int							true	"User ID"
+//	@success		200	{object}	users.UserDossierResponse	"The dossier"
+//	@failure		400	{object}	shared.ErrorResponse		"Invalid ID"
+//	@failure		401	{object}	shared.ErrorResponse		"When unauthenticated"
+//	@failure		403	{object}	shared.ErrorResponse		"Missing the users.read permission"
+//	@failure		404	{object}	shared.ErrorResponse		"Unknown user"
+//	@failure		500	{object}	shared.ErrorResponse		"The request could not be completed due to server faults"
+//	@router			/users/{id}/dossier [GET]
+func (h *UsersHandler) GetUserDossier(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "user_id": id})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": id})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+		return
+	}
+
+	response, err := h.buildDossier(ctx, &user)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "user_id": id})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't put together the dossier"})
+		return
+	}
+
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "user_id": id})
+	g.JSON(http.StatusOK, response)
+}
+
+// buildDossier looks up the most recent of everything a user did.
+func (h *UsersHandler) buildDossier(ctx context.Context, user *models.User) (UserDossierResponse, error) {
+	response := UserDossierResponse{User: ToUserDTO(user)}
+
+	for _, state := range []models.ProductState{models.ProductStateActive, models.ProductStateEnded, models.ProductStateExpired} {
+		products, err := h.ProductRepo.GetUserProducts(ctx, user.ID, state, dossierItems, 0)
+		if err != nil {
+			return response, err
+		}
+		count, err := h.ProductRepo.CountUserProducts(ctx, user.ID, state)
+		if err != nil {
+			return response, err
+		}
+		response.Listings = append(response.Listings, DossierProductsDTO{
+			State: string(state),
+			Data:  ranges.EachAddress(products, ToProductDTO),
+			Total: count,
+		})
+	}
+
+	for _, ended := range []bool{false, true} {
+		products, err := h.ProductRepo.GetMyBids(ctx, user.ID, ended, dossierItems, 0)
+		if err != nil {
+			return response, err
+		}
+		count, err := h.ProductRepo.CountMyBids(ctx, user.ID, ended)
+		if err != nil {
+			return response, err
+		}
+		state := models.ProductStateActive
+		if ended {
+			state = models.ProductStateEnded
+		}
+		response.Bids = append(response.Bids, DossierProductsDTO{
+			State: string(state),
+			Data:  ranges.EachAddress(products, ToProductDTO),
+			Total: count,
+		})
+	}
+
+	ratings, err := h.RatingRepo.GetMyRatings(ctx, user.ID, dossierItems, 0)
+	if err != nil {
+		return response, err
+	}
+	response.Ratings.Data = ranges.EachAddress(ratings, ToRatingDTO)
+	response.Ratings.Total, err = h.RatingRepo.CountMyRatings(ctx, user.ID)
+	if err != nil {
+		return response, err
+	}
+
+	rated, err := h.RatingRepo.GetMyReviewedRatings(ctx, user.ID, dossierItems, 0)
+	if err != nil {
+		return response, err
+	}
+	response.Rated.Data = ranges.EachAddress(rated, ToRatingDTO)
+	response.Rated.Total, err = h.RatingRepo.CountMyReviewedRatings(ctx, user.ID)
+	if err != nil {
+		return response, err
+	}
+
+	response.Purchases, err = h.dossierTransactions(ctx, user.ID, false)
+	if err != nil {
+		return response, err
+	}
+	response.Sales, err = h.dossierTransactions(ctx, user.ID, true)
+	if err != nil {
+		return response, err
+	}
+
+	requests, err := h.SellerRequestRepo.GetUserSellerRequests(ctx, user.ID)
+	if err != nil {
+		return response, err
+	}
+	response.SellerRequests = ranges.EachAddress(requests, ToSellerRequestDTO)
+
+	subscriptions, err := h.SubscriptionRepo.GetUserSubscriptions(ctx, user.ID)
+	if err != nil {
+		return response, err
+	}
+	response.Subscriptions = ranges.Each(subscriptions, ToSubscriptionDTO)
+
+	entries, err := h.AuditLogRepo.GetAuditLogs(ctx, repositories.AuditLogFilter{TargetUserID: user.ID}, dossierItems, 0)
+	if err != nil {
+		return response, err
+	}
+	response.AuditLogs = ranges.EachAddress(entries, ToAuditLogDTO)
+
+	return response, nil
+}
+
+func (h *UsersHandler) dossierTransactions(ctx context.Context, userID uint, asSeller bool) (DossierTransactionsDTO, error) {
+	filter := repositories.TransactionFilter{UserID: userID, AsSeller: asSeller}
+	transactions, err := h.TransactionRepo.GetUserTransactions(ctx, filter, dossierItems, 0)
+	if err != nil {
+		return DossierTransactionsDTO{}, err
+	}
+
+	count, amount, err := h.TransactionRepo.SumUserTransactions(ctx, filter)
+	if err != nil {
+		return DossierTransactionsDTO{}, err
+	}
+
+	productIDs := ranges.Each(transactions, func(m models.Transaction) uint { return m.ProductID })
+	ratings, err := h.RatingRepo.GetRatingsByProducts(ctx, productIDs)
+	if err != nil {
+		return DossierTransactionsDTO{}, err
+	}
+
+	return DossierTransactionsDTO{
+		Data: ranges.EachAddress(transactions, func(m *models.Transaction) MyTransactionDTO {
+			return ToMyTransactionDTO(m, userID, ratings)
+		}),
+		Total:       count,
+		TotalAmount: amount,
+	}, nil
+}
+
+// PostSuspendUser godoc
+//
+//	@summary		Suspends a user.
+//	@description	Signs a user out and keeps them from using their account for a while, with a reason they're shown. Their automatic bids stop. Only users with the roles.write permission can suspend other staff.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int							true	"User ID"
+//	@param			body	body		users.PostSuspendUserRequest	true	"How long and why"
+//	@success		200		{object}	users.UserDTO				"The suspended user"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse		"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse		"Missing the users.ban permission, or roles.write for staff"
+//	@failure		404		{object}	shared.ErrorResponse		"Unknown user"
+//	@failure		409		{object}	shared.ErrorResponse		"Tried to suspend yourself"
+//	@failure		500		{object}	shared.ErrorResponse		"The request could not be completed due to server faults"
+//	@router			/users/{id}/suspend [POST]
+func (h *UsersHandler) PostSuspendUser(g *gin.Context) {
+	var body PostSuspendUserRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "a duration of 1 to 8760 hours and a reason are required"})
+		return
+	}
+
+	until := time.Now().Add(time.Duration(body.Hours) * time.Hour)
+	h.suspendUser(g, &until, body.Reason, body)
+}
+
+// PostBanUser godoc
+//
+//	@summary		Bans a user.
+//	@description	Signs a user out and keeps them from using their account until reinstated, with a reason they're shown. Their automatic bids stop and their bids on running auctions are withdrawn. Only users with the roles.write permission can ban other staff.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int						true	"User ID"
+//	@param			body	body		users.PostBanUserRequest	true	"Why"
+//	@success		200		{object}	users.UserDTO			"The banned user"
+//	@failure		400		{object}	shared.ErrorResponse	"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse	"Missing the users.ban permission, or roles.write for staff"
+//	@failure		404		{object}	shared.ErrorResponse	"Unknown user"
+//	@failure		409		{object}	shared.ErrorResponse	"Tried to ban yourself"
+//	@failure		500		{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
+//	@router			/users/{id}/ban [POST]
+func (h *UsersHandler) PostBanUser(g *gin.Context) {
+	var body PostBanUserRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "a reason is required"})
+		return
+	}
+
+	h.suspendUser(g, nil, body.Reason, body)
+}
+
+// suspendUser suspends the user in the path until a time, or bans them if there's none.
+func (h *UsersHandler) suspendUser(g *gin.Context, until *time.Time, reason string, body any) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	user, ok := h.moderatedUser(g, claims, body)
+	if !ok {
+		return
+	}
+
+	err := h.UserRepo.SuspendUser(ctx, user.ID, until, reason)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't suspend user"})
+		return
+	}
+	h.TokenStateService.InvalidateUser(user.ID)
+
+	entry := models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionUserBan,
+		TargetUserID: &user.ID,
+		Reason:       &reason,
+	}
+	if until != nil {
+		entry.Action = models.AuditActionUserSuspend
+		entry.Details = map[string]any{"until": *until}
+	}
+	h.AuditService.Record(ctx, entry)
+
+	user.SuspendedUntil = until
+	user.Banned = until == nil
+	user.SuspensionReason = &reason
+
+	response := ToUserDTO(&user)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostReinstateUser godoc
+//
+//	@summary		Reinstates a user.
+//	@description	Lifts the suspension or ban of a user, so they can sign in again.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int								true	"User ID"
+//	@param			body	body		users.PostReinstateUserRequest	false	"Why"
+//	@success		200		{object}	users.UserDTO					"The reinstated user"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse			"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse			"Missing the users.ban permission, or roles.write for staff"
+//	@failure		404		{object}	shared.ErrorResponse			"Unknown user"
+//	@failure		409		{object}	shared.ErrorResponse			"The user isn't suspended, or is yourself"
+//	@failure		500		{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/{id}/reinstate [POST]
+func (h *UsersHandler) PostReinstateUser(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	var body PostReinstateUserRequest
+	if g.Request.ContentLength != 0 {
+		if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+			return
+		}
+	}
+
+	user, ok := h.moderatedUser(g, claims, body)
+	if !ok {
+		return
+	}
+
+	rows, err := h.UserRepo.ReinstateUser(ctx, user.ID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't reinstate user"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "user isn't suspended", "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "the user isn't suspended"})
+		return
+	}
+	h.TokenStateService.InvalidateUser(user.ID)
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionUserReinstate,
+		TargetUserID: &user.ID,
+		Reason:       body.Reason,
+	})
+
+	user.SuspendedUntil = nil
+	user.Banned = false
+	user.SuspensionReason = nil
+
+	response := ToUserDTO(&user)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// moderatedUser looks up the user in the path for suspending or reinstating them, which can't be
+// yourself. Staff can only be moderated by whoever can manage roles. Returns false if it responded.
+func (h *UsersHandler) moderatedUser(g *gin.Context, claims *services.JWTSubject, body any) (models.User, bool) {
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return models.User{}, false
+	}
+
+	if uint(id) == claims.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "can't moderate yourself", "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "you can't suspend or reinstate yourself"})
+		return models.User{}, false
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return models.User{}, false
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+		return models.User{}, false
+	}
+
+	roles := strings.Join(ranges.Each(user.Roles, func(r models.Role) string { return r.ID }), " ")
+	permissions, err := h.PermissionService.Permissions(ctx, roles)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't check the roles of the user"})
+		return models.User{}, false
+	}
+	if len(permissions) > 0 && !h.MiddlewareService.Can(g, claims, models.PERMISSION_ROLES_WRITE) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "can't moderate staff", "body": body})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "only users who can manage roles can suspend staff"})
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+// GetAuditLogs godoc
+//
+//	@summary		Gets the audit trail.
+//	@description	Lists what staff did to users and roles, newest first, optionally only to a user, by a user or of a kind.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			user_id		query		int							false	"Only actions on this user"
+//	@param			actor_id	query		int							false	"Only actions by this user"
+//	@param			action		query		string						false	"Only this kind of action, like user.ban"
+//	@param			page		query		int							false	"Page number"
+//	@param			per_page	query		int							false	"Items per page"
+//	@success		200			{object}	users.GetAuditLogsResponse	"The audit trail"
+//	@failure		400			{object}	shared.ErrorResponse		"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse		"When unauthenticated"
+//	@failure		403			{object}	shared.ErrorResponse		"Missing the audit.read permission"
+//	@failure		500			{object}	shared.ErrorResponse		"The request could not be completed due to server faults"
+//	@router			/users/audit [GET]
+func (h *UsersHandler) GetAuditLogs(g *gin.Context) {
+	ctx := g.Request.Context()
+	query := GetAuditLogsQuery{
+		Page:    1,
+		PerPage: 20,
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	filter := repositories.AuditLogFilter{
+		ActorID:      query.ActorID,
+		TargetUserID: query.UserID,
+		Action:       models.AuditAction(query.Action),
+	}
+	entries, err := h.AuditLogRepo.GetAuditLogs(ctx, filter, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for the audit trail"})
+		return
+	}
+
+	count, err := h.AuditLogRepo.CountAuditLogs(ctx, filter)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count the audit trail"})
+		return
+	}
+
+	response := GetAuditLogsResponse{
+		Data:       ranges.EachAddress(entries, ToAuditLogDTO),
+		Total:      count,
+		TotalPages: int(math.Ceil(float64(count) / float64(query.PerPage))),
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "query": query, "total": count})
+	g.JSON(http.StatusOK, response)
+}
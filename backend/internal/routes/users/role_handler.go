@@ -0,0 +1,126 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/ranges"
+)
+
+// PutUserRoles godoc
+//
+//	@summary		Assigns roles to a user.
+//	@description	Replaces the roles of a user, who always keeps the default one. Their access tokens are revoked, so the change takes effect right away. Admins can't change their own roles, or give or take away roles with permissions they don't have.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int							true	"User ID"
+//	@param			body	body		users.PutUserRolesRequest	true	"The roles"
+//	@success		200		{object}	users.UserDTO				"Assigned"
+//	@failure		400		{object}	shared.ErrorResponse		"Invalid body or unknown role"
+//	@failure		401		{object}	shared.ErrorResponse		"Unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse		"Missing the roles.write permission, or a permission of a role being changed"
+//	@failure		404		{object}	shared.ErrorResponse		"Unknown user"
+//	@failure		409		{object}	shared.ErrorResponse		"Tried to change your own roles"
+//	@failure		500		{object}	shared.ErrorResponse		"The server failed to complete the request"
+//	@router			/users/{id}/roles [PUT]
+func (h *UsersHandler) PutUserRoles(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PutUserRolesRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Otherwise admins could lock themselves out of the admin panel.
+	if uint(id) == claims.UserID {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": "can't change own roles", "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: "you can't change your own roles"})
+		return
+	}
+
+	current, err := h.UserRepo.GetUserByID(ctx, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+		return
+	}
+
+	// Roles being given or taken away can't have more than the caller, or roles.write would be
+	// enough to make a second account admin, or to demote real admins.
+	currentRoles := ranges.Each(current.Roles, func(r models.Role) string { return r.ID })
+	newRoles := append([]string{models.ROLE_USER}, body.Roles...)
+	changed := ranges.Filter(currentRoles, func(r string) bool { return !slices.Contains(newRoles, r) })
+	changed = append(changed, ranges.Filter(newRoles, func(r string) bool { return !slices.Contains(currentRoles, r) })...)
+
+	permissions, err := h.PermissionService.Permissions(ctx, strings.Join(changed, " "))
+	var missing string
+	if err == nil {
+		missing, err = h.PermissionService.MissingPermission(ctx, claims.Roles, permissions)
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't check the roles"})
+		return
+	}
+	if missing != "" {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusForbidden, "error": "changing a role with a missing permission", "permission": missing, "body": body})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "you can't give or take away roles with permissions you don't have"})
+		return
+	}
+
+	user, err := h.RoleRepo.SetUserRoles(ctx, uint(id), body.Roles)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return
+	}
+	if errors.Is(err, repositories.ErrUnknownRole) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't assign roles"})
+		return
+	}
+	h.TokenStateService.InvalidateUser(user.ID)
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionUserRoles,
+		TargetUserID: &user.ID,
+		Details:      map[string]any{"roles": body.Roles},
+	})
+
+	response := ToUserDTO(&user)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
@@ -9,14 +9,27 @@ import (
 )
 
 type UsersHandler struct {
-	DB                *gorm.DB
-	MiddlewareService *services.MiddlewareService
-	PasswordService   *services.PasswordService
-	UserRepo          *repositories.UserRepository
-	ProductRepo       *repositories.ProductRepository
-	RatingRepo        *repositories.RatingRepostory
-	S3Service         *services.S3Service
-	S3PermURL         string
+	DB                  *gorm.DB
+	MiddlewareService   *services.MiddlewareService
+	PasswordService     *services.PasswordService
+	TokenStateService   *services.TokenStateService
+	EmailChangeService  *services.EmailChangeService
+	MailerService       *services.MailerService
+	SubscriptionService *services.SubscriptionService
+	PermissionService   *services.PermissionService
+	AuditService        *services.AuditService
+	UserRepo            *repositories.UserRepository
+	ProductRepo         *repositories.ProductRepository
+	RatingRepo          *repositories.RatingRepostory
+	TransactionRepo     *repositories.TransactionRepository
+	PayoutRepo          *repositories.PayoutRepository
+	RefreshTokenRepo    *repositories.RefreshTokenRepository
+	SellerRequestRepo   *repositories.SellerRequestRepository
+	RoleRepo            *repositories.RoleRepository
+	SubscriptionRepo    *repositories.SubscriptionRepository
+	AuditLogRepo        *repositories.AuditLogRepository
+	S3Service           *services.S3Service
+	S3PermURL           string
 }
 
 func (h *UsersHandler) SetupRouter(r *gin.RouterGroup) {
@@ -28,9 +41,31 @@ func (h *UsersHandler) SetupRouter(r *gin.RouterGroup) {
 	g.GET("/me/bids", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMyBids)
 	g.GET("/me/ratings", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMyRatings)
 	g.GET("/me/rated", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMyRated)
+	g.GET("/me/purchases", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMyPurchases)
+	g.GET("/me/sales", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMySales)
+	g.GET("/me/payouts", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMyPayouts)
 	g.PUT("/me/password", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.PutPassword)
-	g.GET("", h.MiddlewareService.AuthorizedRoute(models.ROLE_ADMIN), h.GetUsers)
+	g.POST("/me/email", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.PostEmailChange)
+	g.POST("/me/email/confirm", h.PostEmailConfirm)
+	g.GET("/me/sessions", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMySessions)
+	g.DELETE("/me/sessions", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.DeleteMySessions)
+	g.DELETE("/me/sessions/:id", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.DeleteMySession)
+	g.GET("", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_READ), h.GetUsers)
+	g.GET("/:id/profile", h.GetProfile)
 	g.POST("/request", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.PostRequest)
-	g.POST("/approve", h.MiddlewareService.AuthorizedRoute(models.ROLE_ADMIN), h.PostApprove)
+	g.GET("/me/requests", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMySellerRequests)
+	g.GET("/requests", h.MiddlewareService.RequirePermission(models.PERMISSION_SELLERS_REVIEW), h.GetSellerRequests)
+	g.POST("/requests/:id/approve", h.MiddlewareService.RequirePermission(models.PERMISSION_SELLERS_REVIEW), h.PostApproveSellerRequest)
+	g.POST("/requests/:id/reject", h.MiddlewareService.RequirePermission(models.PERMISSION_SELLERS_REVIEW), h.PostRejectSellerRequest)
+	g.GET("/subscriptions/plans", h.GetSubscriptionPlans)
+	g.GET("/me/subscriptions", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.GetMySubscriptions)
+	g.GET("/:id/subscriptions", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_READ), h.GetUserSubscriptions)
+	g.POST("/:id/subscriptions", h.MiddlewareService.RequirePermission(models.PERMISSION_SUBSCRIPTIONS_WRITE), h.PostExtendSubscription)
+	g.PUT("/:id/roles", h.MiddlewareService.RequirePermission(models.PERMISSION_ROLES_WRITE), h.PutUserRoles)
+	g.GET("/:id/dossier", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_READ), h.GetUserDossier)
+	g.POST("/:id/suspend", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_BAN), h.PostSuspendUser)
+	g.POST("/:id/ban", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_BAN), h.PostBanUser)
+	g.POST("/:id/reinstate", h.MiddlewareService.RequirePermission(models.PERMISSION_USERS_BAN), h.PostReinstateUser)
+	g.GET("/audit", h.MiddlewareService.RequirePermission(models.PERMISSION_AUDIT_READ), h.GetAuditLogs)
 	g.POST("/avatar", h.MiddlewareService.AuthorizedRoute(models.ROLE_USER), h.PostAvatar)
 }
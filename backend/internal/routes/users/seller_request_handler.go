@@ -0,0 +1,322 @@
+package users
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/ranges"
+)
+
+// How old an account has to be before it can ask to sell.
+const sellerRequestMinAccountAge = 7 * 24 * time.Hour
+
+// PostRequest godoc
+//
+//	@summary		Requests seller privileges
+//	@description	Sends a request to the admins to approve or reject seller privileges on a plan, or to renew them. The account has to be at least 7 days old, and can only have one pending request.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			body	body		users.PostSellerRequestRequest	false	"Message to the admins"
+//	@success		201		{object}	users.SellerRequestDTO			"The pending request"
+//	@failure		400		{object}	shared.ErrorResponse			"Invalid body or unknown plan"
+//	@failure		401		{object}	shared.ErrorResponse			"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse			"Account is too new"
+//	@failure		409		{object}	shared.ErrorResponse			"Already has a pending request"
+//	@failure		500		{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/request [POST]
+func (h *UsersHandler) PostRequest(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	var body PostSellerRequestRequest
+	if g.Request.ContentLength != 0 {
+		if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusBadRequest, "body": body})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+			return
+		}
+	}
+
+	plan, ok := h.SubscriptionService.Plan(body.Plan)
+	if !ok {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "unknown plan", "status": http.StatusBadRequest, "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "unknown plan"})
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+		return
+	}
+
+	if time.Since(user.CreatedAt) < sellerRequestMinAccountAge {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "account too new", "status": http.StatusForbidden, "created_at": user.CreatedAt})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "account must be at least 7 days old"})
+		return
+	}
+
+	request := models.SellerRequest{
+		UserID:  user.ID,
+		Message: body.Message,
+		Plan:    plan.ID,
+	}
+	err = h.SellerRequestRepo.CreateSellerRequest(ctx, &request)
+	if errors.Is(err, repositories.ErrSellerRequestPending) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusConflict})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't create seller request"})
+		return
+	}
+
+	response := ToSellerRequestDTO(&request)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
+
+// GetMySellerRequests godoc
+//
+//	@summary		Gets my seller requests
+//	@description	Retrieves every request for seller privileges you made, newest first, with the decisions on them.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{array}		users.SellerRequestDTO	"My requests"
+//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
+//	@router			/users/me/requests [GET]
+func (h *UsersHandler) GetMySellerRequests(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	requests, err := h.SellerRequestRepo.GetUserSellerRequests(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for seller requests"})
+		return
+	}
+
+	response := ranges.EachAddress(requests, ToSellerRequestDTO)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// GetSellerRequests godoc
+//
+//	@summary		Gets seller requests
+//	@description	Retrieves requests for seller privileges with a status, oldest first. Defaults to pending ones.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			status		query		string							false	"Status of the requests"	Enums(pending, approved, rejected)
+//	@param			page		query		int								false	"Page Number"
+//	@param			per_page	query		int								false	"Items per Page"
+//	@success		200			{object}	users.GetSellerRequestsResponse	"The requests"
+//	@failure		400			{object}	shared.ErrorResponse			"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse			"When unauthenticated"
+//	@failure		403			{object}	shared.ErrorResponse			"Missing the sellers.review permission"
+//	@failure		500			{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/requests [GET]
+func (h *UsersHandler) GetSellerRequests(g *gin.Context) {
+	ctx := g.Request.Context()
+	query := GetSellerRequestsQuery{
+		Status:  string(models.SellerRequestStatusPending),
+		Page:    1,
+		PerPage: 20,
+	}
+
+	if err := g.ShouldBindQuery(&query); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid query"})
+		return
+	}
+
+	status := models.SellerRequestStatus(query.Status)
+	requests, err := h.SellerRequestRepo.GetSellerRequests(ctx, status, query.PerPage, (query.Page-1)*query.PerPage)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for seller requests"})
+		return
+	}
+
+	count, err := h.SellerRequestRepo.CountSellerRequests(ctx, status)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "query": query})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count seller requests"})
+		return
+	}
+
+	response := GetSellerRequestsResponse{
+		Data:       ranges.EachAddress(requests, ToAdminSellerRequestDTO),
+		Total:      count,
+		TotalPages: int(math.Ceil(float64(count) / float64(query.PerPage))),
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response, "query": query})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostApproveSellerRequest godoc
+//
+//	@summary		Approves a seller request
+//	@description	Approves a pending request for seller privileges, giving its user a subscription on the requested plan and emailing them. Renewals start when the current subscription expires.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int										true	"Seller request ID"
+//	@param			body	body		users.PostApproveSellerRequestRequest	false	"Optional note to the user"
+//	@success		200		{object}	users.SellerRequestDTO					"The approved request"
+//	@failure		400		{object}	shared.ErrorResponse					"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse					"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse					"Missing the sellers.review permission"
+//	@failure		404		{object}	shared.ErrorResponse					"Unknown seller request"
+//	@failure		409		{object}	shared.ErrorResponse					"Already reviewed"
+//	@failure		500		{object}	shared.ErrorResponse					"The request could not be completed due to server faults"
+//	@router			/users/requests/{id}/approve [POST]
+func (h *UsersHandler) PostApproveSellerRequest(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PostApproveSellerRequestRequest
+	if g.Request.ContentLength != 0 {
+		if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+			g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+			return
+		}
+	}
+
+	request, err := h.SellerRequestRepo.GetSellerRequestByID(ctx, uint(id))
+	if !handleReviewError(g, err, body) {
+		return
+	}
+
+	// Plans can be removed while requests are pending, those get the default one.
+	plan, ok := h.SubscriptionService.Plan(request.Plan)
+	if !ok {
+		plan, _ = h.SubscriptionService.Plan("")
+	}
+
+	request, subscription, err := h.SellerRequestRepo.ApproveSellerRequest(ctx, uint(id), claims.UserID, body.Reason, plan.ID, plan.Duration())
+	if !handleReviewError(g, err, body) {
+		return
+	}
+
+	h.TokenStateService.InvalidateUser(request.UserID)
+	h.MailerService.SendSellerRequestApprovedEmail(&request, subscription.ExpiredAt)
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionSellerRequestApprove,
+		TargetUserID: &request.UserID,
+		Reason:       body.Reason,
+		Details:      map[string]any{"seller_request_id": request.ID, "plan": plan.ID},
+	})
+
+	response := ToSellerRequestDTO(&request)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostRejectSellerRequest godoc
+//
+//	@summary		Rejects a seller request
+//	@description	Rejects a pending request for seller privileges with a reason, and emails its user. They can request again.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int										true	"Seller request ID"
+//	@param			body	body		users.PostRejectSellerRequestRequest	true	"Why it was rejected"
+//	@success		200		{object}	users.SellerRequestDTO					"The rejected request"
+//	@failure		400		{object}	shared.ErrorResponse					"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse					"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse					"Missing the sellers.review permission"
+//	@failure		404		{object}	shared.ErrorResponse					"Unknown seller request"
+//	@failure		409		{object}	shared.ErrorResponse					"Already reviewed"
+//	@failure		500		{object}	shared.ErrorResponse					"The request could not be completed due to server faults"
+//	@router			/users/requests/{id}/reject [POST]
+func (h *UsersHandler) PostRejectSellerRequest(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PostRejectSellerRequestRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "a reason is required"})
+		return
+	}
+
+	request, err := h.SellerRequestRepo.RejectSellerRequest(ctx, uint(id), claims.UserID, body.Reason)
+	if !handleReviewError(g, err, body) {
+		return
+	}
+
+	h.MailerService.SendSellerRequestRejectedEmail(&request)
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionSellerRequestReject,
+		TargetUserID: &request.UserID,
+		Reason:       &body.Reason,
+		Details:      map[string]any{"seller_request_id": request.ID},
+	})
+
+	response := ToSellerRequestDTO(&request)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "body": body, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// handleReviewError responds to an error from reviewing a seller request, returning whether there
+// was none.
+func handleReviewError(g *gin.Context, err error, body any) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown seller request"})
+	case errors.Is(err, repositories.ErrSellerRequestReviewed):
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusConflict, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusConflict, shared.ErrorResponse{Error: err.Error()})
+	default:
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't review seller request"})
+	}
+	return false
+}
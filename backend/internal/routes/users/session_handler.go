@@ -0,0 +1,125 @@
+package users
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// GetMySessions godoc
+//
+//	@summary		Gets where I'm logged in.
+//	@description	Lists the sessions of the current user, most recently used first. The session of the refresh token cookie is marked as current.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{object}	users.GetSessionsResponse	"The sessions"
+//	@failure		401	{object}	shared.ErrorResponse		"When unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse		"The server could not complete the request"
+//	@router			/users/me/sessions [GET]
+func (h *UsersHandler) GetMySessions(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	sessions, err := h.RefreshTokenRepo.GetSessions(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't find sessions"})
+		return
+	}
+
+	// The current session is the one of the refresh token, if the browser sent it.
+	currentFamily := ""
+	if cookie, err := g.Cookie("RefreshToken"); err == nil {
+		if decodedCookie, err := base64.URLEncoding.DecodeString(cookie); err == nil {
+			hashedCookie := sha256.Sum256(decodedCookie)
+			token, err := h.RefreshTokenRepo.GetRefreshToken(ctx, base64.URLEncoding.EncodeToString(hashedCookie[:]))
+			if err == nil && token.UserID == claims.UserID {
+				currentFamily = token.FamilyID
+			}
+		}
+	}
+
+	dtos := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		dtos = append(dtos, ToSessionDTO(&session, currentFamily))
+	}
+
+	response := GetSessionsResponse{Data: dtos}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "sessions": len(dtos)})
+	g.JSON(http.StatusOK, response)
+}
+
+// DeleteMySession godoc
+//
+//	@summary		Logs out of a session.
+//	@description	Revokes the refresh token of one session, along with the access tokens given out for it.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		string					true	"Session ID"
+//	@success		200	{object}	shared.MessageResponse	"Logged out of the session"
+//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		404	{object}	shared.ErrorResponse	"No such session"
+//	@failure		500	{object}	shared.ErrorResponse	"The server could not complete the request"
+//	@router			/users/me/sessions/{id} [DELETE]
+func (h *UsersHandler) DeleteMySession(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	id := g.Param("id")
+	rows, err := h.RefreshTokenRepo.RevokeSession(ctx, claims.UserID, id)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "session": id})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't log out of session"})
+		return
+	}
+	if rows == 0 {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": "no such session", "session": id})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "no such session"})
+		return
+	}
+
+	h.TokenStateService.InvalidateSession(id)
+
+	response := shared.MessageResponse{Message: "logged out of session"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "session": id, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// DeleteMySessions godoc
+//
+//	@summary		Logs out everywhere.
+//	@description	Revokes the refresh tokens and access tokens of every session, including the current one.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{object}	shared.MessageResponse	"Logged out everywhere"
+//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse	"The server could not complete the request"
+//	@router			/users/me/sessions [DELETE]
+func (h *UsersHandler) DeleteMySessions(g *gin.Context) {
+	ctx := g.Request.Context()
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+
+	rows, err := h.RefreshTokenRepo.RevokeUserTokens(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't log out everywhere"})
+		return
+	}
+
+	h.TokenStateService.InvalidateUser(claims.UserID)
+
+	response := shared.MessageResponse{Message: "logged out everywhere"}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "sessions": rows, "response": response})
+	g.JSON(http.StatusOK, response)
+}
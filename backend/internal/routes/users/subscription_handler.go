@@ -0,0 +1,172 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/logging"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/routes/shared"
+	"luny.dev/cherryauctions/internal/services"
+	"luny.dev/cherryauctions/pkg/ranges"
+)
+
+// GetSubscriptionPlans godoc
+//
+//	@summary		Gets the subscription plans
+//	@description	Retrieves the plans seller privileges can be requested or renewed on. The first one is the default.
+//	@tags			users
+//	@produce		json
+//	@success		200	{array}	users.SubscriptionPlanDTO	"The plans"
+//	@router			/users/subscriptions/plans [GET]
+func (h *UsersHandler) GetSubscriptionPlans(g *gin.Context) {
+	response := ranges.Each(h.SubscriptionService.Plans(), func(p services.SubscriptionPlan) SubscriptionPlanDTO {
+		return SubscriptionPlanDTO{ID: p.ID, Name: p.Name, Days: p.Days}
+	})
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// GetMySubscriptions godoc
+//
+//	@summary		Gets my subscriptions
+//	@description	Retrieves every seller subscription you had, latest first. Once they all expire, live auctions keep running until they end, but new ones can't be posted.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@success		200	{array}		users.SubscriptionDTO	"My subscriptions"
+//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
+//	@router			/users/me/subscriptions [GET]
+func (h *UsersHandler) GetMySubscriptions(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	subscriptions, err := h.SubscriptionRepo.GetUserSubscriptions(ctx, claims.UserID)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for subscriptions"})
+		return
+	}
+
+	response := ranges.Each(subscriptions, ToSubscriptionDTO)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// GetUserSubscriptions godoc
+//
+//	@summary		Gets the subscriptions of a user
+//	@description	Retrieves every seller subscription a user had, latest first.
+//	@tags			users
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id	path		int						true	"User ID"
+//	@success		200	{array}		users.SubscriptionDTO	"Their subscriptions"
+//	@failure		400	{object}	shared.ErrorResponse	"Invalid ID"
+//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
+//	@failure		403	{object}	shared.ErrorResponse	"Missing the users.read permission"
+//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
+//	@router			/users/{id}/subscriptions [GET]
+func (h *UsersHandler) GetUserSubscriptions(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	subscriptions, err := h.SubscriptionRepo.GetUserSubscriptions(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for subscriptions"})
+		return
+	}
+
+	response := ranges.Each(subscriptions, ToSubscriptionDTO)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
+
+// PostExtendSubscription godoc
+//
+//	@summary		Extends the subscription of a user
+//	@description	Gives a user seller privileges for a number of days, starting when their current subscription expires or now if they don't have one. The user is emailed.
+//	@tags			users
+//	@accept			json
+//	@produce		json
+//	@security		ApiKeyAuth
+//	@param			id		path		int									true	"User ID"
+//	@param			body	body		users.PostExtendSubscriptionRequest	true	"How long, and an optional note to the user"
+//	@success		201		{object}	users.SubscriptionDTO				"The new subscription"
+//	@failure		400		{object}	shared.ErrorResponse				"Invalid ID or body"
+//	@failure		401		{object}	shared.ErrorResponse				"When unauthenticated"
+//	@failure		403		{object}	shared.ErrorResponse				"Missing the subscriptions.write permission"
+//	@failure		404		{object}	shared.ErrorResponse				"Unknown user"
+//	@failure		500		{object}	shared.ErrorResponse				"The request could not be completed due to server faults"
+//	@router			/users/{id}/subscriptions [POST]
+func (h *UsersHandler) PostExtendSubscription(g *gin.Context) {
+	claimsAny, _ := g.Get("claims")
+	claims := claimsAny.(*services.JWTSubject)
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	var body PostExtendSubscriptionRequest
+	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "failed to query for user"})
+		return
+	}
+
+	subscription := models.SellerSubscription{
+		UserID:      user.ID,
+		Source:      models.SubscriptionSourceExtension,
+		GrantedByID: &claims.UserID,
+		Note:        body.Note,
+	}
+	err = h.SubscriptionRepo.ExtendSubscription(ctx, &subscription, time.Duration(body.Days)*24*time.Hour)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error(), "body": body})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't extend subscription"})
+		return
+	}
+
+	h.TokenStateService.InvalidateUser(user.ID)
+	h.MailerService.SendSubscriptionExtendedEmail(&user, &subscription)
+	h.AuditService.Record(ctx, models.AuditLog{
+		ActorID:      claims.UserID,
+		Action:       models.AuditActionSubscriptionExtend,
+		TargetUserID: &user.ID,
+		Reason:       body.Note,
+		Details:      map[string]any{"days": body.Days, "expired_at": subscription.ExpiredAt},
+	})
+
+	response := ToSubscriptionDTO(subscription)
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusCreated, "body": body, "response": response})
+	g.JSON(http.StatusCreated, response)
+}
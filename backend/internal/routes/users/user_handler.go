@@ -7,84 +7,25 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/davidbyttow/govips/v2/vips"
 	"github.com/gin-gonic/gin"
 	"luny.dev/cherryauctions/internal/logging"
 	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
 	"luny.dev/cherryauctions/internal/routes/shared"
 	"luny.dev/cherryauctions/internal/services"
 	"luny.dev/cherryauctions/pkg/closer"
 	"luny.dev/cherryauctions/pkg/ranges"
 )
 
-// PostRequest godoc
-//
-//	@summary		Requests seller privileges
-//	@description	Sends a request to the admin to approve or deny seller privileges.
-//	@tags			users
-//	@produce		json
-//	@security		ApiKeyAuth
-//	@success		204	{object}	shared.MessageResponse	"When success"
-//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
-//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
-//	@router			/users/request [POST]
-func (h *UsersHandler) PostRequest(g *gin.Context) {
-	claimsAny, _ := g.Get("claims")
-	claims := claimsAny.(*services.JWTSubject)
-	ctx := g.Request.Context()
-
-	rows, err := h.UserRepo.RequestUserApproval(ctx, claims.UserID)
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusInternalServerError})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't mark user as requesting"})
-		return
-	}
-
-	if rows == 0 {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": "no rows are written", "status": http.StatusInternalServerError})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "no rows in db were changed"})
-		return
-	}
-
-	response := shared.MessageResponse{Message: "requested privileges successfully"}
-	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusNoContent, "response": response})
-	g.JSON(http.StatusNoContent, response)
-}
-
-// PostApprove godoc
-//
-//	@summary		Approves seller privileges
-//	@description	Approves seller privileges
-//	@tags			users
-//	@produce		json
-//	@security		ApiKeyAuth
-//	@success		204	{object}	shared.MessageResponse	"When success"
-//	@failure		400	{object}	shared.ErrorResponse	"Invalid request"
-//	@failure		401	{object}	shared.ErrorResponse	"When unauthenticated"
-//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
-//	@router			/users/approve [POST]
-func (h *UsersHandler) PostApprove(g *gin.Context) {
-	ctx := g.Request.Context()
-
-	var body PostApproveRequest
-	if err := g.ShouldBindBodyWithJSON(&body); err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusBadRequest, "body": body})
-		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "bad request"})
-		return
-	}
-
-	err := h.UserRepo.ApproveUser(ctx, uint(body.ID))
-	if err != nil {
-		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "status": http.StatusBadRequest, "body": body})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't add a new subscription"})
-		return
-	}
-
-	response := shared.MessageResponse{Message: "approved successfully"}
-	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusNoContent, "response": response})
-	g.JSON(http.StatusNoContent, response)
-}
+// How much of their history the public profile of a user shows.
+const (
+	publicProfileRatings  = 10
+	publicProfileListings = 12
+)
 
 // PostAvatar godoc
 //
@@ -181,14 +122,23 @@ func (h *UsersHandler) PostAvatar(g *gin.Context) {
 
 // GetUsers godoc
 //
-//	@summary		Retrieves all users
-//	@description	Retrieves all users.
+//	@summary		Searches for users
+//	@description	Searches for users by name or email, optionally only the ones with a role, verified or not, sellers or not and suspended or not.
 //	@tags			users
 //	@produce		json
 //	@security		ApiKeyAuth
-//	@success		200	{object}	users.GetUsersResponse	"All users"
-//	@failure		401	{object}	shared.ErrorResponse	"When unauthorized"
-//	@failure		500	{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
+//	@param			query		query		string					false	"Part of the name or email"
+//	@param			role		query		string					false	"Role ID"
+//	@param			verified	query		bool					false	"Whether the account is verified"
+//	@param			seller		query		bool					false	"Whether the user can sell right now"
+//	@param			suspended	query		bool					false	"Whether the user is suspended or banned"
+//	@param			page		query		int						false	"Page number"
+//	@param			per_page	query		int						false	"Items per page"
+//	@success		200			{object}	users.GetUsersResponse	"Matching users"
+//	@failure		400			{object}	shared.ErrorResponse	"Invalid query"
+//	@failure		401			{object}	shared.ErrorResponse	"When unauthorized"
+//	@failure		403			{object}	shared.ErrorResponse	"Missing the users.read permission"
+//	@failure		500			{object}	shared.ErrorResponse	"The request could not be completed due to server faults"
 //	@router			/users [GET]
 func (h *UsersHandler) GetUsers(g *gin.Context) {
 	ctx := g.Request.Context()
@@ -203,17 +153,24 @@ func (h *UsersHandler) GetUsers(g *gin.Context) {
 		return
 	}
 
-	users, err := h.UserRepo.GetUsers(ctx, query.PerPage, (query.Page-1)*query.PerPage)
+	filter := repositories.UserFilter{
+		Query:     strings.TrimSpace(query.Query),
+		Role:      query.Role,
+		Verified:  query.Verified,
+		Seller:    query.Seller,
+		Suspended: query.Suspended,
+	}
+	users, err := h.UserRepo.GetUsers(ctx, filter, query.PerPage, (query.Page-1)*query.PerPage)
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "query": query})
 		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query the database"})
 		return
 	}
 
-	count, err := h.UserRepo.CountUsers(ctx)
+	count, err := h.UserRepo.CountUsers(ctx, filter)
 	if err != nil {
 		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "query": query})
-		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count products"})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count users"})
 		return
 	}
 
@@ -227,3 +184,69 @@ func (h *UsersHandler) GetUsers(g *gin.Context) {
 	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response, "query": query})
 	g.JSON(http.StatusOK, response)
 }
+
+// GetProfile godoc
+//
+//	@summary		Gets the public profile of a user.
+//	@description	Gets the reputation of a user, their most recent ratings with replies and their active listings. Emails are never shown.
+//	@tags			users
+//	@produce		json
+//	@param			id	path		int								true	"User ID"
+//	@success		200	{object}	users.PublicProfileResponse	"The profile"
+//	@failure		400	{object}	shared.ErrorResponse			"Invalid ID"
+//	@failure		404	{object}	shared.ErrorResponse			"Unknown user"
+//	@failure		500	{object}	shared.ErrorResponse			"The request could not be completed due to server faults"
+//	@router			/users/{id}/profile [GET]
+func (h *UsersHandler) GetProfile(g *gin.Context) {
+	ctx := g.Request.Context()
+
+	id, err := strconv.ParseUint(g.Param("id"), 10, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusBadRequest, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusBadRequest, shared.ErrorResponse{Error: "invalid id"})
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(ctx, uint(id))
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusNotFound, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusNotFound, shared.ErrorResponse{Error: "unknown user"})
+		return
+	}
+
+	ratings, err := h.RatingRepo.GetMyReviewedRatings(ctx, user.ID, publicProfileRatings, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for ratings"})
+		return
+	}
+
+	products, err := h.ProductRepo.GetUserProducts(ctx, user.ID, models.ProductStateActive, publicProfileListings, 0)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "couldn't query for listings"})
+		return
+	}
+
+	count, err := h.ProductRepo.CountUserProducts(ctx, user.ID, models.ProductStateActive)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"status": http.StatusInternalServerError, "error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "unable to count listings"})
+		return
+	}
+
+	response := PublicProfileResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		AvatarURL:       user.AvatarURL,
+		MemberSince:     user.CreatedAt,
+		PositiveRatings: user.PositiveRatings,
+		NegativeRatings: user.NegativeRatings,
+		Reputation:      user.Reputation(),
+		RecentRatings:   ranges.EachAddress(ratings, ToPublicRatingDTO),
+		ActiveListings:  ranges.EachAddress(products, ToPublicListingDTO),
+		ActiveCount:     count,
+	}
+	logging.LogMessage(g, logging.LOG_INFO, gin.H{"status": http.StatusOK, "response": response})
+	g.JSON(http.StatusOK, response)
+}
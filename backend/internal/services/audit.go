@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+// AuditService keeps the trail of what staff did to users and roles.
+type AuditService struct {
+	auditLogRepo *repositories.AuditLogRepository
+}
+
+func NewAuditService(auditLogRepo *repositories.AuditLogRepository) *AuditService {
+	return &AuditService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record saves an entry to the audit trail. The action already happened by then, so failing to
+// record it only warns instead of failing the request.
+func (s *AuditService) Record(ctx context.Context, entry models.AuditLog) {
+	err := s.auditLogRepo.CreateAuditLog(ctx, &entry)
+	if err != nil {
+		log.Printf("warning: unable to record %s by user %d in the audit trail: %v", entry.Action, entry.ActorID, err)
+	}
+}
@@ -3,56 +3,177 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"luny.dev/cherryauctions/pkg/closer"
 )
 
-const googleRecaptchaSite = "https://www.google.com/recaptcha/api/siteverify"
+// Endpoints that ask for a captcha, to look up their rule.
+const (
+	CaptchaRegister = "register"
+)
+
+const (
+	recaptchaSiteVerify = "https://www.google.com/recaptcha/api/siteverify"
+	hcaptchaSiteVerify  = "https://api.hcaptcha.com/siteverify"
+	turnstileSiteVerify = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+var (
+	ErrCaptchaCantVerify      error = errors.New("couldn't verify captcha")
+	ErrCaptchaInvalidResponse error = errors.New("invalid captcha response")
+	ErrCaptchaFailed          error = errors.New("captcha verification failed")
+	ErrCaptchaUnsupported     error = errors.New("unsupported captcha provider")
+	ErrInvalidCaptchaRules    error = errors.New("invalid captcha rules")
+)
+
+// CaptchaRule is what an endpoint expects from a captcha. An empty action accepts any, and a
+// score of 0 doesn't check it. Only providers that report them check them.
+type CaptchaRule struct {
+	Action   string  `json:"action"`
+	MinScore float64 `json:"min_score"`
+}
 
-type RecaptchaResponse struct {
-	Success     bool     `json:"success"`
-	Score       float64  `json:"score"`
-	Action      string   `json:"action"`
-	ChallengeTS string   `json:"challenge_ts"`
-	Hostname    string   `json:"hostname"`
-	ErrorCodes  []string `json:"error-codes"`
+// CaptchaVerifier checks a captcha token from the frontend.
+type CaptchaVerifier interface {
+	Name() string
+	Verify(ctx context.Context, token string, clientIP string, rule CaptchaRule) error
 }
 
+// CaptchaService checks captchas for endpoints, each with its own rule.
 type CaptchaService struct {
-	RecaptchaSecret string
+	verifier CaptchaVerifier
+	rules    map[string]CaptchaRule
 }
 
-var (
-	ErrCaptchaCantVerify      error = errors.New("couldn't verify captcha")
-	ErrCaptchaInvalidResponse error = errors.New("invalid recaptcha response")
-	ErrCaptchaFailed          error = errors.New("recaptcha verification failed")
-)
+func NewCaptchaService(verifier CaptchaVerifier, rules map[string]CaptchaRule) *CaptchaService {
+	return &CaptchaService{
+		verifier: verifier,
+		rules:    rules,
+	}
+}
+
+// Check verifies a captcha token for an endpoint. Endpoints without a rule accept any action and score.
+func (s *CaptchaService) Check(ctx context.Context, endpoint string, token string, clientIP string) error {
+	return s.verifier.Verify(ctx, token, clientIP, s.rules[endpoint])
+}
+
+// ParseCaptchaRules parses the rules of each endpoint from JSON, like {"register": {"action": "submit", "min_score": 0.5}}.
+func ParseCaptchaRules(raw string) (map[string]CaptchaRule, error) {
+	var rules map[string]CaptchaRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCaptchaRules, err)
+	}
+
+	for endpoint, rule := range rules {
+		if rule.MinScore < 0 || rule.MinScore > 1 {
+			return nil, fmt.Errorf("%w: score of %s must be between 0 and 1", ErrInvalidCaptchaRules, endpoint)
+		}
+	}
+	return rules, nil
+}
+
+// NewCaptchaVerifier creates the verifier of a provider: recaptcha, hcaptcha, turnstile, or none.
+// With none, the secret is the only token accepted, or anything if it's empty.
+func NewCaptchaVerifier(provider string, secret string) (CaptchaVerifier, error) {
+	switch provider {
+	case "recaptcha":
+		return &SiteVerifyCaptcha{Provider: provider, Endpoint: recaptchaSiteVerify, Secret: secret, Scores: true, Actions: true}, nil
+	case "hcaptcha":
+		// Scores of hCaptcha are risk, not confidence, and it doesn't have actions.
+		return &SiteVerifyCaptcha{Provider: provider, Endpoint: hcaptchaSiteVerify, Secret: secret}, nil
+	case "turnstile":
+		return &SiteVerifyCaptcha{Provider: provider, Endpoint: turnstileSiteVerify, Secret: secret, Actions: true}, nil
+	case "none":
+		return &FakeCaptchaVerifier{Token: secret}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrCaptchaUnsupported, provider)
+}
 
-func (s *CaptchaService) CheckGrecaptcha(token string, clientIP string) error {
-	// Run a captcha check.
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	Score      *float64 `json:"score"`
+	Action     string   `json:"action"`
+	Hostname   string   `json:"hostname"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// SiteVerifyCaptcha checks tokens with the siteverify API that reCAPTCHA, hCaptcha and Turnstile
+// all share. Scores and actions are only checked for providers that have them.
+type SiteVerifyCaptcha struct {
+	Provider string
+	Endpoint string
+	Secret   string
+	Scores   bool
+	Actions  bool
+
+	// Defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+func (c *SiteVerifyCaptcha) Name() string {
+	return c.Provider
+}
+
+func (c *SiteVerifyCaptcha) Verify(ctx context.Context, token string, clientIP string, rule CaptchaRule) error {
 	form := url.Values{}
-	form.Add("secret", s.RecaptchaSecret)
+	form.Add("secret", c.Secret)
 	form.Add("response", token)
 	form.Add("remoteip", clientIP)
 
-	resp, err := http.PostForm(googleRecaptchaSite, form)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return ErrCaptchaCantVerify
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return ErrCaptchaCantVerify
 	}
 	defer closer.CloseResources(resp.Body)
 
-	var result RecaptchaResponse
+	var result siteVerifyResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return ErrCaptchaInvalidResponse
 	}
 
-	if !result.Success || result.Score < 0.5 || result.Action != "submit" {
-		return ErrCaptchaFailed
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrCaptchaFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	if c.Actions && rule.Action != "" && result.Action != rule.Action {
+		return fmt.Errorf("%w: action is %s", ErrCaptchaFailed, result.Action)
+	}
+	if c.Scores && rule.MinScore > 0 && (result.Score == nil || *result.Score < rule.MinScore) {
+		return fmt.Errorf("%w: score is too low", ErrCaptchaFailed)
 	}
+	return nil
+}
 
+// FakeCaptchaVerifier doesn't talk to any provider, for development and tests. It only accepts
+// its token, or anything if that's empty.
+type FakeCaptchaVerifier struct {
+	Token string
+}
+
+func (c *FakeCaptchaVerifier) Name() string {
+	return "none"
+}
+
+func (c *FakeCaptchaVerifier) Verify(ctx context.Context, token string, clientIP string, rule CaptchaRule) error {
+	if c.Token != "" && token != c.Token {
+		return ErrCaptchaFailed
+	}
 	return nil
 }
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// newFakeSiteVerify answers siteverify requests with a response depending on the token.
+func newFakeSiteVerify(t *testing.T, responses map[string]map[string]any) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "127.0.0.1", r.PostForm.Get("remoteip"))
+
+		response, ok := responses[r.PostForm.Get("response")]
+		if !ok {
+			response = map[string]any{"success": false, "error-codes": []string{"invalid-input-response"}}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCaptchaVerifiers(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSiteVerify(t, map[string]map[string]any{
+		"human": {"success": true, "score": 0.9, "action": "submit"},
+		"bot":   {"success": true, "score": 0.1, "action": "submit"},
+		"login": {"success": true, "score": 0.9, "action": "login"},
+		"plain": {"success": true},
+	})
+	rule := services.CaptchaRule{Action: "submit", MinScore: 0.5}
+
+	t.Run("Recaptcha", func(t *testing.T) {
+		verifier, err := services.NewCaptchaVerifier("recaptcha", "secret")
+		assert.Nil(t, err)
+		verifier.(*services.SiteVerifyCaptcha).Endpoint = server.URL
+
+		assert.Nil(t, verifier.Verify(ctx, "human", "127.0.0.1", rule))
+		assert.ErrorIs(t, verifier.Verify(ctx, "bot", "127.0.0.1", rule), services.ErrCaptchaFailed)
+		assert.ErrorIs(t, verifier.Verify(ctx, "login", "127.0.0.1", rule), services.ErrCaptchaFailed)
+		assert.ErrorIs(t, verifier.Verify(ctx, "plain", "127.0.0.1", rule), services.ErrCaptchaFailed)
+		assert.ErrorIs(t, verifier.Verify(ctx, "forged", "127.0.0.1", rule), services.ErrCaptchaFailed)
+
+		// Without a rule, any action and score is fine.
+		assert.Nil(t, verifier.Verify(ctx, "bot", "127.0.0.1", services.CaptchaRule{}))
+	})
+
+	t.Run("HCaptcha", func(t *testing.T) {
+		verifier, err := services.NewCaptchaVerifier("hcaptcha", "secret")
+		assert.Nil(t, err)
+		verifier.(*services.SiteVerifyCaptcha).Endpoint = server.URL
+
+		assert.Nil(t, verifier.Verify(ctx, "plain", "127.0.0.1", rule))
+		assert.ErrorIs(t, verifier.Verify(ctx, "forged", "127.0.0.1", rule), services.ErrCaptchaFailed)
+	})
+
+	t.Run("Turnstile", func(t *testing.T) {
+		verifier, err := services.NewCaptchaVerifier("turnstile", "secret")
+		assert.Nil(t, err)
+		verifier.(*services.SiteVerifyCaptcha).Endpoint = server.URL
+
+		assert.Nil(t, verifier.Verify(ctx, "bot", "127.0.0.1", rule))
+		assert.ErrorIs(t, verifier.Verify(ctx, "login", "127.0.0.1", rule), services.ErrCaptchaFailed)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		verifier := &services.SiteVerifyCaptcha{Provider: "recaptcha", Endpoint: "http://127.0.0.1:1", Secret: "secret"}
+		assert.ErrorIs(t, verifier.Verify(ctx, "human", "127.0.0.1", rule), services.ErrCaptchaCantVerify)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		verifier, err := services.NewCaptchaVerifier("none", "")
+		assert.Nil(t, err)
+		assert.Nil(t, verifier.Verify(ctx, "anything", "127.0.0.1", rule))
+
+		verifier, err = services.NewCaptchaVerifier("none", "pass")
+		assert.Nil(t, err)
+		assert.Nil(t, verifier.Verify(ctx, "pass", "127.0.0.1", rule))
+		assert.ErrorIs(t, verifier.Verify(ctx, "fail", "127.0.0.1", rule), services.ErrCaptchaFailed)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := services.NewCaptchaVerifier("friendly", "secret")
+		assert.ErrorIs(t, err, services.ErrCaptchaUnsupported)
+	})
+}
+
+func TestCaptchaService(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeSiteVerify(t, map[string]map[string]any{
+		"register": {"success": true, "score": 0.6, "action": "register"},
+	})
+	verifier := &services.SiteVerifyCaptcha{Provider: "recaptcha", Endpoint: server.URL, Secret: "secret", Scores: true, Actions: true}
+
+	rules, err := services.ParseCaptchaRules(`{"register": {"action": "register", "min_score": 0.5}, "strict": {"min_score": 0.7}}`)
+	assert.Nil(t, err)
+	service := services.NewCaptchaService(verifier, rules)
+
+	assert.Nil(t, service.Check(ctx, services.CaptchaRegister, "register", "127.0.0.1"))
+	assert.ErrorIs(t, service.Check(ctx, "strict", "register", "127.0.0.1"), services.ErrCaptchaFailed)
+	assert.Nil(t, service.Check(ctx, "unruled", "register", "127.0.0.1"))
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		_, err := services.ParseCaptchaRules(`{"register": {"min_score": 2}}`)
+		assert.ErrorIs(t, err, services.ErrInvalidCaptchaRules)
+
+		_, err = services.ParseCaptchaRules(`not json`)
+		assert.ErrorIs(t, err, services.ErrInvalidCaptchaRules)
+	})
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"luny.dev/cherryauctions/internal/config"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+// TransactionDeadlines are how long each party has to act on a transaction.
+type TransactionDeadlines struct {
+	// How long the winner has to pay, counting from the transaction creation.
+	Payment time.Duration
+	// How long the buyer has to raise an issue after delivery.
+	Delivery time.Duration
+	// How long before each deadline the reminder goes out.
+	Reminder time.Duration
+}
+
+func NewTransactionDeadlines(cfg *config.Config) TransactionDeadlines {
+	day := 24 * time.Hour
+	return TransactionDeadlines{
+		Payment:  time.Duration(cfg.Deadlines.PaymentDays) * day,
+		Delivery: time.Duration(cfg.Deadlines.DeliveryDays) * day,
+		Reminder: time.Duration(cfg.Deadlines.ReminderDays) * day,
+	}
+}
+
+// PaymentDeadline returns when a pending transaction gets cancelled.
+func (d TransactionDeadlines) PaymentDeadline(transaction *models.Transaction) time.Time {
+	return transaction.CreatedAt.Add(d.Payment)
+}
+
+// DeliveryDeadline returns when a delivered transaction gets completed.
+// Transactions delivered before the delivery time was tracked count from their last update.
+func (d TransactionDeadlines) DeliveryDeadline(transaction *models.Transaction) time.Time {
+	deliveredAt := transaction.UpdatedAt
+	if transaction.DeliveredAt != nil {
+		deliveredAt = *transaction.DeliveredAt
+	}
+	return deliveredAt.Add(d.Delivery)
+}
+
+// DeadlineService enforces the transaction deadlines, meant to be run by the scheduler.
+type DeadlineService struct {
+	deadlines       TransactionDeadlines
+	transactionRepo *repositories.TransactionRepository
+	mailerService   *MailerService
+	feeService      *FeeService
+}
+
+func NewDeadlineService(
+	deadlines TransactionDeadlines,
+	transactionRepo *repositories.TransactionRepository,
+	mailerService *MailerService,
+	feeService *FeeService,
+) *DeadlineService {
+	return &DeadlineService{
+		deadlines:       deadlines,
+		transactionRepo: transactionRepo,
+		mailerService:   mailerService,
+		feeService:      feeService,
+	}
+}
+
+// EnforceDeadlines sends out reminders for upcoming deadlines, cancels unpaid
+// transactions and completes delivered ones that went past them.
+func (s *DeadlineService) EnforceDeadlines(ctx context.Context) {
+	now := time.Now()
+
+	s.cancelUnpaid(ctx, now)
+	s.completeDelivered(ctx, now)
+	s.remindUnpaid(ctx, now)
+	s.remindDelivered(ctx, now)
+}
+
+func (s *DeadlineService) cancelUnpaid(ctx context.Context, now time.Time) {
+	transactions, err := s.transactionRepo.GetPendingTransactionsCreatedBefore(ctx, now.Add(-s.deadlines.Payment), false)
+	if err != nil {
+		log.Printf("warning: unable to get unpaid transactions: %v", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		if _, err := s.transactionRepo.CancelTransactionStatus(ctx, transaction.ID); err != nil {
+			log.Printf("warning: unable to cancel unpaid transaction %d: %v", transaction.ID, err)
+		}
+	}
+}
+
+func (s *DeadlineService) completeDelivered(ctx context.Context, now time.Time) {
+	transactions, err := s.transactionRepo.GetDeliveredTransactionsBefore(ctx, now.Add(-s.deadlines.Delivery), false)
+	if err != nil {
+		log.Printf("warning: unable to get delivered transactions: %v", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		rows, err := s.transactionRepo.CompleteDeliveredTransaction(ctx, transaction.ID)
+		if err != nil {
+			log.Printf("warning: unable to complete delivered transaction %d: %v", transaction.ID, err)
+			continue
+		}
+
+		if rows > 0 {
+			if err := s.feeService.SettleTransaction(ctx, transaction.ID); err != nil {
+				log.Printf("warning: unable to settle transaction %d: %v", transaction.ID, err)
+			}
+		}
+	}
+}
+
+func (s *DeadlineService) remindUnpaid(ctx context.Context, now time.Time) {
+	transactions, err := s.transactionRepo.GetPendingTransactionsCreatedBefore(ctx, now.Add(s.deadlines.Reminder-s.deadlines.Payment), true)
+	if err != nil {
+		log.Printf("warning: unable to get unpaid transactions to remind: %v", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		if err := s.mailerService.SendPaymentReminderEmail(&transaction, s.deadlines.PaymentDeadline(&transaction)); err != nil {
+			log.Printf("failed to send payment reminder email: %v", err)
+			continue
+		}
+
+		if _, err := s.transactionRepo.SetPaymentReminderSent(ctx, transaction.ID); err != nil {
+			log.Printf("failed to mark payment reminder as sent: %v", err)
+		}
+	}
+}
+
+func (s *DeadlineService) remindDelivered(ctx context.Context, now time.Time) {
+	transactions, err := s.transactionRepo.GetDeliveredTransactionsBefore(ctx, now.Add(s.deadlines.Reminder-s.deadlines.Delivery), true)
+	if err != nil {
+		log.Printf("warning: unable to get delivered transactions to remind: %v", err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		if err := s.mailerService.SendDeliveryReminderEmail(&transaction, s.deadlines.DeliveryDeadline(&transaction)); err != nil {
+			log.Printf("failed to send delivery reminder email: %v", err)
+			continue
+		}
+
+		if _, err := s.transactionRepo.SetDeliveryReminderSent(ctx, transaction.ID); err != nil {
+			log.Printf("failed to mark delivery reminder as sent: %v", err)
+		}
+	}
+}
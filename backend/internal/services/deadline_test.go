@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/config"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestTransactionDeadlines(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Deadlines.PaymentDays = 3
+	cfg.Deadlines.DeliveryDays = 7
+	cfg.Deadlines.ReminderDays = 1
+	deadlines := services.NewTransactionDeadlines(cfg)
+
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(48 * time.Hour)
+
+	t.Run("FromConfig", func(t *testing.T) {
+		assert.Equal(t, 72*time.Hour, deadlines.Payment)
+		assert.Equal(t, 168*time.Hour, deadlines.Delivery)
+		assert.Equal(t, 24*time.Hour, deadlines.Reminder)
+	})
+
+	t.Run("PaymentDeadline", func(t *testing.T) {
+		transaction := &models.Transaction{}
+		transaction.CreatedAt = createdAt
+		assert.Equal(t, createdAt.Add(72*time.Hour), deadlines.PaymentDeadline(transaction))
+	})
+
+	t.Run("DeliveryDeadline", func(t *testing.T) {
+		deliveredAt := createdAt.Add(24 * time.Hour)
+		transaction := &models.Transaction{DeliveredAt: &deliveredAt}
+		transaction.UpdatedAt = updatedAt
+		assert.Equal(t, deliveredAt.Add(168*time.Hour), deadlines.DeliveryDeadline(transaction))
+	})
+
+	t.Run("DeliveryDeadlineUntracked", func(t *testing.T) {
+		transaction := &models.Transaction{}
+		transaction.UpdatedAt = updatedAt
+		assert.Equal(t, updatedAt.Add(168*time.Hour), deadlines.DeliveryDeadline(transaction))
+	})
+}
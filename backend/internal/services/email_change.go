@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+const (
+	emailChangeExpiry = 30 * time.Minute
+	emailChangeWindow = 24 * time.Hour
+
+	// How many email changes a user can request within the window.
+	maxEmailChanges = 3
+)
+
+var (
+	ErrEmailChangeRateLimited = errors.New("too many email change requests")
+	ErrEmailChangeSame        = errors.New("that's already your email")
+	ErrEmailChangeOAuth       = errors.New("account uses oauth, its email can't be changed")
+	ErrEmailChangePassword    = errors.New("incorrect current password")
+)
+
+type EmailChangeService struct {
+	mailer          *MailerService
+	random          *RandomService
+	password        *PasswordService
+	emailChangeRepo *repositories.EmailChangeRepository
+	userRepo        *repositories.UserRepository
+}
+
+func NewEmailChangeService(
+	mailer *MailerService,
+	random *RandomService,
+	password *PasswordService,
+	emailChangeRepo *repositories.EmailChangeRepository,
+	userRepo *repositories.UserRepository,
+) *EmailChangeService {
+	return &EmailChangeService{
+		mailer:          mailer,
+		random:          random,
+		password:        password,
+		emailChangeRepo: emailChangeRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// RequestChange sends a confirmation link to the new email, and lets the old one know. The user
+// has to give their current password, and the new email can't belong to anyone yet.
+func (s *EmailChangeService) RequestChange(ctx context.Context, userID uint, newEmail string, currentPassword string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == nil || user.OauthType != "none" {
+		return ErrEmailChangeOAuth
+	}
+
+	ok, err := s.password.VerifyPassword(*user.Password, currentPassword)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrEmailChangePassword
+	}
+
+	if user.Email != nil && strings.EqualFold(*user.Email, newEmail) {
+		return ErrEmailChangeSame
+	}
+	if _, err := s.userRepo.GetUserByEmail(ctx, newEmail); err == nil {
+		return repositories.ErrEmailInUse
+	}
+
+	count, err := s.emailChangeRepo.CountEmailChanges(ctx, userID, time.Now().Add(-emailChangeWindow))
+	if err != nil {
+		return err
+	}
+	if count >= maxEmailChanges {
+		return ErrEmailChangeRateLimited
+	}
+
+	token, err := s.random.GenerateSecretKey(32)
+	if err != nil {
+		return err
+	}
+	encodedToken := base64.URLEncoding.EncodeToString(token)
+	tokenHash, _ := HashResetToken(encodedToken)
+
+	err = s.emailChangeRepo.CreateEmailChange(ctx, &models.EmailChange{
+		UserID:    userID,
+		NewEmail:  newEmail,
+		TokenHash: tokenHash,
+		ExpiredAt: time.Now().Add(emailChangeExpiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mailer.SendEmailChangeConfirmEmail(&user, newEmail, encodedToken, emailChangeExpiry)
+	s.mailer.SendEmailChangeNoticeEmail(&user, newEmail, false)
+	return nil
+}
+
+// ConfirmChange changes the email of a user with the emailed token, returning who it was. The old
+// email is told about it.
+func (s *EmailChangeService) ConfirmChange(ctx context.Context, token string) (uint, error) {
+	tokenHash, err := HashResetToken(token)
+	if err != nil {
+		return 0, repositories.ErrEmailChangeInvalid
+	}
+
+	change, err := s.emailChangeRepo.ConfirmEmailChange(ctx, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mailer.SendEmailChangeNoticeEmail(&change.User, change.NewEmail, true)
+	return change.UserID, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+var ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+
+// FeeTier is a bracket of the final price charged at its own rate, like tax brackets.
+type FeeTier struct {
+	// Upper bound of the bracket in cents, 0 for no bound. The last tier has to be unbounded,
+	// so every cent of the price falls into one.
+	UpTo    int64 `json:"up_to"`
+	RateBPS int64 `json:"rate_bps"`
+}
+
+// FeeSchedule decides how much the platform takes from a sale. Rates are in basis points,
+// so 500 means 5%.
+//
+// The commission is, in order of priority, a category rate if the product is in one of
+// them (the lowest one wins), the tiers if there are any, or the flat rate. The fixed fee
+// is charged on top, and the total never exceeds the final price.
+type FeeSchedule struct {
+	RateBPS    int64          `json:"rate_bps"`
+	Tiers      []FeeTier      `json:"tiers"`
+	Categories map[uint]int64 `json:"categories"`
+	FixedFee   int64          `json:"fixed_fee"`
+}
+
+// FeeLine is a single fee charged on a sale.
+type FeeLine struct {
+	Kind        models.FeeKind
+	Description string
+	RateBPS     int64
+	Amount      int64
+}
+
+// ParseFeeSchedule parses a fee schedule from JSON and checks that it makes sense.
+func ParseFeeSchedule(raw string) (FeeSchedule, error) {
+	var schedule FeeSchedule
+	if err := json.Unmarshal([]byte(raw), &schedule); err != nil {
+		return schedule, fmt.Errorf("%w: %v", ErrInvalidFeeSchedule, err)
+	}
+
+	if !validRate(schedule.RateBPS) || schedule.FixedFee < 0 {
+		return schedule, ErrInvalidFeeSchedule
+	}
+
+	var lastBound int64
+	for i, tier := range schedule.Tiers {
+		if !validRate(tier.RateBPS) {
+			return schedule, ErrInvalidFeeSchedule
+		}
+
+		last := i == len(schedule.Tiers)-1
+		if tier.UpTo == 0 && !last {
+			return schedule, fmt.Errorf("%w: only the last tier can be unbounded", ErrInvalidFeeSchedule)
+		}
+		if tier.UpTo != 0 && last {
+			return schedule, fmt.Errorf("%w: the last tier must be unbounded", ErrInvalidFeeSchedule)
+		}
+		if tier.UpTo != 0 && tier.UpTo <= lastBound {
+			return schedule, fmt.Errorf("%w: tiers must be in increasing order", ErrInvalidFeeSchedule)
+		}
+		lastBound = tier.UpTo
+	}
+
+	for _, rate := range schedule.Categories {
+		if !validRate(rate) {
+			return schedule, ErrInvalidFeeSchedule
+		}
+	}
+
+	return schedule, nil
+}
+
+func validRate(bps int64) bool {
+	return bps >= 0 && bps <= 10000
+}
+
+// applyRate takes a rate off an amount, rounding half up.
+func applyRate(amount int64, bps int64) int64 {
+	return (amount*bps + 5000) / 10000
+}
+
+// Compute returns the fees charged on a sale of a product in certain categories.
+func (s FeeSchedule) Compute(price int64, categoryIDs []uint) []FeeLine {
+	var lines []FeeLine
+
+	categoryRate := int64(-1)
+	for _, id := range categoryIDs {
+		if rate, ok := s.Categories[id]; ok && (categoryRate < 0 || rate < categoryRate) {
+			categoryRate = rate
+		}
+	}
+
+	switch {
+	case categoryRate >= 0:
+		lines = append(lines, FeeLine{
+			Kind:        models.FeeKindCommission,
+			Description: "Category commission",
+			RateBPS:     categoryRate,
+			Amount:      applyRate(price, categoryRate),
+		})
+	case len(s.Tiers) > 0:
+		var lower int64
+		for _, tier := range s.Tiers {
+			upper := tier.UpTo
+			if upper == 0 || upper > price {
+				upper = price
+			}
+			if upper <= lower {
+				break
+			}
+
+			lines = append(lines, FeeLine{
+				Kind:        models.FeeKindCommission,
+				Description: fmt.Sprintf("Commission on $%.2f to $%.2f", float64(lower)/100, float64(upper)/100),
+				RateBPS:     tier.RateBPS,
+				Amount:      applyRate(upper-lower, tier.RateBPS),
+			})
+			lower = upper
+		}
+	default:
+		lines = append(lines, FeeLine{
+			Kind:        models.FeeKindCommission,
+			Description: "Commission",
+			RateBPS:     s.RateBPS,
+			Amount:      applyRate(price, s.RateBPS),
+		})
+	}
+
+	if s.FixedFee > 0 {
+		lines = append(lines, FeeLine{
+			Kind:        models.FeeKindFixed,
+			Description: "Transaction fee",
+			Amount:      s.FixedFee,
+		})
+	}
+
+	// Never take more than the sale itself.
+	remaining := price
+	for i := range lines {
+		lines[i].Amount = min(lines[i].Amount, remaining)
+		remaining -= lines[i].Amount
+	}
+
+	return lines
+}
+
+// FeeService settles completed transactions, recording the platform's fees and the seller's payout.
+type FeeService struct {
+	schedule   FeeSchedule
+	payoutRepo *repositories.PayoutRepository
+}
+
+func NewFeeService(
+	schedule FeeSchedule,
+	payoutRepo *repositories.PayoutRepository,
+) *FeeService {
+	return &FeeService{
+		schedule:   schedule,
+		payoutRepo: payoutRepo,
+	}
+}
+
+// SettleTransaction records the fees and payout of a completed transaction.
+// Settling the same transaction twice does nothing.
+func (s *FeeService) SettleTransaction(ctx context.Context, transactionID uint) error {
+	transaction, err := s.payoutRepo.GetTransactionForSettlement(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	if transaction.TransactionStatus != models.TransactionStatusCompleted {
+		return fmt.Errorf("transaction %d is not completed", transactionID)
+	}
+
+	var categoryIDs []uint
+	for _, category := range transaction.Product.Categories {
+		categoryIDs = append(categoryIDs, category.ID)
+		if category.ParentID != nil {
+			categoryIDs = append(categoryIDs, *category.ParentID)
+		}
+	}
+
+	lines := s.schedule.Compute(transaction.FinalPrice, categoryIDs)
+	payout := models.Payout{
+		TransactionID: transaction.ID,
+		SellerID:      transaction.SellerID,
+		GrossAmount:   transaction.FinalPrice,
+	}
+	for _, line := range lines {
+		payout.FeeAmount += line.Amount
+		payout.Fees = append(payout.Fees, models.FeeLineItem{
+			Kind:        line.Kind,
+			Description: line.Description,
+			RateBPS:     line.RateBPS,
+			Amount:      line.Amount,
+		})
+	}
+	payout.NetAmount = payout.GrossAmount - payout.FeeAmount
+
+	return s.payoutRepo.CreatePayout(ctx, &payout)
+}
+
+// SettleCompletedTransactions settles every completed transaction that hasn't been yet.
+func (s *FeeService) SettleCompletedTransactions(ctx context.Context) {
+	ids, err := s.payoutRepo.GetUnsettledTransactionIDs(ctx)
+	if err != nil {
+		log.Printf("warning: unable to get unsettled transactions: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.SettleTransaction(ctx, id); err != nil {
+			log.Printf("warning: unable to settle transaction %d: %v", id, err)
+		}
+	}
+}
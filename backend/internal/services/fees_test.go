@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func sumFees(lines []services.FeeLine) int64 {
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
+
+func TestParseFeeSchedule(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		schedule, err := services.ParseFeeSchedule(`{"rate_bps":500,"tiers":[{"up_to":10000,"rate_bps":1000},{"rate_bps":500}],"categories":{"3":200},"fixed_fee":50}`)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 500, schedule.RateBPS)
+		assert.Len(t, schedule.Tiers, 2)
+		assert.EqualValues(t, 200, schedule.Categories[3])
+		assert.EqualValues(t, 50, schedule.FixedFee)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := services.ParseFeeSchedule(`{`)
+		assert.ErrorIs(t, err, services.ErrInvalidFeeSchedule)
+	})
+
+	t.Run("RateOutOfRange", func(t *testing.T) {
+		_, err := services.ParseFeeSchedule(`{"rate_bps":10001}`)
+		assert.ErrorIs(t, err, services.ErrInvalidFeeSchedule)
+	})
+
+	t.Run("UnboundedTierNotLast", func(t *testing.T) {
+		_, err := services.ParseFeeSchedule(`{"tiers":[{"rate_bps":1000},{"up_to":10000,"rate_bps":500}]}`)
+		assert.ErrorIs(t, err, services.ErrInvalidFeeSchedule)
+	})
+
+	t.Run("TiersOutOfOrder", func(t *testing.T) {
+		_, err := services.ParseFeeSchedule(`{"tiers":[{"up_to":10000,"rate_bps":1000},{"up_to":5000,"rate_bps":500},{"rate_bps":200}]}`)
+		assert.ErrorIs(t, err, services.ErrInvalidFeeSchedule)
+	})
+
+	t.Run("LastTierBounded", func(t *testing.T) {
+		_, err := services.ParseFeeSchedule(`{"tiers":[{"up_to":10000,"rate_bps":1000},{"up_to":50000,"rate_bps":500}]}`)
+		assert.ErrorIs(t, err, services.ErrInvalidFeeSchedule)
+	})
+}
+
+func TestFeeScheduleCompute(t *testing.T) {
+	t.Run("Flat", func(t *testing.T) {
+		schedule := services.FeeSchedule{RateBPS: 500}
+		lines := schedule.Compute(10000, nil)
+		assert.Len(t, lines, 1)
+		assert.Equal(t, models.FeeKindCommission, lines[0].Kind)
+		assert.EqualValues(t, 500, lines[0].Amount)
+	})
+
+	t.Run("RoundsHalfUp", func(t *testing.T) {
+		schedule := services.FeeSchedule{RateBPS: 250}
+		assert.EqualValues(t, 3, sumFees(schedule.Compute(101, nil)))
+	})
+
+	t.Run("Tiered", func(t *testing.T) {
+		schedule := services.FeeSchedule{Tiers: []services.FeeTier{
+			{UpTo: 10000, RateBPS: 1000},
+			{UpTo: 50000, RateBPS: 500},
+			{RateBPS: 200},
+		}}
+
+		lines := schedule.Compute(60000, nil)
+		assert.Len(t, lines, 3)
+		assert.EqualValues(t, 1000, lines[0].Amount)
+		assert.EqualValues(t, 2000, lines[1].Amount)
+		assert.EqualValues(t, 200, lines[2].Amount)
+
+		lines = schedule.Compute(5000, nil)
+		assert.Len(t, lines, 1)
+		assert.EqualValues(t, 500, lines[0].Amount)
+	})
+
+	t.Run("CategoryOverrides", func(t *testing.T) {
+		schedule := services.FeeSchedule{
+			RateBPS:    500,
+			Tiers:      []services.FeeTier{{RateBPS: 1000}},
+			Categories: map[uint]int64{3: 300, 4: 100},
+		}
+
+		lines := schedule.Compute(10000, []uint{1, 3, 4})
+		assert.Len(t, lines, 1)
+		assert.EqualValues(t, 100, lines[0].RateBPS)
+		assert.EqualValues(t, 100, lines[0].Amount)
+
+		assert.EqualValues(t, 1000, sumFees(schedule.Compute(10000, []uint{1})))
+	})
+
+	t.Run("FixedFee", func(t *testing.T) {
+		schedule := services.FeeSchedule{RateBPS: 500, FixedFee: 30}
+		lines := schedule.Compute(10000, nil)
+		assert.Len(t, lines, 2)
+		assert.Equal(t, models.FeeKindFixed, lines[1].Kind)
+		assert.EqualValues(t, 530, sumFees(lines))
+	})
+
+	t.Run("NeverExceedsPrice", func(t *testing.T) {
+		schedule := services.FeeSchedule{RateBPS: 5000, FixedFee: 1000}
+		assert.EqualValues(t, 1200, sumFees(schedule.Compute(1200, nil)))
+	})
+}
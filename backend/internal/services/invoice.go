@@ -0,0 +1,393 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+	"luny.dev/cherryauctions/pkg/pdf"
+)
+
+type InvoiceFormat string
+
+const (
+	InvoiceFormatPDF  InvoiceFormat = "pdf"
+	InvoiceFormatHTML InvoiceFormat = "html"
+)
+
+var ErrInvoiceNotReady = errors.New("transaction hasn't been settled yet")
+
+// Invoices are private, links to them only work for a few minutes.
+const invoiceURLValidFor = 5 * time.Minute
+
+// Invoice is the receipt of a completed transaction, listing the sale and the fees the
+// platform took from it.
+type Invoice struct {
+	Number      string
+	IssuedAt    time.Time
+	Transaction models.Transaction
+	Payout      models.Payout
+}
+
+func NewInvoice(transaction models.Transaction, payout models.Payout) Invoice {
+	return Invoice{
+		Number:      fmt.Sprintf("INV-%06d", transaction.ID),
+		IssuedAt:    payout.CreatedAt,
+		Transaction: transaction,
+		Payout:      payout,
+	}
+}
+
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	dollars := fmt.Sprintf("%d", cents/100)
+	for i := len(dollars) - 3; i > 0; i -= 3 {
+		dollars = dollars[:i] + "," + dollars[i:]
+	}
+	return fmt.Sprintf("%s$%s.%02d", sign, dollars, cents%100)
+}
+
+func partyName(user models.User) string {
+	if user.Name != nil && *user.Name != "" {
+		return *user.Name
+	}
+	return fmt.Sprintf("User #%d", user.ID)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": formatCents,
+	"name":  partyName,
+	"deref": deref,
+	"percent": func(bps int64) string {
+		return fmt.Sprintf("%.2f%%", float64(bps)/100)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+  <title>Invoice {{.Number}}</title>
+</head>
+<body style="font-family: sans-serif; max-width: 720px; margin: auto;">
+  <h1>CherryAuctions</h1>
+  <h2>Invoice {{.Number}}</h2>
+  <p>Issued on {{.IssuedAt.Format "January 2, 2006"}}</p>
+
+  <table style="width: 100%; margin-bottom: 24px;">
+    <tr>
+      <td style="vertical-align: top;">
+        <strong>Seller</strong><br />
+        {{name .Transaction.Seller}}<br />
+        {{deref .Transaction.Seller.Email}}
+      </td>
+      <td style="vertical-align: top;">
+        <strong>Buyer</strong><br />
+        {{name .Transaction.Buyer}}<br />
+        {{deref .Transaction.Buyer.Email}}<br />
+        <span style="white-space: pre-wrap;">{{deref .Transaction.ShippingAddress}}</span>
+      </td>
+    </tr>
+  </table>
+
+  <table style="width: 100%; border-collapse: collapse;">
+    <tr style="border-bottom: 1px solid #ccc;">
+      <th style="text-align: left;">Item</th>
+      <th style="text-align: right;">Amount</th>
+    </tr>
+    <tr>
+      <td>{{.Transaction.Product.Name}} (#{{.Transaction.ProductID}})</td>
+      <td style="text-align: right;">{{money .Payout.GrossAmount}}</td>
+    </tr>
+    <tr style="border-top: 1px solid #ccc;">
+      <td><strong>Total paid by the buyer</strong></td>
+      <td style="text-align: right;"><strong>{{money .Payout.GrossAmount}}</strong></td>
+    </tr>
+  </table>
+
+  <h3>Platform fees</h3>
+  <table style="width: 100%; border-collapse: collapse;">
+    {{range .Payout.Fees}}
+    <tr>
+      <td>{{.Description}}{{if .RateBPS}} ({{percent .RateBPS}}){{end}}</td>
+      <td style="text-align: right;">-{{money .Amount}}</td>
+    </tr>
+    {{end}}
+    <tr style="border-top: 1px solid #ccc;">
+      <td><strong>Paid out to the seller</strong></td>
+      <td style="text-align: right;"><strong>{{money .Payout.NetAmount}}</strong></td>
+    </tr>
+  </table>
+
+  <p style="color: #666; font-size: 12px;">
+    Transaction #{{.Transaction.ID}}. This document was generated automatically.
+  </p>
+</body>
+</html>
+`))
+
+// HTML renders the invoice as a standalone HTML page.
+func (i Invoice) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, i); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF renders the invoice as a single A4 page, or more if the fees don't fit.
+func (i Invoice) PDF() []byte {
+	const (
+		left  = 50.0
+		right = pdf.A4Width - 50
+		mid   = pdf.A4Width / 2
+	)
+
+	doc := pdf.New()
+	y := 70.0
+	next := func(step float64) {
+		y += step
+		if y > pdf.A4Height-60 {
+			doc.AddPage()
+			y = 70
+		}
+	}
+
+	doc.Text(left, y, pdf.HelveticaBold, 20, "CherryAuctions")
+	doc.TextRight(right, y, pdf.HelveticaBold, 14, "Invoice "+i.Number)
+	next(18)
+	doc.TextRight(right, y, pdf.Helvetica, 10, "Issued on "+i.IssuedAt.Format("January 2, 2006"))
+	next(36)
+
+	// Parties, side by side.
+	doc.Text(left, y, pdf.HelveticaBold, 11, "Seller")
+	doc.Text(mid, y, pdf.HelveticaBold, 11, "Buyer")
+	next(16)
+	doc.Text(left, y, pdf.Helvetica, 10, partyName(i.Transaction.Seller))
+	doc.Text(mid, y, pdf.Helvetica, 10, partyName(i.Transaction.Buyer))
+	next(14)
+	doc.Text(left, y, pdf.Helvetica, 10, deref(i.Transaction.Seller.Email))
+	doc.Text(mid, y, pdf.Helvetica, 10, deref(i.Transaction.Buyer.Email))
+	for _, line := range pdf.Wrap(deref(i.Transaction.ShippingAddress), 10, right-mid) {
+		next(14)
+		doc.Text(mid, y, pdf.Helvetica, 10, line)
+	}
+	next(36)
+
+	row := func(font pdf.Font, label string, amount string) {
+		lines := pdf.Wrap(label, 10, right-left-120)
+		for j, line := range lines {
+			doc.Text(left, y, font, 10, line)
+			if j == 0 {
+				doc.TextRight(right, y, font, 10, amount)
+			}
+			if j < len(lines)-1 {
+				next(14)
+			}
+		}
+		next(18)
+	}
+
+	doc.Text(left, y, pdf.HelveticaBold, 10, "Item")
+	doc.TextRight(right, y, pdf.HelveticaBold, 10, "Amount")
+	next(8)
+	doc.Line(left, y, right, y)
+	next(16)
+	row(pdf.Helvetica, fmt.Sprintf("%s (#%d)", i.Transaction.Product.Name, i.Transaction.ProductID), formatCents(i.Payout.GrossAmount))
+	doc.Line(left, y-12, right, y-12)
+	next(4)
+	row(pdf.HelveticaBold, "Total paid by the buyer", formatCents(i.Payout.GrossAmount))
+	next(24)
+
+	doc.Text(left, y, pdf.HelveticaBold, 12, "Platform fees")
+	next(20)
+	for _, fee := range i.Payout.Fees {
+		label := fee.Description
+		if fee.RateBPS != 0 {
+			label = fmt.Sprintf("%s (%.2f%%)", label, float64(fee.RateBPS)/100)
+		}
+		row(pdf.Helvetica, label, "-"+formatCents(fee.Amount))
+	}
+	doc.Line(left, y-12, right, y-12)
+	next(4)
+	row(pdf.HelveticaBold, "Paid out to the seller", formatCents(i.Payout.NetAmount))
+
+	doc.Text(left, pdf.A4Height-40, pdf.Helvetica, 8, fmt.Sprintf("Transaction #%d. This document was generated automatically.", i.Transaction.ID))
+	return doc.Bytes()
+}
+
+// InvoiceService issues invoices for completed transactions, keeps them in S3 and sends them
+// to both parties.
+type InvoiceService struct {
+	s3Service       *S3Service
+	mailerService   *MailerService
+	randomService   *RandomService
+	transactionRepo *repositories.TransactionRepository
+	payoutRepo      *repositories.PayoutRepository
+}
+
+func NewInvoiceService(
+	s3Service *S3Service,
+	mailerService *MailerService,
+	randomService *RandomService,
+	transactionRepo *repositories.TransactionRepository,
+	payoutRepo *repositories.PayoutRepository,
+) *InvoiceService {
+	return &InvoiceService{
+		s3Service:       s3Service,
+		mailerService:   mailerService,
+		randomService:   randomService,
+		transactionRepo: transactionRepo,
+		payoutRepo:      payoutRepo,
+	}
+}
+
+func (s *InvoiceService) buildInvoice(ctx context.Context, transaction models.Transaction) (Invoice, error) {
+	payout, err := s.payoutRepo.GetPayoutByTransactionID(ctx, transaction.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Invoice{}, ErrInvoiceNotReady
+	}
+	if err != nil {
+		return Invoice{}, err
+	}
+	return NewInvoice(transaction, payout), nil
+}
+
+// issueInvoice renders the invoice of a settled transaction and stores both formats as private
+// objects, unless it's already stored. The key still has a random part to not be guessable.
+func (s *InvoiceService) issueInvoice(ctx context.Context, transaction *models.Transaction) (htmlDoc []byte, pdfDoc []byte, err error) {
+	invoice, err := s.buildInvoice(ctx, *transaction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	htmlDoc, err = invoice.HTML()
+	if err != nil {
+		return nil, nil, err
+	}
+	pdfDoc = invoice.PDF()
+
+	if transaction.InvoiceKey != nil {
+		return htmlDoc, pdfDoc, nil
+	}
+
+	secret, err := s.randomService.GenerateSecretKey(16)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := fmt.Sprintf("invoices/%s-%s", invoice.Number, hex.EncodeToString(secret))
+
+	if err := s.s3Service.PutPrivateObject(ctx, key+".html", bytes.NewReader(htmlDoc), "text/html; charset=utf-8"); err != nil {
+		return nil, nil, err
+	}
+	if err := s.s3Service.PutPrivateObject(ctx, key+".pdf", bytes.NewReader(pdfDoc), "application/pdf"); err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.transactionRepo.SetInvoiceKey(ctx, transaction.ID, key); err != nil {
+		return nil, nil, err
+	}
+
+	transaction.InvoiceKey = &key
+	return htmlDoc, pdfDoc, nil
+}
+
+// GetInvoiceURL returns a short-lived link to the invoice of a transaction in a format, issuing it
+// first if needed, along with how long the link works for.
+func (s *InvoiceService) GetInvoiceURL(ctx context.Context, transactionID uint, format InvoiceFormat) (string, time.Duration, error) {
+	transaction, err := s.transactionRepo.GetTransactionForInvoice(ctx, transactionID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if transaction.TransactionStatus != models.TransactionStatusCompleted {
+		return "", 0, ErrInvoiceNotReady
+	}
+
+	if transaction.InvoiceKey == nil {
+		if _, _, err := s.issueInvoice(ctx, &transaction); err != nil {
+			return "", 0, err
+		}
+	}
+
+	key := fmt.Sprintf("%s.%s", *transaction.InvoiceKey, format)
+	filename := fmt.Sprintf("invoice-%06d.%s", transaction.ID, format)
+	url, err := s.s3Service.PresignGetObject(ctx, key, filename, invoiceURLValidFor)
+	if err != nil {
+		return "", 0, err
+	}
+	return url, invoiceURLValidFor, nil
+}
+
+// SendInvoice issues the invoice of a settled transaction and emails it to both parties.
+func (s *InvoiceService) SendInvoice(ctx context.Context, transactionID uint) error {
+	transaction, err := s.transactionRepo.GetTransactionForInvoice(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	if transaction.TransactionStatus != models.TransactionStatusCompleted {
+		return ErrInvoiceNotReady
+	}
+	if transaction.InvoiceSentAt != nil {
+		return nil
+	}
+
+	_, pdfDoc, err := s.issueInvoice(ctx, &transaction)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("invoice-%06d.pdf", transaction.ID)
+	if err := s.mailerService.SendTransactionCompletedEmail(&transaction, filename, pdfDoc); err != nil {
+		return err
+	}
+
+	_, err = s.transactionRepo.SetInvoiceSent(ctx, transaction.ID)
+	return err
+}
+
+// QueueInvoice sends the invoice of a transaction in the background. Failures are picked up
+// by SendPendingInvoices later.
+func (s *InvoiceService) QueueInvoice(transactionID uint) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.SendInvoice(ctx, transactionID); err != nil {
+			log.Printf("warning: unable to send invoice of transaction %d: %v", transactionID, err)
+		}
+	}()
+}
+
+// SendPendingInvoices sends the invoices of every settled transaction that hasn't gotten one.
+func (s *InvoiceService) SendPendingInvoices(ctx context.Context) {
+	ids, err := s.transactionRepo.GetUninvoicedTransactionIDs(ctx)
+	if err != nil {
+		log.Printf("warning: unable to get uninvoiced transactions: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.SendInvoice(ctx, id); err != nil {
+			log.Printf("warning: unable to send invoice of transaction %d: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestInvoice(t *testing.T) {
+	buyerName, sellerName := "Nguyệt Ánh", "Cherry <Shop>"
+	buyerEmail, address := "buyer@example.com", "12 Lê Lợi\nHồ Chí Minh"
+	issuedAt := time.Date(2025, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	transaction := models.Transaction{
+		Model:           gorm.Model{ID: 42},
+		ProductID:       7,
+		Product:         models.Product{Name: "Vintage (Camera)"},
+		Buyer:           models.User{Name: &buyerName, Email: &buyerEmail},
+		Seller:          models.User{Name: &sellerName},
+		FinalPrice:      123456,
+		ShippingAddress: &address,
+	}
+	payout := models.Payout{
+		Model:       gorm.Model{CreatedAt: issuedAt},
+		GrossAmount: 123456,
+		FeeAmount:   6223,
+		NetAmount:   117233,
+		Fees: []models.FeeLineItem{
+			{Kind: models.FeeKindCommission, Description: "Commission", RateBPS: 500, Amount: 6173},
+			{Kind: models.FeeKindFixed, Description: "Transaction fee", Amount: 50},
+		},
+	}
+
+	invoice := services.NewInvoice(transaction, payout)
+	assert.Equal(t, "INV-000042", invoice.Number)
+	assert.Equal(t, issuedAt, invoice.IssuedAt)
+
+	t.Run("HTML", func(t *testing.T) {
+		html, err := invoice.HTML()
+		assert.Nil(t, err)
+
+		out := string(html)
+		assert.Contains(t, out, "Invoice INV-000042")
+		assert.Contains(t, out, "March 4, 2025")
+		assert.Contains(t, out, "Nguyệt Ánh")
+		assert.Contains(t, out, "Cherry &lt;Shop&gt;")
+		assert.Contains(t, out, "$1,234.56")
+		assert.Contains(t, out, "Commission (5.00%)")
+		assert.Contains(t, out, "-$61.73")
+		assert.Contains(t, out, "$1,172.33")
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		out := invoice.PDF()
+		assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+		assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+		assert.Contains(t, string(out), "/Count 1")
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,6 +12,15 @@ type JWTService struct {
 	JWTDomain    string
 	JWTAudience  string
 	JWTSecretKey string
+
+	// Asymmetric keys, the first one signs. Tokens are signed with HS256 and JWTSecretKey
+	// without any, which is kept for compatibility.
+	JWTKeys []*JWTKey
+}
+
+// JWKSResponse is the JSON Web Key Set of the keys access tokens can be verified with.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
 }
 
 type JWTSubject struct {
@@ -20,11 +30,25 @@ type JWTSubject struct {
 	Roles                 string     `json:"roles"`
 	SubscriptionExpiredAt *time.Time `json:"subscription_expired_at"`
 	Verified              bool       `json:"verified"`
+
+	// The session the token was given out for, and the token version of the user at the time.
+	// Either changing makes the token invalid before it expires.
+	SessionID    string `json:"sid"`
+	TokenVersion int64  `json:"ver"`
 	jwt.RegisteredClaims
 }
 
 // SignJWT signs a JWT based on the environment variables and returns a signed string.
-func (s *JWTService) SignJWT(id uint, name string, email string, roles string, subscription *time.Time, verified bool) (string, error) {
+func (s *JWTService) SignJWT(
+	id uint,
+	name string,
+	email string,
+	roles string,
+	subscription *time.Time,
+	verified bool,
+	sessionID string,
+	tokenVersion int64,
+) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTSubject{
 		id,
 		name,
@@ -32,6 +56,8 @@ func (s *JWTService) SignJWT(id uint, name string, email string, roles string, s
 		roles,
 		subscription,
 		verified,
+		sessionID,
+		tokenVersion,
 		jwt.RegisteredClaims{
 			Issuer:    s.JWTDomain,
 			Audience:  jwt.ClaimStrings{s.JWTAudience},
@@ -40,21 +66,31 @@ func (s *JWTService) SignJWT(id uint, name string, email string, roles string, s
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.JWTExpiry) * time.Second)),
 		},
 	})
-	str, err := token.SignedString([]byte(s.JWTSecretKey))
-	return str, err
+	if len(s.JWTKeys) == 0 {
+		return token.SignedString([]byte(s.JWTSecretKey))
+	}
+
+	key := s.JWTKeys[0]
+	token.Method = key.method()
+	token.Header["alg"] = token.Method.Alg()
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
 }
 
 // VerifyJWT verifies if a JWT is valid under some conditions.
 func (s *JWTService) VerifyJWT(signedString string) (*JWTSubject, error) {
 	var sub JWTSubject
 
+	methods := []string{jwt.SigningMethodHS256.Alg()}
+	if len(s.JWTKeys) > 0 {
+		methods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	}
+
 	parser := jwt.NewParser(jwt.WithAudience(s.JWTAudience),
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithValidMethods(methods),
 		jwt.WithExpirationRequired(),
 		jwt.WithIssuer(s.JWTDomain))
-	token, err := parser.ParseWithClaims(signedString, &sub, func(t *jwt.Token) (any, error) {
-		return []byte(s.JWTSecretKey), nil
-	})
+	token, err := parser.ParseWithClaims(signedString, &sub, s.getKey)
 
 	if err != nil || !token.Valid {
 		return nil, err
@@ -62,3 +98,30 @@ func (s *JWTService) VerifyJWT(signedString string) (*JWTSubject, error) {
 
 	return &sub, err
 }
+
+// JWKS returns the public keys that tokens can be verified with, empty when using HS256.
+func (s *JWTService) JWKS() JWKSResponse {
+	keys := make([]JWK, 0, len(s.JWTKeys))
+	for _, key := range s.JWTKeys {
+		keys = append(keys, key.JWK())
+	}
+	return JWKSResponse{Keys: keys}
+}
+
+// getKey finds the key a token was signed with by its kid, which has to match the algorithm.
+func (s *JWTService) getKey(t *jwt.Token) (any, error) {
+	if len(s.JWTKeys) == 0 {
+		return []byte(s.JWTSecretKey), nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	for _, key := range s.JWTKeys {
+		if key.ID == kid {
+			if key.method() != t.Method {
+				return nil, errors.New("signing method doesn't match the key")
+			}
+			return key.PublicKey, nil
+		}
+	}
+	return nil, errors.New("token is signed with an unknown key")
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrJWTKeyInvalid     = errors.New("invalid jwt key")
+	ErrJWTKeyUnsupported = errors.New("jwt keys have to be ed25519 or rsa")
+)
+
+// JWTKey is an asymmetric key tokens are signed or verified with, identified by the kid header.
+// Keys without a private key only verify, which is how old keys are kept during a rotation.
+type JWTKey struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// JWK is a public key as published in the JWKS.
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// ParseJWTKey parses a PEM encoded private or public key.
+func ParseJWTKey(id string, data []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s is not PEM encoded", ErrJWTKeyInvalid, id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s has PEM type %s", ErrJWTKeyInvalid, id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrJWTKeyInvalid, id, err)
+	}
+
+	key := &JWTKey{ID: id}
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.PrivateKey, key.PublicKey = k, k.Public()
+	case *rsa.PrivateKey:
+		key.PrivateKey, key.PublicKey = k, k.Public()
+	case ed25519.PublicKey, *rsa.PublicKey:
+		key.PublicKey = k
+	default:
+		return nil, fmt.Errorf("%w: %s is a %T", ErrJWTKeyUnsupported, id, parsed)
+	}
+	return key, nil
+}
+
+// LoadJWTKeys loads keys from a comma separated list of kid=source pairs, where the source is
+// either a path to a PEM file or the PEM itself, with newlines escaped as \n. The first key
+// signs and has to be private, the rest only verify.
+func LoadJWTKeys(spec string) ([]*JWTKey, error) {
+	var keys []*JWTKey
+	seen := make(map[string]bool)
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, source, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || source == "" {
+			return nil, fmt.Errorf("%w: expected kid=source, got %q", ErrJWTKeyInvalid, entry)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: kid %s is used twice", ErrJWTKeyInvalid, id)
+		}
+		seen[id] = true
+
+		var data []byte
+		if strings.HasPrefix(source, "-----BEGIN") {
+			data = []byte(strings.ReplaceAll(source, `\n`, "\n"))
+		} else {
+			var err error
+			data, err = os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("%w: can't read %s: %w", ErrJWTKeyInvalid, id, err)
+			}
+		}
+
+		key, err := ParseJWTKey(id, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) > 0 && keys[0].PrivateKey == nil {
+		return nil, fmt.Errorf("%w: the signing key %s has no private key", ErrJWTKeyInvalid, keys[0].ID)
+	}
+	return keys, nil
+}
+
+// method is the signing method of the key, RS256 for RSA and EdDSA for Ed25519.
+func (k *JWTKey) method() jwt.SigningMethod {
+	if _, ok := k.PublicKey.(*rsa.PublicKey); ok {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// JWK returns the public part of the key.
+func (k *JWTKey) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Alg: k.method().Alg(), Use: "sig"}
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
@@ -1,6 +1,12 @@
 package services_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,7 +20,7 @@ func TestSignJWT(t *testing.T) {
 		JWTDomain:    "https://example.com",
 		JWTSecretKey: "test",
 	}
-	str, err := jwtService.SignJWT(2, "name", "test@example.com", "lol hi", nil, true)
+	str, err := jwtService.SignJWT(2, "name", "test@example.com", "lol hi", nil, true, "session", 3)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, str)
@@ -27,6 +33,8 @@ func TestSignJWT(t *testing.T) {
 		assert.Equal(t, claims.UserID, uint(2))
 		assert.Equal(t, claims.Email, "test@example.com")
 		assert.Equal(t, claims.Roles, "lol hi")
+		assert.Equal(t, claims.SessionID, "session")
+		assert.Equal(t, claims.TokenVersion, int64(3))
 	})
 
 	jwtService2 := services.JWTService{
@@ -42,3 +50,94 @@ func TestSignJWT(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+// pemKey encodes a private key, or only its public key, with newlines escaped like in env.
+func pemKey(t *testing.T, key any, public bool) string {
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		assert.Nil(t, err)
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.Nil(t, err)
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	return strings.ReplaceAll(string(pem.EncodeToMemory(block)), "\n", `\n`)
+}
+
+func TestJWTKeys(t *testing.T) {
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	newService := func(spec string) services.JWTService {
+		keys, err := services.LoadJWTKeys(spec)
+		assert.Nil(t, err)
+		return services.JWTService{
+			JWTExpiry:    30,
+			JWTAudience:  "test",
+			JWTDomain:    "https://example.com",
+			JWTSecretKey: "test",
+			JWTKeys:      keys,
+		}
+	}
+
+	// Signs with the Ed25519 key, then rotates to the RSA key while still verifying the old one.
+	oldService := newService("ed-1=" + pemKey(t, edPrivate, false))
+	rotatedService := newService("rsa-2=" + pemKey(t, rsaPrivate, false) + ",ed-1=" + pemKey(t, edPublic, true))
+	droppedService := newService("rsa-2=" + pemKey(t, rsaPrivate, false))
+
+	oldToken, err := oldService.SignJWT(2, "name", "test@example.com", "user", nil, true, "session", 0)
+	assert.Nil(t, err)
+	newToken, err := rotatedService.SignJWT(2, "name", "test@example.com", "user", nil, true, "session", 0)
+	assert.Nil(t, err)
+
+	t.Run("EdDSA", func(t *testing.T) {
+		claims, err := oldService.VerifyJWT(oldToken)
+		assert.Nil(t, err)
+		assert.Equal(t, uint(2), claims.UserID)
+	})
+
+	t.Run("RotationVerifiesOldKey", func(t *testing.T) {
+		_, err := rotatedService.VerifyJWT(oldToken)
+		assert.Nil(t, err)
+		_, err = rotatedService.VerifyJWT(newToken)
+		assert.Nil(t, err)
+	})
+
+	t.Run("DroppedKeyFails", func(t *testing.T) {
+		_, err := droppedService.VerifyJWT(oldToken)
+		assert.NotNil(t, err)
+		_, err = droppedService.VerifyJWT(newToken)
+		assert.Nil(t, err)
+	})
+
+	t.Run("NoHS256WithKeys", func(t *testing.T) {
+		hsService := services.JWTService{JWTExpiry: 30, JWTAudience: "test", JWTDomain: "https://example.com", JWTSecretKey: "test"}
+		hsToken, err := hsService.SignJWT(2, "name", "test@example.com", "user", nil, true, "session", 0)
+		assert.Nil(t, err)
+
+		_, err = rotatedService.VerifyJWT(hsToken)
+		assert.NotNil(t, err)
+		_, err = hsService.VerifyJWT(newToken)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("JWKS", func(t *testing.T) {
+		jwks := rotatedService.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "rsa-2", jwks.Keys[0].Kid)
+		assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+		assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+		assert.Equal(t, "ed-1", jwks.Keys[1].Kid)
+		assert.Equal(t, "EdDSA", jwks.Keys[1].Alg)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	})
+
+	t.Run("SigningKeyMustBePrivate", func(t *testing.T) {
+		_, err := services.LoadJWTKeys("ed-1=" + pemKey(t, edPublic, true))
+		assert.ErrorIs(t, err, services.ErrJWTKeyInvalid)
+	})
+}
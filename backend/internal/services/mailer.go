@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"html"
+	"io"
 	"log"
+	"net/url"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -270,6 +273,312 @@ Your bid has been denied by the seller. You may no longer participate in this au
     You are receiving this because you are a bidder or watcher for this item. This mail is automated, do not reply.
   </p>
 </body>
+</html>`
+	paymentReminderTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your payment is due soon</h2>
+
+  <p>
+		You won the auction for "<strong>%s</strong>" at <strong>$%.2f</strong>, but the transaction hasn't been paid yet.
+  </p>
+
+  <hr />
+
+	<a href="%s">Link to product</a>
+
+  <p>
+		Please pay before <strong>%s</strong>. After that, the transaction is cancelled automatically and a negative rating is left on your account.
+  </p>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	transactionCompletedTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your transaction is complete</h2>
+
+  <p>
+		The transaction for "<strong>%s</strong>" has been completed, for a final price of <strong>$%.2f</strong>.
+  </p>
+
+	<a href="%s">Link to product</a>
+
+  <p>
+		The invoice is attached to this email, and can be downloaded again from the transaction at any time.
+  </p>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	passwordResetTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Reset your password</h2>
+
+  <p>
+    Someone, hopefully you, asked to reset the password of your account.
+  </p>
+
+  <hr />
+
+	<a href="%s">Reset my password</a>
+
+  <hr />
+
+  <p>
+    This link is valid for <strong>%d minutes</strong> and can only be used once.
+    If you did not request a password reset, please ignore this email.
+  </p>
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	accountLockedTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your account was locked</h2>
+
+  <p>
+    There were too many wrong passwords for your account, so logging in is locked until <strong>%s</strong>.
+  </p>
+
+  <hr />
+
+  <p>
+    If this wasn't you, someone might be guessing your password. You can reset your password to be safe.
+  </p>
+
+	<a href="%s">Reset my password</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	emailChangeTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Confirm your new email</h2>
+
+  <p>
+    Someone, hopefully you, asked to change the email of their account to this address.
+  </p>
+
+  <hr />
+
+	<a href="%s">Confirm my email</a>
+
+  <hr />
+
+  <p>
+    This link is valid for <strong>%d minutes</strong> and can only be used once.
+    If you did not request this, please ignore this email.
+  </p>
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	emailChangeNoticeTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>%s</h2>
+
+  <p>
+    %s <strong>%s</strong>.
+  </p>
+
+  <hr />
+
+  <p>
+    If this wasn't you, someone might know your password. You can reset your password to be safe.
+  </p>
+
+	<a href="%s">Reset my password</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	sellerRequestApprovedTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>You can now sell on CherryAuctions</h2>
+
+  <p>
+    Your request for seller privileges was approved. You can list auctions until <strong>%s</strong>.
+  </p>
+
+  <p>%s</p>
+
+  <hr />
+
+	<a href="%s">View my subscription</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	sellerRequestRejectedTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your seller request was rejected</h2>
+
+  <p>
+    Your request for seller privileges was rejected, because:
+  </p>
+
+  <blockquote>%s</blockquote>
+
+  <hr />
+
+  <p>
+    You can send a new request once you've addressed it.
+  </p>
+
+	<a href="%s">Request again</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	subscriptionReminderTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your seller subscription is about to expire</h2>
+
+  <p>
+    Your seller subscription expires on <strong>%s</strong>. After that, you won't be able to post new auctions.
+  </p>
+
+  <p>
+    Your <strong>%d</strong> live auctions will keep running until they end, and will be sold as usual.
+  </p>
+
+  <hr />
+
+	<a href="%s">Renew my subscription</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	subscriptionExtendedTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Your seller subscription was extended</h2>
+
+  <p>
+    An admin extended your seller subscription. You can list auctions until <strong>%s</strong>.
+  </p>
+
+  <p>%s</p>
+
+  <hr />
+
+	<a href="%s">View my subscription</a>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
+</html>`
+	deliveryReminderTemplate = `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+</head>
+<body style="font-family: sans-serif;">
+  <h2>Did you receive your item?</h2>
+
+  <p>
+		The seller marked "<strong>%s</strong>" as delivered.
+  </p>
+
+  <hr />
+
+	<a href="%s">Link to product</a>
+
+  <p>
+		If something is wrong, please let the seller know before <strong>%s</strong>. After that, the transaction is completed automatically.
+  </p>
+
+  <hr />
+
+  <p style="color: #666; font-size: 12px;">
+	This mail is automated, do not reply.
+  </p>
+</body>
 </html>`
 )
 
@@ -441,6 +750,123 @@ func (s *MailerService) SendOTPEmail(user *models.User, otp string) {
 	}()
 }
 
+// SendPasswordResetEmail sends an email with a link to reset the password.
+func (s *MailerService) SendPasswordResetEmail(user *models.User, token string, validFor time.Duration) {
+	go func() {
+		link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.CORS.Origins, url.QueryEscape(token))
+		body := fmt.Sprintf(passwordResetTemplate, link, int(validFor.Minutes()))
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *user.Email, *user.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Password Reset")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send password reset email: %v", err)
+		}
+	}()
+}
+
+// SendAccountLockedEmail lets a user know their account is locked from too many wrong passwords.
+func (s *MailerService) SendAccountLockedEmail(user *models.User, until time.Time) {
+	go func() {
+		link := fmt.Sprintf("%s/forgot", s.cfg.CORS.Origins)
+		body := fmt.Sprintf(accountLockedTemplate, until.Format(time.RFC1123), link)
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *user.Email, *user.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Account Locked")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send account locked email: %v", err)
+		}
+	}()
+}
+
+// SendEmailChangeConfirmEmail sends a link to confirm a new email to that address.
+func (s *MailerService) SendEmailChangeConfirmEmail(user *models.User, newEmail string, token string, validFor time.Duration) {
+	go func() {
+		link := fmt.Sprintf("%s/confirm-email?token=%s", s.cfg.CORS.Origins, url.QueryEscape(token))
+		body := fmt.Sprintf(emailChangeTemplate, link, int(validFor.Minutes()))
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", newEmail, *user.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Confirm Your Email")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send email change email: %v", err)
+		}
+	}()
+}
+
+// SendEmailChangeNoticeEmail lets the old email of a user know it's being changed, or was changed
+// once confirmed.
+func (s *MailerService) SendEmailChangeNoticeEmail(user *models.User, newEmail string, confirmed bool) {
+	go func() {
+		title, text := "Your email is being changed", "Someone asked to change the email of your account to"
+		if confirmed {
+			title, text = "Your email was changed", "The email of your account was changed to"
+		}
+		link := fmt.Sprintf("%s/forgot", s.cfg.CORS.Origins)
+		body := fmt.Sprintf(emailChangeNoticeTemplate, title, text, newEmail, link)
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *user.Email, *user.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - "+title)
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send email change notice: %v", err)
+		}
+	}()
+}
+
+// SendSellerRequestApprovedEmail lets a user know they can sell until their subscription expires.
+func (s *MailerService) SendSellerRequestApprovedEmail(request *models.SellerRequest, expiredAt time.Time) {
+	go func() {
+		note := ""
+		if request.Reason != nil {
+			note = html.EscapeString(*request.Reason)
+		}
+		link := fmt.Sprintf("%s/subscriptions", s.cfg.CORS.Origins)
+		body := fmt.Sprintf(sellerRequestApprovedTemplate, expiredAt.Format(time.RFC1123), note, link)
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *request.User.Email, *request.User.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Seller Request Approved")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send seller request approved email: %v", err)
+		}
+	}()
+}
+
+// SendSellerRequestRejectedEmail lets a user know why their seller request was rejected.
+func (s *MailerService) SendSellerRequestRejectedEmail(request *models.SellerRequest) {
+	go func() {
+		link := fmt.Sprintf("%s/subscriptions", s.cfg.CORS.Origins)
+		body := fmt.Sprintf(sellerRequestRejectedTemplate, html.EscapeString(*request.Reason), link)
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *request.User.Email, *request.User.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Seller Request Rejected")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send seller request rejected email: %v", err)
+		}
+	}()
+}
+
 func (s *MailerService) SendAuctionExpiredEmail(ctx context.Context, product *models.Product) {
 	fmt.Println("Sending expired for", product.ID)
 	url := fmt.Sprintf("%s/products/%d", s.cfg.CORS.Origins, product.ID)
@@ -605,3 +1031,126 @@ func (s *MailerService) SendDescriptionChangedEmail(product *models.Product, rec
 		}
 	}()
 }
+
+// SendSubscriptionReminderEmail reminds a seller that their subscription is about to expire, and
+// what happens to their live auctions.
+func (s *MailerService) SendSubscriptionReminderEmail(subscription *models.SellerSubscription, liveAuctions int64) error {
+	if subscription.User.Email == nil {
+		return fmt.Errorf("seller %d has no email", subscription.UserID)
+	}
+
+	link := fmt.Sprintf("%s/subscriptions", s.cfg.CORS.Origins)
+	body := fmt.Sprintf(subscriptionReminderTemplate, subscription.ExpiredAt.Format(time.RFC1123), liveAuctions, link)
+
+	message := gomail.NewMessage()
+	message.SetHeader("From", fromHeader)
+	message.SetHeader("To", *subscription.User.Email)
+	message.SetHeader("Subject", "CherryAuctions - Subscription Expiring")
+	message.SetBody("text/html", body)
+
+	return s.mailer.DialAndSend(message)
+}
+
+// SendSubscriptionExtendedEmail lets a seller know an admin extended their subscription.
+func (s *MailerService) SendSubscriptionExtendedEmail(user *models.User, subscription *models.SellerSubscription) {
+	go func() {
+		note := ""
+		if subscription.Note != nil {
+			note = html.EscapeString(*subscription.Note)
+		}
+		link := fmt.Sprintf("%s/subscriptions", s.cfg.CORS.Origins)
+		body := fmt.Sprintf(subscriptionExtendedTemplate, subscription.ExpiredAt.Format(time.RFC1123), note, link)
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetAddressHeader("To", *user.Email, *user.Name)
+		message.SetBody("text/html", body)
+		message.SetHeader("Subject", "CherryAuctions - Subscription Extended")
+
+		if err := s.mailer.DialAndSend(message); err != nil {
+			log.Printf("failed to send subscription extended email: %v", err)
+		}
+	}()
+}
+
+// SendPaymentReminderEmail reminds the winner of a transaction to pay before the deadline.
+func (s *MailerService) SendPaymentReminderEmail(transaction *models.Transaction, deadline time.Time) error {
+	if transaction.Buyer.Email == nil {
+		return fmt.Errorf("buyer %d has no email", transaction.BuyerID)
+	}
+
+	url := fmt.Sprintf("%s/products/%d", s.cfg.CORS.Origins, transaction.ProductID)
+	body := fmt.Sprintf(
+		paymentReminderTemplate,
+		transaction.Product.Name,
+		float64(transaction.FinalPrice)/100,
+		url,
+		deadline.Format(time.RFC1123),
+	)
+
+	message := gomail.NewMessage()
+	message.SetHeader("From", fromHeader)
+	message.SetHeader("To", *transaction.Buyer.Email)
+	message.SetHeader("Subject", "CherryAuctions - Payment Reminder")
+	message.SetBody("text/html", body)
+
+	return s.mailer.DialAndSend(message)
+}
+
+// SendDeliveryReminderEmail reminds the buyer that a delivered transaction completes itself after the deadline.
+func (s *MailerService) SendDeliveryReminderEmail(transaction *models.Transaction, deadline time.Time) error {
+	if transaction.Buyer.Email == nil {
+		return fmt.Errorf("buyer %d has no email", transaction.BuyerID)
+	}
+
+	url := fmt.Sprintf("%s/products/%d", s.cfg.CORS.Origins, transaction.ProductID)
+	body := fmt.Sprintf(
+		deliveryReminderTemplate,
+		transaction.Product.Name,
+		url,
+		deadline.Format(time.RFC1123),
+	)
+
+	message := gomail.NewMessage()
+	message.SetHeader("From", fromHeader)
+	message.SetHeader("To", *transaction.Buyer.Email)
+	message.SetHeader("Subject", "CherryAuctions - Delivery Confirmation Reminder")
+	message.SetBody("text/html", body)
+
+	return s.mailer.DialAndSend(message)
+}
+
+// SendTransactionCompletedEmail lets both parties of a completed transaction know, with the invoice attached.
+func (s *MailerService) SendTransactionCompletedEmail(transaction *models.Transaction, filename string, invoice []byte) error {
+	url := fmt.Sprintf("%s/products/%d", s.cfg.CORS.Origins, transaction.ProductID)
+	body := fmt.Sprintf(
+		transactionCompletedTemplate,
+		transaction.Product.Name,
+		float64(transaction.FinalPrice)/100,
+		url,
+	)
+
+	var messages []*gomail.Message
+	for _, party := range []models.User{transaction.Buyer, transaction.Seller} {
+		if party.Email == nil {
+			continue
+		}
+
+		message := gomail.NewMessage()
+		message.SetHeader("From", fromHeader)
+		message.SetHeader("To", *party.Email)
+		message.SetHeader("Subject", "CherryAuctions - Transaction Completed")
+		message.SetBody("text/html", body)
+		message.Attach(filename, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(invoice)
+			return err
+		}), gomail.SetHeader(map[string][]string{"Content-Type": {"application/pdf"}}))
+		messages = append(messages, message)
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("transaction %d has no one to email", transaction.ID)
+	}
+
+	return s.mailer.DialAndSend(messages...)
+}
@@ -11,7 +11,9 @@ import (
 )
 
 type MiddlewareService struct {
-	JWTService *JWTService
+	JWTService        *JWTService
+	TokenStateService *TokenStateService
+	PermissionService *PermissionService
 }
 
 func (s *MiddlewareService) parseAuthHeaders(g *gin.Context) (*JWTSubject, error) {
@@ -25,6 +27,18 @@ func (s *MiddlewareService) parseAuthHeaders(g *gin.Context) (*JWTSubject, error
 		return nil, errors.New("invalid access token")
 	}
 
+	// Logouts, bans and role changes take effect before the token expires.
+	if s.TokenStateService != nil {
+		err = s.TokenStateService.CheckToken(g.Request.Context(), claims)
+		if errors.Is(err, ErrTokenRevoked) || errors.Is(err, ErrUserSuspended) {
+			return nil, err
+		}
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "user_id": claims.UserID})
+			return nil, errors.New("can't check access token")
+		}
+	}
+
 	return claims, nil
 }
 
@@ -46,27 +60,43 @@ func (s *MiddlewareService) SoftAuthorizedRoute(g *gin.Context) {
 	g.Next()
 }
 
+// authenticate checks the access token of a request, which has to belong to a verified user.
+// Otherwise, block with `401 unauthorized`, or `403 forbidden` if the user is suspended.
+func (s *MiddlewareService) authenticate(g *gin.Context) (*JWTSubject, bool) {
+	claims, err := s.parseAuthHeaders(g)
+	if errors.Is(err, ErrUserSuspended) {
+		logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": err.Error()})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	if !claims.Verified {
+		logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": "not verified"})
+		g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "not verified"})
+		return nil, false
+	}
+
+	g.Set("claims", claims)
+	return claims, true
+}
+
 // AuthorizedRoleRoute creates a GIN handler that forces the user to have a specified permission name
 // to be allowed to proceed. Otherwise, block with `403 forbidden`.
 //
 // If role is empty, this just wants the user to be authenticated.
 func (s *MiddlewareService) AuthorizedRoute(role string) func(*gin.Context) {
 	return func(g *gin.Context) {
-		claims, err := s.parseAuthHeaders(g)
-		if err != nil {
-			logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": err.Error()})
-			g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: err.Error()})
-			return
-		}
-
-		if !claims.Verified {
-			logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": "not verified"})
-			g.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse{Error: "not verified"})
+		claims, ok := s.authenticate(g)
+		if !ok {
 			return
 		}
 
 		// Doesn't support wildcard permissions, but I don't care.
-		g.Set("claims", claims)
 		roles := strings.Split(claims.Roles, " ")
 		if role != "" {
 			for _, hasRole := range roles {
@@ -85,3 +115,39 @@ func (s *MiddlewareService) AuthorizedRoute(role string) func(*gin.Context) {
 		g.Next()
 	}
 }
+
+// RequirePermission creates a GIN handler that forces the user to have a role granting a
+// permission to be allowed to proceed. Otherwise, block with `403 forbidden`.
+func (s *MiddlewareService) RequirePermission(permission string) func(*gin.Context) {
+	return func(g *gin.Context) {
+		claims, ok := s.authenticate(g)
+		if !ok {
+			return
+		}
+
+		allowed, err := s.PermissionService.HasPermission(g.Request.Context(), claims.Roles, permission)
+		if err != nil {
+			logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "user_id": claims.UserID, "permission": permission})
+			g.AbortWithStatusJSON(http.StatusInternalServerError, shared.ErrorResponse{Error: "can't check permissions"})
+			return
+		}
+		if !allowed {
+			logging.LogMessage(g, logging.LOG_DEBUG, gin.H{"error": "not enough permissions", "roles": claims.Roles, "permission": permission})
+			g.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse{Error: "not enough permissions"})
+			return
+		}
+
+		g.Next()
+	}
+}
+
+// Can checks if the authenticated user has a permission, for handlers that allow more than
+// one kind of user. Failing to check counts as not having it.
+func (s *MiddlewareService) Can(g *gin.Context, claims *JWTSubject, permission string) bool {
+	allowed, err := s.PermissionService.HasPermission(g.Request.Context(), claims.Roles, permission)
+	if err != nil {
+		logging.LogMessage(g, logging.LOG_ERROR, gin.H{"error": err.Error(), "user_id": claims.UserID, "permission": permission})
+		return false
+	}
+	return allowed
+}
@@ -0,0 +1,177 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+const (
+	fakeClientID    = "cherry-client"
+	fakeRedirectURL = "http://localhost:5173/oauth/google/callback"
+)
+
+// fakeIssuer is a local OpenID Connect issuer that signs in whoever it's told to.
+type fakeIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	audience string
+
+	// What the next sign in returns, and the pending authorization codes.
+	claims jwt.MapClaims
+	codes  map[string]fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	issuer := &fakeIssuer{key: key, kid: "key-1", audience: fakeClientID, codes: map[string]fakeAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": issuer.kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(issuer.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		auth, ok := issuer.codes[r.Form.Get("code")]
+		if !ok || r.Form.Get("client_id") != fakeClientID || r.Form.Get("redirect_uri") != fakeRedirectURL ||
+			services.PKCEChallenge(r.Form.Get("code_verifier")) != auth.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delete(issuer.codes, r.Form.Get("code"))
+
+		claims := jwt.MapClaims{
+			"iss":   issuer.server.URL,
+			"aud":   issuer.audience,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": auth.nonce,
+		}
+		for k, v := range issuer.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = issuer.kid
+		signed, _ := token.SignedString(issuer.key)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// authorize signs in at the authorization endpoint like a browser would, returning the code.
+func (f *fakeIssuer) authorize(t *testing.T, authURL string) string {
+	parsed, err := url.Parse(authURL)
+	assert.Nil(t, err)
+
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	code := "code-" + query.Get("state")
+	f.codes[code] = fakeAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code
+}
+
+func TestOIDCProvider(t *testing.T) {
+	ctx := context.Background()
+	issuer := newFakeIssuer(t)
+	provider := services.NewOIDCProvider(issuer.server.URL, fakeClientID, "secret", fakeRedirectURL, issuer.server.Client())
+	issuer.claims = jwt.MapClaims{"sub": "1234", "email": "nguyet@example.com", "email_verified": true, "name": "Nguyệt"}
+
+	verifier := "a-very-long-and-random-code-verifier-for-pkce"
+	signIn := func(state string, nonce string) string {
+		authURL, err := provider.AuthCodeURL(ctx, state, nonce, services.PKCEChallenge(verifier))
+		assert.Nil(t, err)
+		return issuer.authorize(t, authURL)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		code := signIn("state-1", "nonce-1")
+		identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "1234", identity.Subject)
+		assert.Equal(t, "nguyet@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+		assert.Equal(t, "Nguyệt", identity.Name)
+	})
+
+	t.Run("CodeReused", func(t *testing.T) {
+		code := signIn("state-2", "nonce-2")
+		_, err := provider.Exchange(ctx, code, verifier, "nonce-2")
+		assert.Nil(t, err)
+		_, err = provider.Exchange(ctx, code, verifier, "nonce-2")
+		assert.ErrorIs(t, err, services.ErrOIDCExchangeFailed)
+	})
+
+	t.Run("WrongVerifier", func(t *testing.T) {
+		code := signIn("state-3", "nonce-3")
+		_, err := provider.Exchange(ctx, code, "another-code-verifier", "nonce-3")
+		assert.ErrorIs(t, err, services.ErrOIDCExchangeFailed)
+	})
+
+	t.Run("WrongNonce", func(t *testing.T) {
+		code := signIn("state-4", "nonce-4")
+		_, err := provider.Exchange(ctx, code, verifier, "nonce-other")
+		assert.ErrorIs(t, err, services.ErrOIDCInvalidToken)
+	})
+
+	t.Run("WrongAudience", func(t *testing.T) {
+		issuer.audience = "someone-else"
+		defer func() { issuer.audience = fakeClientID }()
+
+		code := signIn("state-5", "nonce-5")
+		_, err := provider.Exchange(ctx, code, verifier, "nonce-5")
+		assert.ErrorIs(t, err, services.ErrOIDCInvalidToken)
+	})
+
+	t.Run("RotatedKey", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(t, err)
+		issuer.key, issuer.kid = key, "key-2"
+
+		code := signIn("state-6", "nonce-6")
+		_, err = provider.Exchange(ctx, code, verifier, "nonce-6")
+		assert.Nil(t, err)
+	})
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// From RFC 7636, appendix B.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", services.PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -12,6 +15,9 @@ import (
 	"luny.dev/cherryauctions/internal/repositories"
 )
 
+// An OTP is cleared after this many wrong codes, and another one has to be sent.
+const otpMaxAttempts = 5
+
 type OTPService struct {
 	mailer   *MailerService
 	userRepo *repositories.UserRepository
@@ -21,6 +27,7 @@ var (
 	ErrOTPDidntUpdate = errors.New("couldn't update otp in user repo, wrong id?")
 	ErrOTPWrongOTP    = errors.New("wrong otp")
 	ErrOTPCantClear   = errors.New("couldn't clear otp")
+	ErrOTPInvalidated = errors.New("too many wrong codes, please request a new otp")
 )
 
 func NewOTPService(
@@ -39,11 +46,22 @@ func (s *OTPService) VerifyOTP(ctx context.Context, userID uint, otpCode string)
 		return err
 	}
 
-	if user.OTPCode == nil || *user.OTPCode != otpCode || user.OTPExpiredAt == nil || user.OTPExpiredAt.Before(time.Now()) {
+	if user.OTPHash == nil || user.OTPExpiredAt == nil || user.OTPExpiredAt.Before(time.Now()) {
+		return ErrOTPWrongOTP
+	}
+
+	otpHash := HashOTP(otpCode)
+	if subtle.ConstantTimeCompare([]byte(*user.OTPHash), []byte(otpHash)) != 1 {
+		if _, err := s.userRepo.FailOTP(ctx, userID, otpMaxAttempts); err != nil {
+			return err
+		}
+		if user.OTPAttempts+1 >= otpMaxAttempts {
+			return ErrOTPInvalidated
+		}
 		return ErrOTPWrongOTP
 	}
 
-	rows, err := s.userRepo.ClearOTP(ctx, userID)
+	rows, err := s.userRepo.ClearOTP(ctx, userID, otpHash)
 	if err != nil {
 		return err
 	}
@@ -62,8 +80,8 @@ func (s *OTPService) SendOTP(ctx context.Context, user *models.User) error {
 		return err
 	}
 
-	otpCode := otp.Int64() + 100000
-	rows, err := s.userRepo.UpdateOTP(ctx, user.ID, fmt.Sprintf("%d", otpCode))
+	otpCode := fmt.Sprintf("%d", otp.Int64()+100000)
+	rows, err := s.userRepo.UpdateOTP(ctx, user.ID, HashOTP(otpCode))
 	if err != nil {
 		return err
 	}
@@ -72,6 +90,12 @@ func (s *OTPService) SendOTP(ctx context.Context, user *models.User) error {
 		return ErrOTPDidntUpdate
 	}
 
-	s.mailer.SendOTPEmail(user, fmt.Sprintf("%d", otpCode))
+	s.mailer.SendOTPEmail(user, otpCode)
 	return nil
 }
+
+// HashOTP hashes an OTP code for storage.
+func HashOTP(code string) string {
+	hashed := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hashed[:])
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+var (
+	ErrPasskeyChallengeInvalid = errors.New("invalid or expired passkey challenge")
+	ErrPasskeyUnknown          = errors.New("unknown passkey")
+	ErrPasskeyExists           = errors.New("passkey is already registered")
+)
+
+const passkeyTimeout = 5 * time.Minute
+
+// PasskeyCredentialParam is a COSE algorithm the relying party accepts.
+type PasskeyCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// PasskeyCredentialDescriptor refers to a credential by its base64url ID.
+type PasskeyCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// PasskeyCreationOptions are the options for navigator.credentials.create, with binary fields
+// base64url encoded.
+type PasskeyCreationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParam `json:"pubKeyCredParams"`
+	Timeout                int64                    `json:"timeout"`
+	Attestation            string                   `json:"attestation"`
+	AuthenticatorSelection struct {
+		ResidentKey      string `json:"residentKey"`
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	ExcludeCredentials []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+}
+
+// PasskeyRequestOptions are the options for navigator.credentials.get. No credentials are
+// allowed explicitly, the authenticator picks a discoverable one.
+type PasskeyRequestOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int64  `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PasskeyService runs the WebAuthn ceremonies for passkeys, storing challenges and credentials.
+type PasskeyService struct {
+	rp           *WebAuthnRelyingParty
+	random       *RandomService
+	webAuthnRepo *repositories.WebAuthnRepository
+}
+
+func NewPasskeyService(rp *WebAuthnRelyingParty, random *RandomService, webAuthnRepo *repositories.WebAuthnRepository) *PasskeyService {
+	return &PasskeyService{
+		rp:           rp,
+		random:       random,
+		webAuthnRepo: webAuthnRepo,
+	}
+}
+
+// BeginRegistration creates the options for adding a passkey to a user.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, user models.User) (*PasskeyCreationOptions, error) {
+	challenge, err := s.newChallenge(ctx, "registration", &user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.webAuthnRepo.GetCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &PasskeyCreationOptions{
+		Challenge:          challenge,
+		Timeout:            passkeyTimeout.Milliseconds(),
+		Attestation:        "none",
+		ExcludeCredentials: make([]PasskeyCredentialDescriptor, 0, len(existing)),
+	}
+	options.RP.ID = s.rp.ID
+	options.RP.Name = s.rp.Name
+	options.User.ID = PasskeyUserHandle(user.ID)
+	if user.Email != nil {
+		options.User.Name = *user.Email
+	}
+	options.User.DisplayName = options.User.Name
+	if user.Name != nil {
+		options.User.DisplayName = *user.Name
+	}
+	for _, alg := range s.rp.SupportedAlgorithms() {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, PasskeyCredentialParam{Type: "public-key", Alg: alg})
+	}
+	options.AuthenticatorSelection.ResidentKey = "required"
+	options.AuthenticatorSelection.UserVerification = "required"
+	for _, credential := range existing {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, PasskeyCredentialDescriptor{Type: "public-key", ID: credential.CredentialID})
+	}
+	return options, nil
+}
+
+// FinishRegistration verifies the response to a registration challenge of the user, and stores
+// the new passkey.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID uint, name string, response WebAuthnRegistration) (*models.WebAuthnCredential, error) {
+	clientData, _, err := ParseClientData(response.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.useChallenge(ctx, clientData.Challenge, "registration", &userID); err != nil {
+		return nil, err
+	}
+
+	verified, err := s.rp.VerifyRegistration(response, clientData.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.webAuthnRepo.GetCredentialByCredentialID(ctx, verified.ID)
+	if err == nil {
+		return nil, ErrPasskeyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	credential := &models.WebAuthnCredential{
+		UserID:       userID,
+		CredentialID: verified.ID,
+		PublicKey:    verified.PublicKey,
+		Algorithm:    verified.Algorithm,
+		SignCount:    int64(verified.SignCount),
+		AAGUID:       verified.AAGUID,
+		Name:         name,
+	}
+	if err := s.webAuthnRepo.CreateCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// BeginLogin creates the options for logging in with any passkey.
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*PasskeyRequestOptions, error) {
+	challenge, err := s.newChallenge(ctx, "login", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.rp.ID,
+		Timeout:          passkeyTimeout.Milliseconds(),
+		UserVerification: "required",
+	}, nil
+}
+
+// FinishLogin verifies the response to a login challenge, returning who logged in.
+func (s *PasskeyService) FinishLogin(ctx context.Context, response WebAuthnAssertion) (uint, error) {
+	clientData, _, err := ParseClientData(response.Response.ClientDataJSON)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.useChallenge(ctx, clientData.Challenge, "login", nil); err != nil {
+		return 0, err
+	}
+
+	credential, err := s.webAuthnRepo.GetCredentialByCredentialID(ctx, response.RawID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrPasskeyUnknown
+	}
+	if err != nil {
+		return 0, err
+	}
+	if response.Response.UserHandle != "" && response.Response.UserHandle != PasskeyUserHandle(credential.UserID) {
+		return 0, ErrPasskeyUnknown
+	}
+
+	signCount, err := s.rp.VerifyAssertion(response, clientData.Challenge, credential.PublicKey, credential.Algorithm, uint32(credential.SignCount))
+	if err != nil {
+		return 0, err
+	}
+
+	// Another login with the same credential got in first, which only a clone could do.
+	rows, err := s.webAuthnRepo.UseCredential(ctx, credential.ID, credential.SignCount, int64(signCount))
+	if err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return 0, ErrWebAuthnSignCount
+	}
+	return credential.UserID, nil
+}
+
+// PasskeyUserHandle is the WebAuthn user handle of a user, their ID as 8 big endian bytes. It
+// doesn't reveal anything the site doesn't already show.
+func PasskeyUserHandle(userID uint) string {
+	return base64.RawURLEncoding.EncodeToString(binary.BigEndian.AppendUint64(nil, uint64(userID)))
+}
+
+func (s *PasskeyService) newChallenge(ctx context.Context, ceremony string, userID *uint) (string, error) {
+	raw, err := s.random.GenerateSecretKey(32)
+	if err != nil {
+		return "", err
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = s.webAuthnRepo.CreateChallenge(ctx, &models.WebAuthnChallenge{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		UserID:    userID,
+		ExpiredAt: time.Now().Add(passkeyTimeout),
+	})
+	return challenge, err
+}
+
+func (s *PasskeyService) useChallenge(ctx context.Context, challenge string, ceremony string, userID *uint) (models.WebAuthnChallenge, error) {
+	found, err := s.webAuthnRepo.UseChallenge(ctx, challenge, ceremony, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return found, ErrPasskeyChallengeInvalid
+	}
+	return found, err
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+const (
+	passwordResetExpiry = 30 * time.Minute
+	passwordResetWindow = time.Hour
+
+	// How many resets can be requested within the window.
+	maxResetsPerEmail = 3
+	maxResetsPerIP    = 10
+)
+
+var ErrResetRateLimited = errors.New("too many password reset requests")
+
+type PasswordResetService struct {
+	mailer    *MailerService
+	random    *RandomService
+	password  *PasswordService
+	resetRepo *repositories.PasswordResetRepository
+	userRepo  *repositories.UserRepository
+}
+
+func NewPasswordResetService(
+	mailer *MailerService,
+	random *RandomService,
+	password *PasswordService,
+	resetRepo *repositories.PasswordResetRepository,
+	userRepo *repositories.UserRepository,
+) *PasswordResetService {
+	return &PasswordResetService{
+		mailer:    mailer,
+		random:    random,
+		password:  password,
+		resetRepo: resetRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// RequestReset emails a reset link if the email belongs to an account with a password. Whether it
+// does is never reported back, so the endpoint can't be used to find out who has an account.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string, ip string) error {
+	// Emails are matched case-insensitively, so the limits have to be too.
+	email = strings.ToLower(strings.TrimSpace(email))
+	reset := models.PasswordReset{
+		Email:     email,
+		RequestIP: ip,
+		ExpiredAt: time.Now().Add(passwordResetExpiry),
+	}
+
+	var encodedToken string
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	hasPassword := err == nil && user.Email != nil && user.Password != nil && user.OauthType == "none"
+	if hasPassword {
+		token, err := s.random.GenerateSecretKey(32)
+		if err != nil {
+			return err
+		}
+		encodedToken = base64.URLEncoding.EncodeToString(token)
+		tokenHash, _ := HashResetToken(encodedToken)
+
+		reset.UserID = &user.ID
+		reset.TokenHash = &tokenHash
+	}
+
+	err = s.resetRepo.CreatePasswordReset(ctx, &reset, time.Now().Add(-passwordResetWindow), maxResetsPerEmail, maxResetsPerIP)
+	if errors.Is(err, repositories.ErrTooManyResets) {
+		return ErrResetRateLimited
+	}
+	if err != nil || !hasPassword {
+		return err
+	}
+
+	s.mailer.SendPasswordResetEmail(&user, encodedToken, passwordResetExpiry)
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed token, and logs the user out everywhere.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token string, newPassword string) (uint, error) {
+	tokenHash, err := HashResetToken(token)
+	if err != nil {
+		return 0, repositories.ErrResetTokenInvalid
+	}
+
+	hashedPassword, err := s.password.HashPassword(newPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.resetRepo.ResetPassword(ctx, tokenHash, hashedPassword)
+}
+
+// HashResetToken hashes an emailed reset token the way it's stored, like refresh tokens are.
+func HashResetToken(token string) (string, error) {
+	decoded, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+
+	hashed := sha256.Sum256(decoded)
+	return base64.URLEncoding.EncodeToString(hashed[:]), nil
+}
@@ -0,0 +1,34 @@
+package services_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestHashResetToken(t *testing.T) {
+	token := base64.URLEncoding.EncodeToString([]byte("a very random reset token"))
+
+	t.Run("Stable", func(t *testing.T) {
+		first, err := services.HashResetToken(token)
+		assert.Nil(t, err)
+		second, err := services.HashResetToken(token)
+		assert.Nil(t, err)
+		assert.Equal(t, first, second)
+		assert.NotEqual(t, token, first)
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		other := base64.URLEncoding.EncodeToString([]byte("another random reset token"))
+		first, _ := services.HashResetToken(token)
+		second, _ := services.HashResetToken(other)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := services.HashResetToken("not base64!")
+		assert.NotNil(t, err)
+	})
+}
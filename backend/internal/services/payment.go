@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+const (
+	PaymentEventSucceeded = "payment.succeeded"
+	PaymentEventFailed    = "payment.failed"
+
+	// How old a signed webhook can be before it is rejected as a replay.
+	paymentWebhookTolerance = 5 * time.Minute
+)
+
+var (
+	ErrPaymentInvalidSignature = errors.New("invalid webhook signature")
+	ErrPaymentInvalidPayload   = errors.New("invalid webhook payload")
+	ErrPaymentNotPending       = errors.New("transaction is not waiting for payment")
+	ErrPaymentsDisabled        = errors.New("payments are disabled")
+)
+
+// PaymentCheckout is what a provider hands back when a payment is started.
+type PaymentCheckout struct {
+	Reference   string
+	CheckoutURL string
+}
+
+// PaymentEvent is a provider-agnostic webhook event.
+type PaymentEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+}
+
+// PaymentProvider is a payment gateway that can take money from the buyer,
+// give it back, and tell us about it through signed webhooks.
+//
+// Refunds are keyed by the reference of the payment, so retrying one never refunds twice.
+type PaymentProvider interface {
+	Name() string
+	CreatePayment(ctx context.Context, amount int64, description string) (PaymentCheckout, error)
+	Refund(ctx context.Context, reference string, amount int64) error
+	ParseWebhook(payload []byte, signature string) (PaymentEvent, error)
+}
+
+// MockPaymentProvider is a built-in gateway that never moves real money.
+// Payments are confirmed by calling the mock checkout endpoint, which signs
+// a webhook the same way a real provider would.
+type MockPaymentProvider struct {
+	secret          []byte
+	checkoutBaseURL string
+	randomService   *RandomService
+}
+
+func NewMockPaymentProvider(secret string, checkoutBaseURL string, randomService *RandomService) *MockPaymentProvider {
+	return &MockPaymentProvider{
+		secret:          []byte(secret),
+		checkoutBaseURL: strings.TrimSuffix(checkoutBaseURL, "/"),
+		randomService:   randomService,
+	}
+}
+
+func (p *MockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockPaymentProvider) CreatePayment(ctx context.Context, amount int64, description string) (PaymentCheckout, error) {
+	if amount <= 0 {
+		return PaymentCheckout{}, fmt.Errorf("invalid payment amount %d", amount)
+	}
+
+	key, err := p.randomService.GenerateSecretKey(16)
+	if err != nil {
+		return PaymentCheckout{}, err
+	}
+
+	reference := "mock_pi_" + hex.EncodeToString(key)
+	return PaymentCheckout{
+		Reference:   reference,
+		CheckoutURL: fmt.Sprintf("%s/payments/mock/%s", p.checkoutBaseURL, reference),
+	}, nil
+}
+
+func (p *MockPaymentProvider) Refund(ctx context.Context, reference string, amount int64) error {
+	if !strings.HasPrefix(reference, "mock_pi_") {
+		return fmt.Errorf("unknown mock payment %s", reference)
+	}
+	return nil
+}
+
+// SignWebhook signs a payload as of a certain time, in the form of `t=<unix>,v1=<hex hmac>`.
+func (p *MockPaymentProvider) SignWebhook(payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, p.computeSignature(timestamp, payload))
+}
+
+func (p *MockPaymentProvider) computeSignature(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *MockPaymentProvider) ParseWebhook(payload []byte, signature string) (PaymentEvent, error) {
+	var timestamp, provided string
+	for part := range strings.SplitSeq(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			provided = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || provided == "" {
+		return PaymentEvent{}, ErrPaymentInvalidSignature
+	}
+
+	age := time.Since(time.Unix(unix, 0))
+	if age > paymentWebhookTolerance || age < -paymentWebhookTolerance {
+		return PaymentEvent{}, ErrPaymentInvalidSignature
+	}
+
+	expected := p.computeSignature(timestamp, payload)
+	if !hmac.Equal([]byte(expected), []byte(provided)) {
+		return PaymentEvent{}, ErrPaymentInvalidSignature
+	}
+
+	var event PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" || event.Reference == "" {
+		return PaymentEvent{}, ErrPaymentInvalidPayload
+	}
+	return event, nil
+}
+
+// PaymentService ties a payment provider to transactions. Without a provider, payments are
+// disabled and transactions are paid outside of the platform.
+type PaymentService struct {
+	provider    PaymentProvider
+	paymentRepo *repositories.PaymentRepository
+}
+
+func NewPaymentService(
+	provider PaymentProvider,
+	paymentRepo *repositories.PaymentRepository,
+) *PaymentService {
+	return &PaymentService{
+		provider:    provider,
+		paymentRepo: paymentRepo,
+	}
+}
+
+// Provider returns the provider payments are made through, or nil if payments are disabled.
+func (s *PaymentService) Provider() PaymentProvider {
+	return s.provider
+}
+
+// Enabled reports whether payments go through a provider.
+func (s *PaymentService) Enabled() bool {
+	return s.provider != nil
+}
+
+// GetPaymentIntent retrieves a payment intent by the provider's reference.
+func (s *PaymentService) GetPaymentIntent(ctx context.Context, reference string) (models.PaymentIntent, error) {
+	return s.paymentRepo.GetPaymentIntentByReference(ctx, reference)
+}
+
+// StartPayment creates a payment intent for a pending transaction, or returns the
+// one that is still open so the buyer doesn't get charged twice.
+func (s *PaymentService) StartPayment(ctx context.Context, transaction *models.Transaction) (models.PaymentIntent, error) {
+	if !s.Enabled() {
+		return models.PaymentIntent{}, ErrPaymentsDisabled
+	}
+	if transaction.TransactionStatus != models.TransactionStatusPending {
+		return models.PaymentIntent{}, ErrPaymentNotPending
+	}
+
+	intent, err := s.paymentRepo.GetOpenPaymentIntent(ctx, transaction.ID)
+	if err == nil {
+		return intent, nil
+	}
+
+	checkout, err := s.provider.CreatePayment(ctx, transaction.FinalPrice, fmt.Sprintf("CherryAuctions transaction #%d", transaction.ID))
+	if err != nil {
+		return models.PaymentIntent{}, err
+	}
+
+	intent = models.PaymentIntent{
+		TransactionID: transaction.ID,
+		Provider:      s.provider.Name(),
+		Reference:     checkout.Reference,
+		Amount:        transaction.FinalPrice,
+		Status:        models.PaymentIntentStatusRequiresPayment,
+		CheckoutURL:   checkout.CheckoutURL,
+	}
+	err = s.paymentRepo.CreatePaymentIntent(ctx, &intent)
+	return intent, err
+}
+
+// HandleWebhook verifies and applies a webhook event from the provider.
+// Events that were already applied return repositories.ErrPaymentEventProcessed.
+//
+// A payment that succeeds after the transaction was cancelled is refunded right away.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (models.PaymentIntent, error) {
+	if !s.Enabled() {
+		return models.PaymentIntent{}, ErrPaymentsDisabled
+	}
+
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return models.PaymentIntent{}, err
+	}
+
+	var status models.PaymentIntentStatus
+	switch event.Type {
+	case PaymentEventSucceeded:
+		status = models.PaymentIntentStatusSucceeded
+	case PaymentEventFailed:
+		status = models.PaymentIntentStatusFailed
+	default:
+		return models.PaymentIntent{}, ErrPaymentInvalidPayload
+	}
+
+	intent, err := s.paymentRepo.ApplyPaymentEvent(ctx, &models.PaymentWebhookEvent{
+		EventID:  event.ID,
+		Provider: s.provider.Name(),
+		Type:     event.Type,
+	}, event.Reference, status)
+	if err != nil {
+		return intent, err
+	}
+
+	if intent.Status == models.PaymentIntentStatusSucceeded && intent.Transaction.TransactionStatus == models.TransactionStatusCancelled {
+		return intent, s.RefundTransaction(ctx, intent.TransactionID)
+	}
+	return intent, nil
+}
+
+// RefundCancelledTransactions refunds every cancelled transaction that still has captured payments.
+func (s *PaymentService) RefundCancelledTransactions(ctx context.Context) {
+	ids, err := s.paymentRepo.GetUnrefundedTransactionIDs(ctx)
+	if err != nil {
+		log.Printf("warning: unable to get unrefunded transactions: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.RefundTransaction(ctx, id); err != nil {
+			log.Printf("warning: unable to refund transaction %d: %v", id, err)
+		}
+	}
+}
+
+// RefundTransaction refunds every captured payment of a transaction. Nothing was captured if
+// payments are disabled.
+func (s *PaymentService) RefundTransaction(ctx context.Context, transactionID uint) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	intents, err := s.paymentRepo.GetSucceededPaymentIntents(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	for _, intent := range intents {
+		if err := s.provider.Refund(ctx, intent.Reference, intent.Amount); err != nil {
+			return err
+		}
+
+		if _, err := s.paymentRepo.MarkPaymentIntentRefunded(ctx, intent.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestMockPaymentProvider(t *testing.T) {
+	provider := services.NewMockPaymentProvider("test-secret", "http://localhost/v1/", &services.RandomService{})
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","reference":"mock_pi_abc"}`)
+
+	t.Run("CreatePayment", func(t *testing.T) {
+		checkout, err := provider.CreatePayment(context.Background(), 1500, "test")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(checkout.Reference, "mock_pi_"))
+		assert.Equal(t, "http://localhost/v1/payments/mock/"+checkout.Reference, checkout.CheckoutURL)
+	})
+
+	t.Run("RejectsZeroAmount", func(t *testing.T) {
+		_, err := provider.CreatePayment(context.Background(), 0, "test")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ValidSignature", func(t *testing.T) {
+		signature := provider.SignWebhook(payload, time.Now())
+		event, err := provider.ParseWebhook(payload, signature)
+		assert.Nil(t, err)
+		assert.Equal(t, "evt_1", event.ID)
+		assert.Equal(t, services.PaymentEventSucceeded, event.Type)
+		assert.Equal(t, "mock_pi_abc", event.Reference)
+	})
+
+	t.Run("TamperedPayload", func(t *testing.T) {
+		signature := provider.SignWebhook(payload, time.Now())
+		tampered := []byte(strings.Replace(string(payload), "mock_pi_abc", "mock_pi_xyz", 1))
+		_, err := provider.ParseWebhook(tampered, signature)
+		assert.ErrorIs(t, err, services.ErrPaymentInvalidSignature)
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		other := services.NewMockPaymentProvider("other-secret", "", &services.RandomService{})
+		signature := other.SignWebhook(payload, time.Now())
+		_, err := provider.ParseWebhook(payload, signature)
+		assert.ErrorIs(t, err, services.ErrPaymentInvalidSignature)
+	})
+
+	t.Run("ExpiredSignature", func(t *testing.T) {
+		signature := provider.SignWebhook(payload, time.Now().Add(-time.Hour))
+		_, err := provider.ParseWebhook(payload, signature)
+		assert.ErrorIs(t, err, services.ErrPaymentInvalidSignature)
+	})
+
+	t.Run("MalformedHeader", func(t *testing.T) {
+		_, err := provider.ParseWebhook(payload, "garbage")
+		assert.ErrorIs(t, err, services.ErrPaymentInvalidSignature)
+	})
+
+	t.Run("Refund", func(t *testing.T) {
+		assert.Nil(t, provider.Refund(context.Background(), "mock_pi_abc", 1500))
+		assert.NotNil(t, provider.Refund(context.Background(), "pi_real", 1500))
+	})
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+// PermissionService resolves what the roles in an access token are allowed to do. The permissions
+// of every role are cached for a short while, changes made by this server are dropped right away.
+type PermissionService struct {
+	roleRepo *repositories.RoleRepository
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	cached *cachedValue[map[string][]string]
+}
+
+func NewPermissionService(roleRepo *repositories.RoleRepository, ttl time.Duration) *PermissionService {
+	return &PermissionService{
+		roleRepo: roleRepo,
+		ttl:      ttl,
+	}
+}
+
+// Permissions lists what a space-separated list of roles is allowed to do, sorted.
+func (s *PermissionService) Permissions(ctx context.Context, roles string) ([]string, error) {
+	rolePermissions, err := s.rolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0)
+	for _, role := range strings.Fields(roles) {
+		permissions = append(permissions, rolePermissions[strings.ToLower(role)]...)
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
+
+// HasPermission checks if any of a space-separated list of roles grants a permission.
+func (s *PermissionService) HasPermission(ctx context.Context, roles string, permission string) (bool, error) {
+	rolePermissions, err := s.rolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range strings.Fields(roles) {
+		if slices.Contains(rolePermissions[strings.ToLower(role)], permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MissingPermission finds one of some permissions that a space-separated list of roles doesn't
+// grant, or "" if it grants all of them. Nobody should hand out or take away more than they have.
+func (s *PermissionService) MissingPermission(ctx context.Context, roles string, permissions []string) (string, error) {
+	granted, err := s.Permissions(ctx, roles)
+	if err != nil {
+		return "", err
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return permission, nil
+		}
+	}
+	return "", nil
+}
+
+// Invalidate forgets the cached permissions, after a role was changed.
+func (s *PermissionService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
+}
+
+func (s *PermissionService) rolePermissions(ctx context.Context) (map[string][]string, error) {
+	s.mu.Lock()
+	cached := s.cached
+	s.mu.Unlock()
+	if cached != nil && time.Since(cached.fetchedAt) < s.ttl {
+		return cached.value, nil
+	}
+
+	roles, err := s.roleRepo.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rolePermissions := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			rolePermissions[strings.ToLower(role.ID)] = append(rolePermissions[strings.ToLower(role.ID)], permission.ID)
+		}
+	}
+
+	s.mu.Lock()
+	s.cached = &cachedValue[map[string][]string]{value: rolePermissions, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return rolePermissions, nil
+}
@@ -1,12 +1,26 @@
 package services
 
 type ServiceRegistry struct {
-	CaptchaService    *CaptchaService
-	JWTService        *JWTService
-	RandomService     *RandomService
-	PasswordService   *PasswordService
-	MiddlewareService *MiddlewareService
-	S3Service         *S3Service
-	MailerService     *MailerService
-	OTPService        *OTPService
+	CaptchaService       *CaptchaService
+	JWTService           *JWTService
+	RandomService        *RandomService
+	PasswordService      *PasswordService
+	MiddlewareService    *MiddlewareService
+	S3Service            *S3Service
+	MailerService        *MailerService
+	OTPService           *OTPService
+	TrackingProvider     TrackingProvider
+	PaymentService       *PaymentService
+	FeeService           *FeeService
+	InvoiceService       *InvoiceService
+	PasswordResetService *PasswordResetService
+	EmailChangeService   *EmailChangeService
+	TokenStateService    *TokenStateService
+	PermissionService    *PermissionService
+	TOTPService          *TOTPService
+	PasskeyService       *PasskeyService
+	ThrottleService      *ThrottleService
+	SubscriptionService  *SubscriptionService
+	AuditService         *AuditService
+	GoogleProvider       IdentityProvider
 }
@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type S3Service struct {
@@ -34,8 +36,29 @@ func (s *S3Service) PutObject(ctx context.Context, key string, data io.Reader) e
 	return nil
 }
 
-func (s *S3Service) GetObject(ctx context.Context, key string) error {
-	// Not necessary due to using concatenation instead
-	// `${AWS_CDN_URL}/${AWS_BUCKET_NAME}/${key}`
-	return nil
+// PutPrivateObject stores an object only readable through a presigned URL.
+func (s *S3Service) PutPrivateObject(ctx context.Context, key string, data io.Reader, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(s.bucketName),
+		Key:          aws.String(key),
+		Body:         data,
+		ACL:          types.ObjectCannedACLPrivate,
+		ContentType:  aws.String(contentType),
+		CacheControl: aws.String("private, no-store"),
+	})
+	return err
+}
+
+// PresignGetObject returns a URL anyone can read a private object from, until it expires.
+func (s *S3Service) PresignGetObject(ctx context.Context, key string, filename string, expires time.Duration) (string, error) {
+	presigned, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucketName),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(`inline; filename="` + filename + `"`),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return presigned.URL, nil
 }
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+var ErrInvalidSubscriptionPlans = errors.New("invalid subscription plans")
+
+// SubscriptionPlan is how long a seller subscription lasts, that users pick when requesting or
+// renewing one.
+type SubscriptionPlan struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Days int    `json:"days"`
+}
+
+func (p SubscriptionPlan) Duration() time.Duration {
+	return time.Duration(p.Days) * 24 * time.Hour
+}
+
+// ParseSubscriptionPlans parses the plans from JSON, like [{"id": "week", "name": "1 Week", "days": 7}].
+// There has to be at least one, and the first one is the default.
+func ParseSubscriptionPlans(raw string) ([]SubscriptionPlan, error) {
+	var plans []SubscriptionPlan
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubscriptionPlans, err)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("%w: there are no plans", ErrInvalidSubscriptionPlans)
+	}
+
+	seen := make(map[string]bool, len(plans))
+	for _, plan := range plans {
+		if plan.ID == "" || seen[plan.ID] {
+			return nil, fmt.Errorf("%w: ids must be unique and not empty", ErrInvalidSubscriptionPlans)
+		}
+		if plan.Days < 1 || plan.Days > 365 {
+			return nil, fmt.Errorf("%w: %s must last between 1 and 365 days", ErrInvalidSubscriptionPlans, plan.ID)
+		}
+		seen[plan.ID] = true
+	}
+	return plans, nil
+}
+
+// SubscriptionService manages the plans of seller subscriptions and reminds sellers before theirs
+// expire.
+//
+// Once a subscription lapses, the seller can't post new auctions, but their live ones keep running
+// until they end and get sold as usual, since bidders already committed to them. Renewing picks up
+// from when the last subscription expires, or from approval if it already did.
+type SubscriptionService struct {
+	plans            []SubscriptionPlan
+	reminder         time.Duration
+	subscriptionRepo *repositories.SubscriptionRepository
+	productRepo      *repositories.ProductRepository
+	mailerService    *MailerService
+}
+
+func NewSubscriptionService(
+	plans []SubscriptionPlan,
+	reminder time.Duration,
+	subscriptionRepo *repositories.SubscriptionRepository,
+	productRepo *repositories.ProductRepository,
+	mailerService *MailerService,
+) *SubscriptionService {
+	return &SubscriptionService{
+		plans:            plans,
+		reminder:         reminder,
+		subscriptionRepo: subscriptionRepo,
+		productRepo:      productRepo,
+		mailerService:    mailerService,
+	}
+}
+
+func (s *SubscriptionService) Plans() []SubscriptionPlan {
+	return s.plans
+}
+
+// Plan looks up a plan. An empty ID is the default plan.
+func (s *SubscriptionService) Plan(id string) (SubscriptionPlan, bool) {
+	if id == "" {
+		return s.plans[0], true
+	}
+
+	for _, plan := range s.plans {
+		if plan.ID == id {
+			return plan, true
+		}
+	}
+	return SubscriptionPlan{}, false
+}
+
+// SendExpiryReminders reminds sellers whose subscription is about to expire, meant to be run by
+// the scheduler. Each subscription is only reminded of once.
+func (s *SubscriptionService) SendExpiryReminders(ctx context.Context) {
+	subscriptions, err := s.subscriptionRepo.GetExpiringSubscriptions(ctx, time.Now().Add(s.reminder))
+	if err != nil {
+		log.Printf("warning: unable to get expiring subscriptions: %v", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		active, err := s.productRepo.CountUserProducts(ctx, subscription.UserID, models.ProductStateActive)
+		if err != nil {
+			log.Printf("warning: unable to count live auctions of %d: %v", subscription.UserID, err)
+			continue
+		}
+
+		if err := s.mailerService.SendSubscriptionReminderEmail(&subscription, active); err != nil {
+			log.Printf("failed to send subscription reminder email: %v", err)
+			continue
+		}
+
+		if _, err := s.subscriptionRepo.SetReminderSent(ctx, subscription.ID); err != nil {
+			log.Printf("failed to mark subscription reminder as sent: %v", err)
+		}
+	}
+}
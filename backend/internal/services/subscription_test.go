@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestParseSubscriptionPlans(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		plans, err := services.ParseSubscriptionPlans(`[{"id":"week","name":"1 Week","days":7},{"id":"month","name":"1 Month","days":30}]`)
+		assert.Nil(t, err)
+		assert.Len(t, plans, 2)
+		assert.Equal(t, "week", plans[0].ID)
+		assert.Equal(t, 30*24*time.Hour, plans[1].Duration())
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := services.ParseSubscriptionPlans(`[`)
+		assert.ErrorIs(t, err, services.ErrInvalidSubscriptionPlans)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := services.ParseSubscriptionPlans(`[]`)
+		assert.ErrorIs(t, err, services.ErrInvalidSubscriptionPlans)
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		_, err := services.ParseSubscriptionPlans(`[{"id":"week","days":7},{"id":"week","days":14}]`)
+		assert.ErrorIs(t, err, services.ErrInvalidSubscriptionPlans)
+	})
+
+	t.Run("DaysOutOfRange", func(t *testing.T) {
+		_, err := services.ParseSubscriptionPlans(`[{"id":"forever","days":0}]`)
+		assert.ErrorIs(t, err, services.ErrInvalidSubscriptionPlans)
+	})
+}
+
+func TestSubscriptionServicePlan(t *testing.T) {
+	plans, _ := services.ParseSubscriptionPlans(`[{"id":"week","days":7},{"id":"month","days":30}]`)
+	service := services.NewSubscriptionService(plans, time.Hour, nil, nil, nil)
+
+	t.Run("Default", func(t *testing.T) {
+		plan, ok := service.Plan("")
+		assert.True(t, ok)
+		assert.Equal(t, "week", plan.ID)
+	})
+
+	t.Run("Known", func(t *testing.T) {
+		plan, ok := service.Plan("month")
+		assert.True(t, ok)
+		assert.Equal(t, 30, plan.Days)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, ok := service.Plan("year")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+// ThrottlePolicy is how failures against a key turn into lockouts. The first few failures are
+// free, then each one locks the key out for twice as long as the last, up to a maximum.
+type ThrottlePolicy struct {
+	FreeAttempts int
+	BaseLockout  time.Duration
+	MaxLockout   time.Duration
+
+	// Failures are forgotten when there wasn't another one for this long.
+	Window time.Duration
+}
+
+var (
+	// LoginAccountPolicy throttles wrong passwords for an account, from anywhere.
+	LoginAccountPolicy = ThrottlePolicy{FreeAttempts: 5, BaseLockout: 1 * time.Minute, MaxLockout: 1 * time.Hour, Window: 24 * time.Hour}
+
+	// LoginIPPolicy throttles failed logins from an IP, for any account. It's looser since many
+	// people can share one.
+	LoginIPPolicy = ThrottlePolicy{FreeAttempts: 20, BaseLockout: 1 * time.Minute, MaxLockout: 1 * time.Hour, Window: 24 * time.Hour}
+
+	// OTPPolicy throttles wrong OTP codes for an account, across every code sent to it.
+	OTPPolicy = ThrottlePolicy{FreeAttempts: 5, BaseLockout: 5 * time.Minute, MaxLockout: 24 * time.Hour, Window: 24 * time.Hour}
+
+	// TOTPPolicy throttles wrong two-factor codes for an account, across every login challenge.
+	TOTPPolicy = ThrottlePolicy{FreeAttempts: 5, BaseLockout: 5 * time.Minute, MaxLockout: 24 * time.Hour, Window: 24 * time.Hour}
+)
+
+// Lockout is how long the nth failure in a row locks out for.
+func (p ThrottlePolicy) Lockout(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+	for range failures - p.FreeAttempts - 1 {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+	return min(lockout, p.MaxLockout)
+}
+
+func LoginAccountKey(userID uint) string {
+	return fmt.Sprintf("login:user:%d", userID)
+}
+
+func LoginIPKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func OTPKey(userID uint) string {
+	return fmt.Sprintf("otp:user:%d", userID)
+}
+
+func TOTPKey(userID uint) string {
+	return fmt.Sprintf("totp:user:%d", userID)
+}
+
+// ThrottleService slows down guessing passwords and codes, by locking out accounts and IPs that
+// fail too often.
+type ThrottleService struct {
+	throttleRepo *repositories.AuthThrottleRepository
+}
+
+func NewThrottleService(throttleRepo *repositories.AuthThrottleRepository) *ThrottleService {
+	return &ThrottleService{
+		throttleRepo: throttleRepo,
+	}
+}
+
+// Check returns how long until none of the keys are locked out, or 0 if they aren't.
+func (s *ThrottleService) Check(ctx context.Context, keys ...string) (time.Duration, error) {
+	throttles, err := s.throttleRepo.GetLockedThrottles(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var wait time.Duration
+	for _, throttle := range throttles {
+		wait = max(wait, time.Until(*throttle.LockedUntil))
+	}
+	return wait, nil
+}
+
+// Fail counts a failure against a key, returning how long it's now locked out for, or 0 if it
+// isn't.
+func (s *ThrottleService) Fail(ctx context.Context, key string, policy ThrottlePolicy) (time.Duration, error) {
+	throttle, err := s.throttleRepo.RecordFailure(ctx, key, policy.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout := policy.Lockout(throttle.Failures)
+	if lockout == 0 {
+		return 0, nil
+	}
+	return lockout, s.throttleRepo.LockUntil(ctx, throttle.ID, time.Now().Add(lockout))
+}
+
+// Reserve counts an attempt against a key up front, as if it failed, so concurrent attempts can't
+// get past the limit between checking and failing. Reset the key if the attempt succeeds.
+//
+// Returns how long until the key isn't locked out if it already was, in which case the attempt
+// isn't allowed. Otherwise returns how long the key is now locked out for, or 0 if it isn't.
+func (s *ThrottleService) Reserve(ctx context.Context, key string, policy ThrottlePolicy) (time.Duration, bool, error) {
+	throttle, reserved, err := s.throttleRepo.ReserveAttempt(ctx, key, policy.Window, policy.Lockout)
+	if err != nil || throttle.LockedUntil == nil {
+		return 0, reserved, err
+	}
+	return max(time.Until(*throttle.LockedUntil), 0), reserved, nil
+}
+
+// Reset forgets the failures of a key, after it succeeded.
+func (s *ThrottleService) Reset(ctx context.Context, key string) error {
+	return s.throttleRepo.Reset(ctx, key)
+}
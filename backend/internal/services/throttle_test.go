@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestThrottlePolicyLockout(t *testing.T) {
+	policy := services.ThrottlePolicy{FreeAttempts: 3, BaseLockout: time.Minute, MaxLockout: 10 * time.Minute, Window: time.Hour}
+
+	t.Run("FreeAttempts", func(t *testing.T) {
+		for failures := range 4 {
+			assert.Equal(t, time.Duration(0), policy.Lockout(failures))
+		}
+	})
+
+	t.Run("Doubles", func(t *testing.T) {
+		assert.Equal(t, 1*time.Minute, policy.Lockout(4))
+		assert.Equal(t, 2*time.Minute, policy.Lockout(5))
+		assert.Equal(t, 4*time.Minute, policy.Lockout(6))
+		assert.Equal(t, 8*time.Minute, policy.Lockout(7))
+	})
+
+	t.Run("Capped", func(t *testing.T) {
+		assert.Equal(t, 10*time.Minute, policy.Lockout(8))
+		assert.Equal(t, 10*time.Minute, policy.Lockout(1000))
+	})
+
+	t.Run("Policies", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), services.LoginAccountPolicy.Lockout(5))
+		assert.Equal(t, time.Minute, services.LoginAccountPolicy.Lockout(6))
+		assert.Equal(t, time.Hour, services.LoginAccountPolicy.Lockout(100))
+		assert.Greater(t, services.LoginIPPolicy.FreeAttempts, services.LoginAccountPolicy.FreeAttempts)
+	})
+}
+
+func TestHashOTP(t *testing.T) {
+	assert.Len(t, services.HashOTP("123456"), 64)
+	assert.Equal(t, services.HashOTP("123456"), services.HashOTP("123456"))
+	assert.NotEqual(t, services.HashOTP("123456"), services.HashOTP("123457"))
+	assert.NotContains(t, services.HashOTP("123456"), "123456")
+}
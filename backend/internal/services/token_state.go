@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+var (
+	ErrTokenRevoked  = errors.New("access token was revoked")
+	ErrUserSuspended = errors.New("account is suspended")
+)
+
+// The cache starts over once it holds this many users or sessions, instead of growing forever.
+const maxCachedTokenStates = 10000
+
+type cachedValue[T any] struct {
+	value     T
+	fetchedAt time.Time
+}
+
+// TokenStateService checks that access tokens weren't revoked before they expire, either by
+// ending their session or by bumping the token version of the user, and that the user isn't
+// suspended. Lookups are cached for a short while, changes made by this server are dropped from
+// the cache right away.
+type TokenStateService struct {
+	userRepo         *repositories.UserRepository
+	refreshTokenRepo *repositories.RefreshTokenRepository
+	ttl              time.Duration
+
+	mu       sync.Mutex
+	users    map[uint]cachedValue[models.User]
+	sessions map[string]cachedValue[bool]
+}
+
+func NewTokenStateService(
+	userRepo *repositories.UserRepository,
+	refreshTokenRepo *repositories.RefreshTokenRepository,
+	ttl time.Duration,
+) *TokenStateService {
+	return &TokenStateService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		ttl:              ttl,
+		users:            make(map[uint]cachedValue[models.User]),
+		sessions:         make(map[string]cachedValue[bool]),
+	}
+}
+
+// CheckToken returns ErrTokenRevoked if the claims are no longer good, or ErrUserSuspended if
+// they are but the user can't use them right now.
+func (s *TokenStateService) CheckToken(ctx context.Context, claims *JWTSubject) error {
+	if claims.SessionID == "" {
+		return ErrTokenRevoked
+	}
+
+	state, err := s.tokenState(ctx, claims.UserID, claims.TokenVersion)
+	if err != nil {
+		return err
+	}
+	if state.TokenVersion != claims.TokenVersion {
+		return ErrTokenRevoked
+	}
+
+	active, err := s.sessionActive(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrTokenRevoked
+	}
+
+	if state.IsSuspended(time.Now()) {
+		return ErrUserSuspended
+	}
+	return nil
+}
+
+// InvalidateUser forgets the cached token state of a user, after it was changed.
+func (s *TokenStateService) InvalidateUser(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, userID)
+}
+
+// InvalidateSession forgets whether a session is active, after it was ended.
+func (s *TokenStateService) InvalidateSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+// InvalidateAll forgets everything, after the token versions of many users were bumped.
+func (s *TokenStateService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.users)
+	clear(s.sessions)
+}
+
+// tokenState looks up the current token version and suspension of a user. A token newer than
+// the cached version means the cache is stale, so it's looked up again.
+func (s *TokenStateService) tokenState(ctx context.Context, userID uint, claimed int64) (models.User, error) {
+	s.mu.Lock()
+	cached, ok := s.users[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < s.ttl && cached.value.TokenVersion >= claimed {
+		return cached.value, nil
+	}
+
+	state, err := s.userRepo.GetTokenState(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.mu.Lock()
+	if len(s.users) >= maxCachedTokenStates {
+		clear(s.users)
+	}
+	s.users[userID] = cachedValue[models.User]{value: state, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return state, nil
+}
+
+// sessionActive looks up whether a session is still going.
+func (s *TokenStateService) sessionActive(ctx context.Context, sessionID string) (bool, error) {
+	s.mu.Lock()
+	cached, ok := s.sessions[sessionID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < s.ttl {
+		return cached.value, nil
+	}
+
+	active, err := s.refreshTokenRepo.IsSessionActive(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	if len(s.sessions) >= maxCachedTokenStates {
+		clear(s.sessions)
+	}
+	s.sessions[sessionID] = cachedValue[bool]{value: active, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return active, nil
+}
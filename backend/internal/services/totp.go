@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"luny.dev/cherryauctions/internal/models"
+	"luny.dev/cherryauctions/internal/repositories"
+)
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication isn't enabled")
+	ErrTOTPNotSetUp       = errors.New("two-factor authentication wasn't set up")
+	ErrTOTPWrongCode      = errors.New("wrong two-factor code")
+	ErrChallengeInvalid   = errors.New("invalid or expired login challenge")
+)
+
+const (
+	totpIssuer = "Cherry Auctions"
+	totpPeriod = 30
+	totpDigits = 6
+
+	// Codes from one step before or after are fine too, for clocks that drift.
+	totpSkew = 1
+
+	recoveryCodeCount    = 10
+	challengeValidFor    = 5 * time.Minute
+	challengeMaxAttempts = 5
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPService handles two-factor authentication with TOTP (RFC 6238), using SHA-1, 6 digits and
+// 30 second steps like every authenticator app expects.
+type TOTPService struct {
+	random        *RandomService
+	userRepo      *repositories.UserRepository
+	twoFactorRepo *repositories.TwoFactorRepository
+}
+
+func NewTOTPService(
+	random *RandomService,
+	userRepo *repositories.UserRepository,
+	twoFactorRepo *repositories.TwoFactorRepository,
+) *TOTPService {
+	return &TOTPService{
+		random:        random,
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+	}
+}
+
+// Setup gives the user a new secret to add to their authenticator, returned along with its
+// otpauth URI. It isn't asked for until confirmed.
+func (s *TOTPService) Setup(ctx context.Context, user *models.User) (string, string, error) {
+	if user.TOTPEnabled {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	raw, err := s.random.GenerateSecretKey(20)
+	if err != nil {
+		return "", "", err
+	}
+	secret := totpEncoding.EncodeToString(raw)
+
+	rows, err := s.twoFactorRepo.SetTOTPSecret(ctx, user.ID, secret)
+	if err != nil {
+		return "", "", err
+	}
+	if rows == 0 {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	return secret, TOTPURI(secret, *user.Email), nil
+}
+
+// Confirm enables TOTP once the user shows a code from their authenticator, returning the
+// recovery codes. They can't be seen again.
+func (s *TOTPService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTOTPNotSetUp
+	}
+
+	step, ok := matchTOTP(*user.TOTPSecret, code, time.Now(), 0)
+	if !ok {
+		return nil, ErrTOTPWrongCode
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.twoFactorRepo.EnableTOTP(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	return codes, nil
+}
+
+// Disable turns off TOTP, with a code or a recovery code.
+func (s *TOTPService) Disable(ctx context.Context, userID uint, code string) error {
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.DisableTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, with a code or a recovery code.
+func (s *TOTPService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyCode checks a 6 digit code from the authenticator, or uses up a recovery code. Each
+// code only works once.
+func (s *TOTPService) VerifyCode(ctx context.Context, userID uint, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled || user.TOTPSecret == nil {
+		return ErrTOTPNotEnabled
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) == totpDigits {
+		step, ok := matchTOTP(*user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return ErrTOTPWrongCode
+		}
+
+		// Someone else might have used the same code just now.
+		rows, err := s.twoFactorRepo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrTOTPWrongCode
+		}
+		return nil
+	}
+
+	rows, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, HashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTOTPWrongCode
+	}
+	return nil
+}
+
+// Status returns whether a user has TOTP enabled, and how many recovery codes they have left.
+func (s *TOTPService) Status(ctx context.Context, userID uint) (bool, int64, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !user.TOTPEnabled {
+		return false, 0, nil
+	}
+
+	count, err := s.twoFactorRepo.CountRecoveryCodes(ctx, userID)
+	return true, count, err
+}
+
+// CreateChallenge starts the second step of a login, returning the token to answer it with.
+func (s *TOTPService) CreateChallenge(ctx context.Context, userID uint) (string, time.Duration, error) {
+	raw, err := s.random.GenerateSecretKey(32)
+	if err != nil {
+		return "", 0, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = s.twoFactorRepo.CreateChallenge(ctx, &models.TwoFactorChallenge{
+		UserID:    userID,
+		TokenHash: hashChallengeToken(token),
+		ExpiredAt: time.Now().Add(challengeValidFor),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return token, challengeValidFor, nil
+}
+
+// ChallengeUserID finds who a challenge that can still be answered is for.
+func (s *TOTPService) ChallengeUserID(ctx context.Context, token string) (uint, error) {
+	challenge, err := s.twoFactorRepo.GetChallenge(ctx, hashChallengeToken(token), challengeMaxAttempts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrChallengeInvalid
+	}
+	return challenge.UserID, err
+}
+
+// AnswerChallenge finishes a login with a code, returning who logged in. Challenges expire
+// after a few minutes or a few wrong codes.
+func (s *TOTPService) AnswerChallenge(ctx context.Context, token string, code string) (uint, error) {
+	challenge, err := s.twoFactorRepo.AttemptChallenge(ctx, hashChallengeToken(token), challengeMaxAttempts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrChallengeInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.VerifyCode(ctx, challenge.UserID, code); err != nil {
+		return 0, err
+	}
+
+	rows, err := s.twoFactorRepo.UseChallenge(ctx, challenge.ID)
+	if err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return 0, ErrChallengeInvalid
+	}
+	return challenge.UserID, nil
+}
+
+// newRecoveryCodes generates recovery codes like abcd-efgh-ijkl-mnop, along with their hashes.
+func (s *TOTPService) newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw, err := s.random.GenerateSecretKey(10)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		encoded := strings.ToLower(totpEncoding.EncodeToString(raw))
+		code := strings.Join([]string{encoded[0:4], encoded[4:8], encoded[8:12], encoded[12:16]}, "-")
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// TOTPCode computes the code of a base32 secret at a point in time.
+func TOTPCode(secret string, at time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(at.Unix()/totpPeriod)), nil
+}
+
+// TOTPURI is the otpauth URI authenticator apps read from a QR code.
+func TOTPURI(secret string, account string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {totpIssuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// HashRecoveryCode hashes a recovery code, ignoring case, dashes and spaces.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hashed := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hashed[:])
+}
+
+func hashChallengeToken(token string) string {
+	hashed := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashed[:])
+}
+
+// matchTOTP finds the step a code is from, ignoring steps up to and including lastStep.
+func matchTOTP(secret string, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(step))), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// hotp is the HOTP value of a counter (RFC 4226).
+func hotp(key []byte, counter uint64) string {
+	mac := hmac.New(sha1.New, key)
+	_ = binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// From RFC 6238, appendix B, with the ASCII secret 12345678901234567890 and the last 6 digits.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := services.TOTPCode(secret, time.Unix(unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code, "at %d", unix)
+	}
+
+	t.Run("LowercaseSecret", func(t *testing.T) {
+		code, err := services.TOTPCode(strings.ToLower(secret), time.Unix(59, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("InvalidSecret", func(t *testing.T) {
+		_, err := services.TOTPCode("not base32!", time.Now())
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(services.TOTPURI("JBSWY3DPEHPK3PXP", "nguyet@example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Cherry Auctions:nguyet@example.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "Cherry Auctions", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hashed := services.HashRecoveryCode("abcd-efgh-ijkl-mnop")
+	assert.Len(t, hashed, 64)
+	assert.Equal(t, hashed, services.HashRecoveryCode("ABCD EFGH IJKL MNOP"))
+	assert.Equal(t, hashed, services.HashRecoveryCode("abcdefghijklmnop"))
+	assert.NotEqual(t, hashed, services.HashRecoveryCode("abcd-efgh-ijkl-mnoq"))
+}
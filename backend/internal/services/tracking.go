@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+type TrackingStatus string
+
+const (
+	TrackingStatusUnknown        TrackingStatus = "unknown"
+	TrackingStatusLabelCreated   TrackingStatus = "label_created"
+	TrackingStatusInTransit      TrackingStatus = "in_transit"
+	TrackingStatusOutForDelivery TrackingStatus = "out_for_delivery"
+	TrackingStatusDelivered      TrackingStatus = "delivered"
+	TrackingStatusException      TrackingStatus = "exception"
+)
+
+var ErrTrackingNotFound = errors.New("tracking number not found")
+
+// TrackingProvider looks up the shipment status of a parcel from a carrier.
+// Implementations should be safe for concurrent use.
+type TrackingProvider interface {
+	GetTrackingStatus(ctx context.Context, carrier string, trackingNumber string) (TrackingStatus, error)
+}
+
+// NoopTrackingProvider doesn't talk to any carrier, and reports every parcel as unknown.
+// This is what runs until a real carrier integration is configured.
+type NoopTrackingProvider struct{}
+
+func (p *NoopTrackingProvider) GetTrackingStatus(ctx context.Context, carrier string, trackingNumber string) (TrackingStatus, error) {
+	return TrackingStatusUnknown, nil
+}
+
+// FakeTrackingProvider is an in-memory provider for tests and local development.
+// Statuses are keyed by carrier and tracking number, case-insensitive on the carrier.
+type FakeTrackingProvider struct {
+	mu       sync.RWMutex
+	statuses map[string]TrackingStatus
+}
+
+func NewFakeTrackingProvider() *FakeTrackingProvider {
+	return &FakeTrackingProvider{
+		statuses: make(map[string]TrackingStatus),
+	}
+}
+
+func (p *FakeTrackingProvider) key(carrier string, trackingNumber string) string {
+	return strings.ToLower(carrier) + "/" + trackingNumber
+}
+
+// SetStatus sets the status that will be reported for a parcel.
+func (p *FakeTrackingProvider) SetStatus(carrier string, trackingNumber string, status TrackingStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statuses[p.key(carrier, trackingNumber)] = status
+}
+
+func (p *FakeTrackingProvider) GetTrackingStatus(ctx context.Context, carrier string, trackingNumber string) (TrackingStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status, ok := p.statuses[p.key(carrier, trackingNumber)]
+	if !ok {
+		return TrackingStatusUnknown, ErrTrackingNotFound
+	}
+	return status, nil
+}
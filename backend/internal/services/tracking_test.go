@@ -0,0 +1,37 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+func TestTrackingProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("NoopReportsUnknown", func(t *testing.T) {
+		var provider services.TrackingProvider = &services.NoopTrackingProvider{}
+		status, err := provider.GetTrackingStatus(ctx, "ups", "1Z999")
+		assert.Nil(t, err)
+		assert.Equal(t, services.TrackingStatusUnknown, status)
+	})
+
+	t.Run("FakeReturnsSetStatus", func(t *testing.T) {
+		fake := services.NewFakeTrackingProvider()
+		fake.SetStatus("UPS", "1Z999", services.TrackingStatusInTransit)
+
+		var provider services.TrackingProvider = fake
+		status, err := provider.GetTrackingStatus(ctx, "ups", "1Z999")
+		assert.Nil(t, err)
+		assert.Equal(t, services.TrackingStatusInTransit, status)
+	})
+
+	t.Run("FakeUnknownParcel", func(t *testing.T) {
+		fake := services.NewFakeTrackingProvider()
+		status, err := fake.GetTrackingStatus(ctx, "dhl", "nope")
+		assert.ErrorIs(t, err, services.ErrTrackingNotFound)
+		assert.Equal(t, services.TrackingStatusUnknown, status)
+	})
+}
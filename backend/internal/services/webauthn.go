@@ -0,0 +1,345 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	ErrWebAuthnInvalidResponse = errors.New("invalid webauthn response")
+	ErrWebAuthnUnsupportedKey  = errors.New("unsupported webauthn public key")
+	ErrWebAuthnBadSignature    = errors.New("webauthn signature doesn't match")
+	ErrWebAuthnSignCount       = errors.New("webauthn sign count went backwards, the authenticator might be cloned")
+)
+
+// COSE algorithms that credentials can use, in order of preference.
+const (
+	COSEAlgEdDSA = -8
+	COSEAlgES256 = -7
+	COSEAlgRS256 = -257
+)
+
+// Flags of the authenticator data.
+const (
+	authDataUserPresent  = 0x01
+	authDataUserVerified = 0x04
+	authDataAttested     = 0x40
+)
+
+// WebAuthnRelyingParty verifies the responses of WebAuthn ceremonies (https://www.w3.org/TR/webauthn-3/).
+// It doesn't store anything, challenges and credentials are kept by the caller. Attestation isn't
+// asked for, so only the "none" format is accepted, and users always have to be verified since
+// passkeys replace the password.
+type WebAuthnRelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// WebAuthnClientData is the client data signed along with every response.
+type WebAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// WebAuthnRegistration is the response of navigator.credentials.create, with binary fields
+// base64url encoded.
+type WebAuthnRegistration struct {
+	ID       string `json:"id" binding:"required"`
+	RawID    string `json:"rawId" binding:"required"`
+	Type     string `json:"type" binding:"required"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AttestationObject string `json:"attestationObject" binding:"required"`
+	} `json:"response" binding:"required"`
+}
+
+// WebAuthnAssertion is the response of navigator.credentials.get, with binary fields base64url
+// encoded.
+type WebAuthnAssertion struct {
+	ID       string `json:"id" binding:"required"`
+	RawID    string `json:"rawId" binding:"required"`
+	Type     string `json:"type" binding:"required"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AuthenticatorData string `json:"authenticatorData" binding:"required"`
+		Signature         string `json:"signature" binding:"required"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response" binding:"required"`
+}
+
+// WebAuthnVerifiedCredential is a new credential that passed verification.
+type WebAuthnVerifiedCredential struct {
+	ID        string // base64url
+	PublicKey []byte // PKIX DER
+	Algorithm int
+	SignCount uint32
+	AAGUID    []byte
+}
+
+type webAuthnAttestation struct {
+	Fmt      string         `codec:"fmt"`
+	AttStmt  map[string]any `codec:"attStmt"`
+	AuthData []byte         `codec:"authData"`
+}
+
+type webAuthnAuthData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	// Only when attested.
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// SupportedAlgorithms lists the COSE algorithms for the creation options.
+func (rp *WebAuthnRelyingParty) SupportedAlgorithms() []int {
+	return []int{COSEAlgEdDSA, COSEAlgES256, COSEAlgRS256}
+}
+
+// ParseClientData decodes the client data, to find the challenge a response is for.
+func ParseClientData(clientDataJSON string) (*WebAuthnClientData, []byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(clientDataJSON)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: client data isn't base64url", ErrWebAuthnInvalidResponse)
+	}
+
+	var clientData WebAuthnClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return nil, nil, fmt.Errorf("%w: client data isn't json", ErrWebAuthnInvalidResponse)
+	}
+	return &clientData, raw, nil
+}
+
+// VerifyRegistration checks a registration response against the challenge it was created with.
+func (rp *WebAuthnRelyingParty) VerifyRegistration(response WebAuthnRegistration, challenge string) (*WebAuthnVerifiedCredential, error) {
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("%w: type is %s", ErrWebAuthnInvalidResponse, response.Type)
+	}
+	if _, _, err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := base64.RawURLEncoding.DecodeString(response.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation object isn't base64url", ErrWebAuthnInvalidResponse)
+	}
+	var attestation webAuthnAttestation
+	if err := codec.NewDecoderBytes(rawAttestation, webAuthnCBOR).Decode(&attestation); err != nil {
+		return nil, fmt.Errorf("%w: attestation object isn't cbor", ErrWebAuthnInvalidResponse)
+	}
+	if attestation.Fmt != "none" {
+		return nil, fmt.Errorf("%w: attestation format %s wasn't asked for", ErrWebAuthnInvalidResponse, attestation.Fmt)
+	}
+
+	authData, err := rp.verifyAuthData(attestation.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.flags&authDataAttested == 0 {
+		return nil, fmt.Errorf("%w: no attested credential", ErrWebAuthnInvalidResponse)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.credentialID)
+	if credentialID != response.RawID {
+		return nil, fmt.Errorf("%w: credential id doesn't match", ErrWebAuthnInvalidResponse)
+	}
+
+	publicKey, algorithm, err := parseCOSEKey(authData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnVerifiedCredential{
+		ID:        credentialID,
+		PublicKey: der,
+		Algorithm: algorithm,
+		SignCount: authData.signCount,
+		AAGUID:    authData.aaguid,
+	}, nil
+}
+
+// VerifyAssertion checks an assertion against the challenge it was requested with and the
+// credential it claims to be from, returning the new sign count to store.
+func (rp *WebAuthnRelyingParty) VerifyAssertion(response WebAuthnAssertion, challenge string, publicKey []byte, algorithm int, signCount uint32) (uint32, error) {
+	if response.Type != "public-key" {
+		return 0, fmt.Errorf("%w: type is %s", ErrWebAuthnInvalidResponse, response.Type)
+	}
+	_, rawClientData, err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := base64.RawURLEncoding.DecodeString(response.Response.AuthenticatorData)
+	if err != nil {
+		return 0, fmt.Errorf("%w: authenticator data isn't base64url", ErrWebAuthnInvalidResponse)
+	}
+	authData, err := rp.verifyAuthData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(response.Response.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("%w: signature isn't base64url", ErrWebAuthnInvalidResponse)
+	}
+	key, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(slices.Clone(rawAuthData), clientDataHash[:]...)
+	if !verifyWebAuthnSignature(key, algorithm, signed, signature) {
+		return 0, ErrWebAuthnBadSignature
+	}
+
+	// Authenticators that don't count always send 0, the rest have to go up every time.
+	if (authData.signCount != 0 || signCount != 0) && authData.signCount <= signCount {
+		return 0, ErrWebAuthnSignCount
+	}
+	return authData.signCount, nil
+}
+
+func (rp *WebAuthnRelyingParty) verifyClientData(clientDataJSON string, ceremony string, challenge string) (*WebAuthnClientData, []byte, error) {
+	clientData, raw, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if clientData.Type != ceremony {
+		return nil, nil, fmt.Errorf("%w: client data is for %s", ErrWebAuthnInvalidResponse, clientData.Type)
+	}
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(challenge)) != 1 {
+		return nil, nil, fmt.Errorf("%w: challenge doesn't match", ErrWebAuthnInvalidResponse)
+	}
+	if !slices.Contains(rp.Origins, clientData.Origin) {
+		return nil, nil, fmt.Errorf("%w: origin %s isn't allowed", ErrWebAuthnInvalidResponse, clientData.Origin)
+	}
+	return clientData, raw, nil
+}
+
+// verifyAuthData parses the authenticator data, which has to be for this relying party with the
+// user present and verified.
+func (rp *WebAuthnRelyingParty) verifyAuthData(raw []byte) (*webAuthnAuthData, error) {
+	if len(raw) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data is too short", ErrWebAuthnInvalidResponse)
+	}
+
+	authData := &webAuthnAuthData{
+		rpIDHash:  raw[0:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return nil, fmt.Errorf("%w: authenticator data is for another relying party", ErrWebAuthnInvalidResponse)
+	}
+	if authData.flags&authDataUserPresent == 0 || authData.flags&authDataUserVerified == 0 {
+		return nil, fmt.Errorf("%w: user wasn't present and verified", ErrWebAuthnInvalidResponse)
+	}
+
+	if authData.flags&authDataAttested != 0 {
+		if len(raw) < 55 {
+			return nil, fmt.Errorf("%w: attested credential data is too short", ErrWebAuthnInvalidResponse)
+		}
+		authData.aaguid = raw[37:53]
+		length := int(binary.BigEndian.Uint16(raw[53:55]))
+		if len(raw) < 55+length {
+			return nil, fmt.Errorf("%w: credential id is too short", ErrWebAuthnInvalidResponse)
+		}
+		authData.credentialID = raw[55 : 55+length]
+		authData.publicKey = raw[55+length:]
+	}
+	return authData, nil
+}
+
+// parseCOSEKey parses a COSE_Key (RFC 9053), followed by anything.
+func parseCOSEKey(raw []byte) (crypto.PublicKey, int, error) {
+	var key map[int]any
+	if err := codec.NewDecoderBytes(raw, webAuthnCBOR).Decode(&key); err != nil {
+		return nil, 0, fmt.Errorf("%w: public key isn't cbor", ErrWebAuthnInvalidResponse)
+	}
+
+	kty, _ := key[1].(int64)
+	alg, _ := key[3].(int64)
+	switch {
+	case kty == 1 && alg == COSEAlgEdDSA:
+		crv, _ := key[-1].(int64)
+		x, _ := key[-2].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		return ed25519.PublicKey(x), COSEAlgEdDSA, nil
+
+	case kty == 2 && alg == COSEAlgES256:
+		crv, _ := key[-1].(int64)
+		x, _ := key[-2].([]byte)
+		y, _ := key[-3].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+
+		// Makes sure the point is on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, COSEAlgES256, nil
+
+	case kty == 3 && alg == COSEAlgRS256:
+		n, _ := key[-1].([]byte)
+		e, _ := key[-2].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, COSEAlgRS256, nil
+	}
+	return nil, 0, ErrWebAuthnUnsupportedKey
+}
+
+func verifyWebAuthnSignature(key crypto.PublicKey, algorithm int, signed []byte, signature []byte) bool {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return algorithm == COSEAlgEdDSA && ed25519.Verify(k, signed, signature)
+	case *ecdsa.PublicKey:
+		hashed := sha256.Sum256(signed)
+		return algorithm == COSEAlgES256 && ecdsa.VerifyASN1(k, hashed[:], signature)
+	case *rsa.PublicKey:
+		hashed := sha256.Sum256(signed)
+		return algorithm == COSEAlgRS256 && rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], signature) == nil
+	}
+	return false
+}
+
+// webAuthnCBOR decodes CBOR with signed integers, since COSE keys mix positive and negative ones.
+var webAuthnCBOR = func() *codec.CborHandle {
+	h := &codec.CborHandle{}
+	h.SignedInteger = true
+	return h
+}()
@@ -0,0 +1,243 @@
+package services_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"luny.dev/cherryauctions/internal/services"
+)
+
+// softAuthenticator is a software passkey that answers ceremonies like a browser would.
+type softAuthenticator struct {
+	rpID         string
+	origin       string
+	credentialID []byte
+	signer       crypto.Signer
+	signCount    uint32
+	counts       bool
+	flags        byte
+}
+
+func newSoftAuthenticator(t *testing.T, algorithm int) *softAuthenticator {
+	t.Helper()
+
+	var signer crypto.Signer
+	var err error
+	switch algorithm {
+	case services.COSEAlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case services.COSEAlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	assert.Nil(t, err)
+
+	credentialID := make([]byte, 16)
+	_, _ = rand.Read(credentialID)
+	return &softAuthenticator{
+		rpID:         "localhost",
+		origin:       "http://localhost:5173",
+		credentialID: credentialID,
+		signer:       signer,
+		counts:       true,
+		flags:        0x01 | 0x04, // User present and verified.
+	}
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"type": ceremony, "challenge": challenge, "origin": a.origin, "crossOrigin": false})
+	assert.Nil(t, err)
+	return raw
+}
+
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) coseKey(t *testing.T) []byte {
+	t.Helper()
+
+	var key map[int]any
+	switch public := a.signer.Public().(type) {
+	case ed25519.PublicKey:
+		key = map[int]any{1: 1, 3: services.COSEAlgEdDSA, -1: 6, -2: []byte(public)}
+	case *ecdsa.PublicKey:
+		key = map[int]any{1: 2, 3: services.COSEAlgES256, -1: 1, -2: public.X.FillBytes(make([]byte, 32)), -3: public.Y.FillBytes(make([]byte, 32))}
+	}
+	return encodeCBOR(t, key)
+}
+
+func (a *softAuthenticator) register(t *testing.T, challenge string) services.WebAuthnRegistration {
+	t.Helper()
+
+	authData := a.authData(a.flags | 0x40)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, a.coseKey(t)...)
+
+	attestation := encodeCBOR(t, map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+
+	var response services.WebAuthnRegistration
+	response.ID = base64.RawURLEncoding.EncodeToString(a.credentialID)
+	response.RawID = response.ID
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(a.clientData(t, "webauthn.create", challenge))
+	response.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(attestation)
+	return response
+}
+
+func (a *softAuthenticator) assert(t *testing.T, challenge string) services.WebAuthnAssertion {
+	t.Helper()
+
+	if a.counts {
+		a.signCount++
+	}
+	authData := a.authData(a.flags)
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	signed := append(authData, clientDataHash[:]...)
+
+	var signature []byte
+	var err error
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		signature, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		hashed := sha256.Sum256(signed)
+		signature, err = a.signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	}
+	assert.Nil(t, err)
+
+	var response services.WebAuthnAssertion
+	response.ID = base64.RawURLEncoding.EncodeToString(a.credentialID)
+	response.RawID = response.ID
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientData)
+	response.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	response.Response.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	response.Response.UserHandle = services.PasskeyUserHandle(1)
+	return response
+}
+
+func encodeCBOR(t *testing.T, value any) []byte {
+	t.Helper()
+	var out []byte
+	assert.Nil(t, codec.NewEncoderBytes(&out, &codec.CborHandle{}).Encode(value))
+	return out
+}
+
+func TestWebAuthnCeremonies(t *testing.T) {
+	rp := &services.WebAuthnRelyingParty{ID: "localhost", Name: "Cherry Auctions", Origins: []string{"http://localhost:5173"}}
+
+	for name, algorithm := range map[string]int{"ES256": services.COSEAlgES256, "EdDSA": services.COSEAlgEdDSA} {
+		t.Run(name, func(t *testing.T) {
+			authenticator := newSoftAuthenticator(t, algorithm)
+
+			credential, err := rp.VerifyRegistration(authenticator.register(t, "register-challenge"), "register-challenge")
+			assert.Nil(t, err)
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(authenticator.credentialID), credential.ID)
+			assert.Equal(t, algorithm, credential.Algorithm)
+			assert.Equal(t, uint32(0), credential.SignCount)
+
+			signCount, err := rp.VerifyAssertion(authenticator.assert(t, "login-challenge"), "login-challenge", credential.PublicKey, credential.Algorithm, credential.SignCount)
+			assert.Nil(t, err)
+			assert.Equal(t, uint32(1), signCount)
+
+			signCount, err = rp.VerifyAssertion(authenticator.assert(t, "again"), "again", credential.PublicKey, credential.Algorithm, signCount)
+			assert.Nil(t, err)
+			assert.Equal(t, uint32(2), signCount)
+		})
+	}
+
+	authenticator := newSoftAuthenticator(t, services.COSEAlgES256)
+	credential, err := rp.VerifyRegistration(authenticator.register(t, "challenge"), "challenge")
+	assert.Nil(t, err)
+
+	t.Run("WrongChallenge", func(t *testing.T) {
+		_, err := rp.VerifyRegistration(authenticator.register(t, "challenge"), "other")
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+
+		_, err = rp.VerifyAssertion(authenticator.assert(t, "challenge"), "other", credential.PublicKey, credential.Algorithm, 0)
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+
+	t.Run("WrongCeremony", func(t *testing.T) {
+		registration := authenticator.register(t, "challenge")
+		registration.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(authenticator.clientData(t, "webauthn.get", "challenge"))
+		_, err := rp.VerifyRegistration(registration, "challenge")
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+
+	t.Run("WrongOrigin", func(t *testing.T) {
+		phishing := *authenticator
+		phishing.origin = "https://cherry-auctions.example"
+		_, err := rp.VerifyAssertion(phishing.assert(t, "challenge"), "challenge", credential.PublicKey, credential.Algorithm, 0)
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+
+	t.Run("WrongRelyingParty", func(t *testing.T) {
+		other := *authenticator
+		other.rpID = "example.com"
+		_, err := rp.VerifyRegistration(other.register(t, "challenge"), "challenge")
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+
+	t.Run("UserNotVerified", func(t *testing.T) {
+		unverified := *authenticator
+		unverified.flags = 0x01
+		_, err := rp.VerifyAssertion(unverified.assert(t, "challenge"), "challenge", credential.PublicKey, credential.Algorithm, 0)
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+
+	t.Run("BadSignature", func(t *testing.T) {
+		assertion := authenticator.assert(t, "challenge")
+		signature, _ := base64.RawURLEncoding.DecodeString(assertion.Response.Signature)
+		signature[len(signature)-1] ^= 0xff
+		assertion.Response.Signature = base64.RawURLEncoding.EncodeToString(signature)
+		_, err := rp.VerifyAssertion(assertion, "challenge", credential.PublicKey, credential.Algorithm, 0)
+		assert.ErrorIs(t, err, services.ErrWebAuthnBadSignature)
+
+		imposter := newSoftAuthenticator(t, services.COSEAlgES256)
+		_, err = rp.VerifyAssertion(imposter.assert(t, "challenge"), "challenge", credential.PublicKey, credential.Algorithm, 0)
+		assert.ErrorIs(t, err, services.ErrWebAuthnBadSignature)
+	})
+
+	t.Run("SignCount", func(t *testing.T) {
+		// A clone is behind the counter the server saw last.
+		_, err := rp.VerifyAssertion(authenticator.assert(t, "challenge"), "challenge", credential.PublicKey, credential.Algorithm, 100)
+		assert.ErrorIs(t, err, services.ErrWebAuthnSignCount)
+
+		// Authenticators that never count are fine.
+		counterless := *authenticator
+		counterless.signCount = 0
+		counterless.counts = false
+		signCount, err := rp.VerifyAssertion(counterless.assert(t, "challenge"), "challenge", credential.PublicKey, credential.Algorithm, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(0), signCount)
+	})
+
+	t.Run("UnsupportedAttestation", func(t *testing.T) {
+		registration := authenticator.register(t, "challenge")
+		authData := authenticator.authData(authenticator.flags)
+		registration.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(encodeCBOR(t, map[string]any{"fmt": "packed", "attStmt": map[string]any{}, "authData": authData}))
+		_, err := rp.VerifyRegistration(registration, "challenge")
+		assert.ErrorIs(t, err, services.ErrWebAuthnInvalidResponse)
+	})
+}
+
+func TestPasskeyUserHandle(t *testing.T) {
+	assert.Equal(t, "AAAAAAAAAAE", services.PasskeyUserHandle(1))
+	assert.NotEqual(t, services.PasskeyUserHandle(1), services.PasskeyUserHandle(2))
+}
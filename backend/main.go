@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/davidbyttow/govips/v2/vips"
@@ -59,16 +60,82 @@ func main() {
 	chatSessionRepo := repositories.NewChatSessionRepository(db)
 	ratingRepo := repositories.NewRatingRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db, productRepo, ratingRepo)
+	paymentRepo := repositories.NewPaymentRepository(db)
+	disputeRepo := repositories.NewDisputeRepository(db)
+	payoutRepo := repositories.NewPayoutRepository(db)
+	ratingReportRepo := repositories.NewRatingReportRepository(db)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	webAuthnRepo := repositories.NewWebAuthnRepository(db)
+	authThrottleRepo := repositories.NewAuthThrottleRepository(db)
+	emailChangeRepo := repositories.NewEmailChangeRepository(db)
+	sellerRequestRepo := repositories.NewSellerRequestRepository(db)
+	subscriptionRepo := repositories.NewSubscriptionRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
 
 	// Setup services here
-	jwtService := &services.JWTService{JWTDomain: cfg.Domain, JWTAudience: cfg.JWT.Audience, JWTSecretKey: cfg.JWT.Secret, JWTExpiry: cfg.JWT.Expiry}
+	jwtKeys, err := services.LoadJWTKeys(cfg.JWT.Keys)
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	}
+	jwtService := &services.JWTService{JWTDomain: cfg.Domain, JWTAudience: cfg.JWT.Audience, JWTSecretKey: cfg.JWT.Secret, JWTExpiry: cfg.JWT.Expiry, JWTKeys: jwtKeys}
 	randomService := &services.RandomService{}
 	passwordService := &services.PasswordService{RandomService: randomService}
-	captchaService := &services.CaptchaService{RecaptchaSecret: cfg.RecaptchaSecret}
-	middlewareService := &services.MiddlewareService{JWTService: jwtService}
+	captchaVerifier, err := services.NewCaptchaVerifier(cfg.Captcha.Provider, cfg.Captcha.Secret)
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	}
+	captchaRules, err := services.ParseCaptchaRules(cfg.Captcha.Rules)
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	}
+	captchaService := services.NewCaptchaService(captchaVerifier, captchaRules)
+	tokenStateService := services.NewTokenStateService(userRepo, refreshTokenRepo, 30*time.Second)
+	permissionService := services.NewPermissionService(roleRepo, 30*time.Second)
+	middlewareService := &services.MiddlewareService{JWTService: jwtService, TokenStateService: tokenStateService, PermissionService: permissionService}
 	s3Service := services.NewS3Service(cfg.AWS.BucketName, s3Client)
 	mailerService := services.NewMailerService(cfg, mailDialer, productRepo, questionRepo, userRepo)
 	otpService := services.NewOTPService(mailerService, userRepo)
+	passwordResetService := services.NewPasswordResetService(mailerService, randomService, passwordService, passwordResetRepo, userRepo)
+	emailChangeService := services.NewEmailChangeService(mailerService, randomService, passwordService, emailChangeRepo, userRepo)
+	totpService := services.NewTOTPService(randomService, userRepo, twoFactorRepo)
+	relyingParty := &services.WebAuthnRelyingParty{ID: cfg.WebAuthn.RPID, Name: cfg.WebAuthn.RPName, Origins: strings.Split(cfg.WebAuthn.Origins, ",")}
+	passkeyService := services.NewPasskeyService(relyingParty, randomService, webAuthnRepo)
+	throttleService := services.NewThrottleService(authThrottleRepo)
+	auditService := services.NewAuditService(auditLogRepo)
+
+	var googleProvider services.IdentityProvider
+	if cfg.Google.ClientID != "" {
+		googleProvider = services.NewOIDCProvider(cfg.Google.Issuer, cfg.Google.ClientID, cfg.Google.ClientSecret, cfg.Google.RedirectURL, nil)
+	}
+	trackingProvider := &services.NoopTrackingProvider{}
+
+	var paymentProvider services.PaymentProvider
+	switch cfg.Payment.Provider {
+	case "none":
+		log.Println("payments are disabled, transactions are paid outside of the platform")
+	case "mock":
+		if !cfg.DevMode {
+			log.Fatalf("fatal: the mock payment provider needs DEV_MODE")
+		}
+		paymentProvider = services.NewMockPaymentProvider(cfg.Payment.WebhookSecret, cfg.Payment.CheckoutBaseURL, randomService)
+	default:
+		log.Fatalf("fatal: unsupported payment provider %s", cfg.Payment.Provider)
+	}
+	paymentService := services.NewPaymentService(paymentProvider, paymentRepo)
+
+	feeSchedule, err := services.ParseFeeSchedule(cfg.FeeSchedule)
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	}
+	feeService := services.NewFeeService(feeSchedule, payoutRepo)
+	invoiceService := services.NewInvoiceService(s3Service, mailerService, randomService, transactionRepo, payoutRepo)
+	subscriptionPlans, err := services.ParseSubscriptionPlans(cfg.Subscriptions.Plans)
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	}
+	subscriptionService := services.NewSubscriptionService(subscriptionPlans, time.Duration(cfg.Subscriptions.ReminderDays)*24*time.Hour, subscriptionRepo, productRepo, mailerService)
+	deadlineService := services.NewDeadlineService(services.NewTransactionDeadlines(cfg), transactionRepo, mailerService, feeService)
 
 	// Weird to do this even in production.
 	infra.MigrateModels(db)
@@ -83,25 +150,51 @@ func main() {
 		MailDialer: mailDialer,
 		Config:     cfg,
 		Services: services.ServiceRegistry{
-			JWTService:        jwtService,
-			RandomService:     randomService,
-			PasswordService:   passwordService,
-			CaptchaService:    captchaService,
-			MiddlewareService: middlewareService,
-			S3Service:         s3Service,
-			MailerService:     mailerService,
-			OTPService:        otpService,
+			JWTService:           jwtService,
+			RandomService:        randomService,
+			PasswordService:      passwordService,
+			CaptchaService:       captchaService,
+			MiddlewareService:    middlewareService,
+			S3Service:            s3Service,
+			MailerService:        mailerService,
+			OTPService:           otpService,
+			TrackingProvider:     trackingProvider,
+			PaymentService:       paymentService,
+			FeeService:           feeService,
+			InvoiceService:       invoiceService,
+			PasswordResetService: passwordResetService,
+			EmailChangeService:   emailChangeService,
+			TokenStateService:    tokenStateService,
+			PermissionService:    permissionService,
+			TOTPService:          totpService,
+			PasskeyService:       passkeyService,
+			ThrottleService:      throttleService,
+			SubscriptionService:  subscriptionService,
+			AuditService:         auditService,
+			GoogleProvider:       googleProvider,
 		},
 		Repositories: repositories.RepositoryRegistry{
-			CategoryRepository:     categoryRepo,
-			UserRepository:         userRepo,
-			RoleRepository:         roleRepo,
-			RefreshTokenRepository: refreshTokenRepo,
-			ProductRepository:      productRepo,
-			QuestionRepository:     questionRepo,
-			ChatSessionRepository:  chatSessionRepo,
-			TransactionRepository:  transactionRepo,
-			RatingRepostory:        ratingRepo,
+			CategoryRepository:      categoryRepo,
+			UserRepository:          userRepo,
+			RoleRepository:          roleRepo,
+			RefreshTokenRepository:  refreshTokenRepo,
+			ProductRepository:       productRepo,
+			QuestionRepository:      questionRepo,
+			ChatSessionRepository:   chatSessionRepo,
+			TransactionRepository:   transactionRepo,
+			RatingRepostory:         ratingRepo,
+			PaymentRepository:       paymentRepo,
+			DisputeRepository:       disputeRepo,
+			PayoutRepository:        payoutRepo,
+			RatingReportRepository:  ratingReportRepo,
+			PasswordResetRepository: passwordResetRepo,
+			TwoFactorRepository:     twoFactorRepo,
+			WebAuthnRepository:      webAuthnRepo,
+			AuthThrottleRepository:  authThrottleRepo,
+			EmailChangeRepository:   emailChangeRepo,
+			SellerRequestRepository: sellerRequestRepo,
+			SubscriptionRepository:  subscriptionRepo,
+			AuditLogRepository:      auditLogRepo,
 		},
 	})
 
@@ -120,6 +213,37 @@ func main() {
 	if err != nil {
 		log.Fatalf("can't setup a cron job: %v", err)
 	}
+
+	_, err = scheduler.NewJob(gocron.DurationJob(10*time.Minute), gocron.NewTask(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		deadlineService.EnforceDeadlines(ctx)
+
+		// Catch up on anything that completed without getting settled.
+		feeService.SettleCompletedTransactions(ctx)
+		if paymentService.Enabled() {
+			paymentService.RefundCancelledTransactions(ctx)
+		}
+		invoiceService.SendPendingInvoices(ctx)
+		subscriptionService.SendExpiryReminders(ctx)
+
+		_, err := twoFactorRepo.DeleteStaleChallenges(ctx, time.Now().Add(-1*time.Hour))
+		if err != nil {
+			fmt.Printf("warning: unable to delete stale login challenges: %v\n", err)
+		}
+		_, err = webAuthnRepo.DeleteStaleChallenges(ctx, time.Now().Add(-1*time.Hour))
+		if err != nil {
+			fmt.Printf("warning: unable to delete stale passkey challenges: %v\n", err)
+		}
+		_, err = authThrottleRepo.DeleteStaleThrottles(ctx, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			fmt.Printf("warning: unable to delete stale login throttles: %v\n", err)
+		}
+	}), gocron.WithSingletonMode(gocron.LimitModeReschedule))
+	if err != nil {
+		log.Fatalf("can't setup a cron job: %v", err)
+	}
 	scheduler.Start()
 	defer func() {
 		err := scheduler.Shutdown()
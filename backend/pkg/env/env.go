@@ -20,6 +20,23 @@ func Getenv(key string, def string) string {
 	return val
 }
 
+// GetenvInt retrieves an environment value as an integer and uses a default value if not available.
+// It still kills itself if the value exists but is not a valid number.
+func GetenvInt(key string, def int64) int64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		fmt.Printf("warning: unable to find environment variable for key = %s\n", key)
+		return def
+	}
+
+	parsed, err := strconv.ParseInt(val, 10, 0)
+	if err != nil {
+		log.Fatalf("fatal: found env variable for %s but is not an integer: %s\n", key, val)
+	}
+
+	return parsed
+}
+
 // Fatalenv retrieves an environment value and kills itself if it doesn't exist.
 func Fatalenv(key string) string {
 	val, ok := os.LookupEnv(key)
@@ -41,6 +58,23 @@ func FatalenvInt(key string) int64 {
 	return parsed
 }
 
+// GetenvBool retrieves an environment value as a boolean and uses a default value if not available.
+// It still kills itself if the value exists but is not a valid boolean.
+func GetenvBool(key string, def bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		fmt.Printf("warning: unable to find environment variable for key = %s\n", key)
+		return def
+	}
+
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Fatalf("fatal: found env variable for %s but is not a boolean: %s\n", key, val)
+	}
+
+	return parsed
+}
+
 // FatalenvBool retrieves an environment value, kills itself if it doesn't exist OR if it is not a valid boolean.
 func FatalenvBool(key string) bool {
 	val := Fatalenv(key)
@@ -24,6 +24,24 @@ func TestGetenvInt(t *testing.T) {
 	assert.EqualValues(t, val, 1234)
 }
 
+func TestGetenvIntDefault(t *testing.T) {
+	val := env.GetenvInt("GO_TEST_ENV", 42)
+	assert.EqualValues(t, 42, val)
+
+	t.Setenv("GO_TEST_ENV", "7")
+	val = env.GetenvInt("GO_TEST_ENV", 42)
+	assert.EqualValues(t, 7, val)
+}
+
+func TestGetenvBoolDefault(t *testing.T) {
+	val := env.GetenvBool("GO_TEST_ENV", true)
+	assert.True(t, val)
+
+	t.Setenv("GO_TEST_ENV", "false")
+	val = env.GetenvBool("GO_TEST_ENV", true)
+	assert.False(t, val)
+}
+
 func TestGetenvBool(t *testing.T) {
 	t.Setenv("GO_TEST_ENV", "false")
 	val := env.FatalenvBool("GO_TEST_ENV")
@@ -0,0 +1,217 @@
+// Package pdf writes simple text documents as PDF, using only the standard Helvetica fonts
+// so nothing has to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Page sizes in points, a point being 1/72 of an inch.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// Document is a PDF document made of A4 pages. Coordinates start from the top left corner of a page.
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	doc := &Document{}
+	doc.AddPage()
+	return doc
+}
+
+// AddPage starts a new page, everything drawn afterwards goes on it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws a line of text with its baseline at y.
+func (d *Document) Text(x float64, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, A4Height-y, escape(Encode(s)))
+}
+
+// TextRight draws a line of text that ends at x.
+func (d *Document) TextRight(x float64, y float64, font Font, size float64, s string) {
+	d.Text(x-StringWidth(s, size), y, font, size, s)
+}
+
+// Line draws a thin line between two points.
+func (d *Document) Line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, A4Height-y1, x2, A4Height-y2)
+}
+
+// WriteTo writes the document out as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &bytes.Buffer{}
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 to 4 are fixed, then a page and its content for every page.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			A4Width, A4Height, 6+2*i,
+		))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// Bytes returns the document as a PDF file.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	_, _ = d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// Encode converts a string to the WinAnsi encoding of the standard fonts. Accents outside of
+// Latin-1 are dropped, so "Nguyệt" becomes "Nguyet", and anything else becomes a question mark.
+func Encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if b, ok := encodeRune(r); ok {
+			out = append(out, b)
+			continue
+		}
+
+		// Try again without the accents.
+		replaced := false
+		for _, base := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, base) {
+				continue
+			}
+			if b, ok := encodeRune(base); ok {
+				out = append(out, b)
+				replaced = true
+			}
+		}
+		if !replaced {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+func encodeRune(r rune) (byte, bool) {
+	switch {
+	case r == '\t':
+		return ' ', true
+	case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		// WinAnsi is the same as Latin-1 in these ranges.
+		return byte(r), true
+	case r == 'đ':
+		return 'd', true
+	case r == 'Đ':
+		return 'D', true
+	}
+	return 0, false
+}
+
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if c == '\\' || c == '(' || c == ')' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// Widths of printable ASCII in Helvetica, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// StringWidth estimates how wide a string is in Helvetica. Bold text is slightly wider,
+// except for digits and punctuation which have the same width.
+func StringWidth(s string, size float64) float64 {
+	total := 0
+	for _, c := range Encode(s) {
+		if c >= 0x20 && c < 0x7f {
+			total += helveticaWidths[c-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap splits text into lines that fit in a width, breaking between words.
+func Wrap(s string, size float64, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && StringWidth(candidate, size) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
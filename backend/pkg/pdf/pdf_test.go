@@ -0,0 +1,68 @@
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"luny.dev/cherryauctions/pkg/pdf"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ASCII", input: "Hello (world)", expected: "Hello (world)"},
+		{name: "Latin-1", input: "café", expected: "caf\xe9"},
+		{name: "Vietnamese", input: "Nguyệt Ánh đẹp", expected: "Nguyet \xc1nh dep"},
+		{name: "Unsupported", input: "日本", expected: "??"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []byte(tt.expected), pdf.Encode(tt.input))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := pdf.Wrap("the quick brown fox jumps over the lazy dog", 10, 80)
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, pdf.StringWidth(line, 10), 80.0)
+	}
+
+	assert.Equal(t, []string{"one", "two"}, pdf.Wrap("one\ntwo", 10, 500))
+}
+
+func TestDocument(t *testing.T) {
+	doc := pdf.New()
+	doc.Text(50, 50, pdf.HelveticaBold, 18, "Invoice (copy)")
+	doc.Line(50, 60, 545, 60)
+	doc.AddPage()
+	doc.TextRight(545, 50, pdf.Helvetica, 10, "$1,000.00")
+
+	out := doc.Bytes()
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+
+	t.Run("XrefOffsets", func(t *testing.T) {
+		match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+		assert.NotNil(t, match)
+		xref, _ := strconv.Atoi(string(match[1]))
+		assert.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+		assert.Len(t, entries, 8)
+		for i, entry := range entries {
+			offset, _ := strconv.Atoi(string(entry[1]))
+			assert.True(t, bytes.HasPrefix(out[offset:], fmt.Appendf(nil, "%d 0 obj\n", i+1)))
+		}
+	})
+}
@@ -9,6 +9,22 @@ export const endpoints = {
     logout: `${api}/v1/auth/logout`,
     verify: `${api}/v1/auth/verify`,
     verifyCheck: `${api}/v1/auth/verify/check`,
+    twoFactor: {
+      index: `${api}/v1/auth/2fa`,
+      login: `${api}/v1/auth/2fa/login`,
+      setup: `${api}/v1/auth/2fa/setup`,
+      confirm: `${api}/v1/auth/2fa/confirm`,
+      recoveryCodes: `${api}/v1/auth/2fa/recovery-codes`,
+      disable: `${api}/v1/auth/2fa/disable`,
+    },
+    passkeys: {
+      index: `${api}/v1/auth/passkeys`,
+      details: (id: unknown) => `${api}/v1/auth/passkeys/${id}`,
+      registerBegin: `${api}/v1/auth/passkeys/register/begin`,
+      registerFinish: `${api}/v1/auth/passkeys/register/finish`,
+      loginBegin: `${api}/v1/auth/passkeys/login/begin`,
+      loginFinish: `${api}/v1/auth/passkeys/login/finish`,
+    },
   },
   products: {
     get: `${api}/v1/products`,
@@ -34,17 +50,38 @@ export const endpoints = {
   users: {
     all: `${api}/v1/users`,
     request: `${api}/v1/users/request`,
-    approve: `${api}/v1/users/approve`,
+    requests: `${api}/v1/users/requests`,
+    approveRequest: (id: unknown) => `${api}/v1/users/requests/${id}/approve`,
+    rejectRequest: (id: unknown) => `${api}/v1/users/requests/${id}/reject`,
+    plans: `${api}/v1/users/subscriptions/plans`,
+    subscriptions: (id: unknown) => `${api}/v1/users/${id}/subscriptions`,
+    roles: (id: unknown) => `${api}/v1/users/${id}/roles`,
+    dossier: (id: unknown) => `${api}/v1/users/${id}/dossier`,
+    suspend: (id: unknown) => `${api}/v1/users/${id}/suspend`,
+    ban: (id: unknown) => `${api}/v1/users/${id}/ban`,
+    reinstate: (id: unknown) => `${api}/v1/users/${id}/reinstate`,
+    audit: `${api}/v1/users/audit`,
     avatar: `${api}/v1/users/avatar`,
     me: {
       index: `${api}/v1/users/me`,
       products: `${api}/v1/users/me/products`,
       bids: `${api}/v1/users/me/bids`,
       password: `${api}/v1/users/me/password`,
+      requests: `${api}/v1/users/me/requests`,
+      subscriptions: `${api}/v1/users/me/subscriptions`,
+      email: `${api}/v1/users/me/email`,
+      emailConfirm: `${api}/v1/users/me/email/confirm`,
       ratings: `${api}/v1/users/me/ratings`,
       rated: `${api}/v1/users/me/rated`,
+      sessions: `${api}/v1/users/me/sessions`,
+      session: (id: unknown) => `${api}/v1/users/me/sessions/${id}`,
     },
   },
+  roles: {
+    index: `${api}/v1/roles`,
+    permissions: `${api}/v1/roles/permissions`,
+    id: (id: unknown) => `${api}/v1/roles/${id}`,
+  },
   transactions: {
     index: `${api}/v1/transactions`,
     id: (id: unknown) => `${api}/v1/transactions/${id}`,
@@ -0,0 +1,67 @@
+import { useScript } from "./use-script";
+
+// The captcha provider the backend checks, from VITE_CAPTCHA_PROVIDER: recaptcha (v3),
+// hcaptcha, turnstile or none. hCaptcha and Turnstile run as invisible widgets.
+const provider = import.meta.env.VITE_CAPTCHA_PROVIDER || "recaptcha";
+const siteKey = import.meta.env.VITE_SITE_KEY;
+
+const scripts: Record<string, string> = {
+  recaptcha: `https://www.google.com/recaptcha/api.js?render=${siteKey}`,
+  hcaptcha: "https://js.hcaptcha.com/1/api.js?render=explicit",
+  turnstile: "https://challenges.cloudflare.com/turnstile/v0/api.js?render=explicit",
+};
+
+export function useCaptcha() {
+  if (scripts[provider]) {
+    useScript({ src: scripts[provider], defer: true, id: `${provider}-script` });
+  }
+
+  // Runs the captcha for an action, returning the token to send to the backend.
+  async function execute(action: string): Promise<string> {
+    switch (provider) {
+      case "recaptcha":
+        return grecaptcha.execute(siteKey, { action });
+
+      case "hcaptcha": {
+        const container = widgetContainer();
+        const widget = hcaptcha.render(container, { sitekey: siteKey, size: "invisible" });
+        try {
+          const { response } = await hcaptcha.execute(widget, { async: true });
+          return response;
+        } finally {
+          hcaptcha.remove(widget);
+          container.remove();
+        }
+      }
+
+      case "turnstile": {
+        const container = widgetContainer();
+        try {
+          return await new Promise<string>((resolve, reject) => {
+            turnstile.render(container, {
+              sitekey: siteKey,
+              action,
+              callback: resolve,
+              "error-callback": reject,
+            });
+          });
+        } finally {
+          container.remove();
+        }
+      }
+
+      default:
+        // The backend takes anything, or a fixed token in development.
+        return import.meta.env.VITE_CAPTCHA_TOKEN || "none";
+    }
+  }
+
+  return { execute };
+}
+
+function widgetContainer(): HTMLElement {
+  const container = document.createElement("div");
+  container.style.display = "none";
+  document.body.appendChild(container);
+  return container;
+}
@@ -0,0 +1,82 @@
+// Passkeys go through navigator.credentials, which takes and gives binary fields. The backend
+// sends and expects them base64url encoded instead.
+
+function fromBase64URL(value: string): ArrayBuffer {
+  const base64 = value.replace(/-/g, "+").replace(/_/g, "/");
+  const binary = atob(base64.padEnd(base64.length + ((4 - (base64.length % 4)) % 4), "="));
+  return Uint8Array.from(binary, (c) => c.charCodeAt(0)).buffer;
+}
+
+function toBase64URL(value: ArrayBuffer): string {
+  const binary = String.fromCharCode(...new Uint8Array(value));
+  return btoa(binary).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+}
+
+// Options from the backend, with binary fields base64url encoded.
+interface PasskeyCreationOptions extends Omit<
+  PublicKeyCredentialCreationOptions,
+  "challenge" | "user" | "excludeCredentials"
+> {
+  challenge: string;
+  user: { id: string; name: string; displayName: string };
+  excludeCredentials: { type: "public-key"; id: string }[];
+}
+
+interface PasskeyRequestOptions extends Omit<PublicKeyCredentialRequestOptions, "challenge"> {
+  challenge: string;
+}
+
+export function usePasskeys() {
+  const supported = typeof window !== "undefined" && "PublicKeyCredential" in window;
+
+  // Creates a passkey from the creation options of the backend, returning the credential to send back.
+  async function createPasskey(options: PasskeyCreationOptions) {
+    const credential = (await navigator.credentials.create({
+      publicKey: {
+        ...options,
+        challenge: fromBase64URL(options.challenge),
+        user: { ...options.user, id: fromBase64URL(options.user.id) },
+        excludeCredentials: options.excludeCredentials.map((c) => ({ ...c, id: fromBase64URL(c.id) })),
+      },
+    })) as PublicKeyCredential | null;
+    if (!credential) {
+      return null;
+    }
+
+    const response = credential.response as AuthenticatorAttestationResponse;
+    return {
+      id: credential.id,
+      rawId: toBase64URL(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: toBase64URL(response.clientDataJSON),
+        attestationObject: toBase64URL(response.attestationObject),
+      },
+    };
+  }
+
+  // Asks for any passkey with the request options of the backend, returning the credential to send back.
+  async function getPasskey(options: PasskeyRequestOptions) {
+    const credential = (await navigator.credentials.get({
+      publicKey: { ...options, challenge: fromBase64URL(options.challenge) },
+    })) as PublicKeyCredential | null;
+    if (!credential) {
+      return null;
+    }
+
+    const response = credential.response as AuthenticatorAssertionResponse;
+    return {
+      id: credential.id,
+      rawId: toBase64URL(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: toBase64URL(response.clientDataJSON),
+        authenticatorData: toBase64URL(response.authenticatorData),
+        signature: toBase64URL(response.signature),
+        userHandle: response.userHandle ? toBase64URL(response.userHandle) : "",
+      },
+    };
+  }
+
+  return { supported, createPasskey, getPasskey };
+}
@@ -0,0 +1,29 @@
+import dayjs from "dayjs";
+import { useI18n } from "vue-i18n";
+
+export type SuspendedResponse = {
+  error: string;
+  reason?: string;
+  suspended_until?: string;
+  banned: boolean;
+};
+
+export function useSuspension() {
+  const { t, locale } = useI18n({ useScope: "global" });
+
+  // Describes why signing in was refused, or returns undefined if it wasn't a suspension.
+  function suspensionMessage(body: Partial<SuspendedResponse>): string | undefined {
+    if (body.banned) {
+      return t("login.banned", { reason: body.reason ?? "" });
+    }
+    if (body.suspended_until) {
+      return t("login.suspended", {
+        until: dayjs(body.suspended_until).locale(locale.value).format("lll"),
+        reason: body.reason ?? "",
+      });
+    }
+    return undefined;
+  }
+
+  return { suspensionMessage };
+}
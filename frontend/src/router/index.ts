@@ -95,6 +95,21 @@ const router = createRouter({
       path: "/forgot",
       component: () => import("../pages/ForgotPasswordPage.vue"),
     },
+    {
+      name: "reset-password",
+      path: "/reset-password",
+      component: () => import("../pages/ResetPasswordPage.vue"),
+    },
+    {
+      name: "confirm-email",
+      path: "/confirm-email",
+      component: () => import("../pages/ConfirmEmailPage.vue"),
+    },
+    {
+      name: "oauth-google-callback",
+      path: "/oauth/google/callback",
+      component: () => import("../pages/GoogleCallbackPage.vue"),
+    },
     {
       name: "acknowledgements",
       path: "/acknowledgements",
@@ -124,6 +139,38 @@ const router = createRouter({
           path: "/admin/users",
           component: () => import("../pages/admin/AdminUsersPage.vue"),
         },
+        {
+          meta: {
+            requiresAuth: true,
+          },
+          name: "admin-user-dossier",
+          path: "/admin/users/:id",
+          component: () => import("../pages/admin/AdminUserDossierPage.vue"),
+        },
+        {
+          meta: {
+            requiresAuth: true,
+          },
+          name: "admin-audit",
+          path: "/admin/audit",
+          component: () => import("../pages/admin/AdminAuditPage.vue"),
+        },
+        {
+          meta: {
+            requiresAuth: true,
+          },
+          name: "admin-seller-requests",
+          path: "/admin/seller-requests",
+          component: () => import("../pages/admin/AdminSellerRequestsPage.vue"),
+        },
+        {
+          meta: {
+            requiresAuth: true,
+          },
+          name: "admin-roles",
+          path: "/admin/roles",
+          component: () => import("../pages/admin/AdminRolesPage.vue"),
+        },
       ],
     },
     {
@@ -10,11 +10,16 @@ export const useProfileStore = defineStore("profile", () => {
   const error = ref();
   const { authFetch } = useAuthFetch();
 
-  const isAdmin = computed(() => profile.value && profile.value.roles.includes("admin"));
+  // Anyone with a permission gets into the admin panel, but only sees what they can use.
+  const isAdmin = computed(() => (profile.value?.permissions?.length ?? 0) > 0);
   const isFetching = computed(() => loading.value);
   const hasProfile = computed(() => profile.value != undefined);
   const hasFetched = computed(() => error.value != undefined || profile.value != undefined);
 
+  function can(permission: string) {
+    return profile.value?.permissions?.includes(permission) ?? false;
+  }
+
   function setProfile(prof: Profile | undefined) {
     profile.value = prof;
   }
@@ -32,5 +37,5 @@ export const useProfileStore = defineStore("profile", () => {
     }
   };
 
-  return { profile, isAdmin, isFetching, setProfile, hasProfile, hasFetched, fetchProfile };
+  return { profile, isAdmin, can, isFetching, setProfile, hasProfile, hasFetched, fetchProfile };
 });
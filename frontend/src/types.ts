@@ -1,5 +1,28 @@
 export type Subscription = {
+  id: number;
+  plan: string;
+  source: "request" | "extension";
+  note?: string;
+  starts_at: string;
   expired_at: string;
+  created_at: string;
+};
+
+export type SubscriptionPlan = {
+  id: string;
+  name: string;
+  days: number;
+};
+
+export type SellerRequest = {
+  id: number;
+  user?: SmallUser;
+  message: string;
+  plan: string;
+  status: "pending" | "approved" | "rejected";
+  reason?: string;
+  reviewed_at?: string;
+  created_at: string;
 };
 
 export type Profile = {
@@ -10,10 +33,28 @@ export type Profile = {
   address?: string;
   verified: boolean;
   created_at: string;
-  average_rating: number;
-  waiting_approval: boolean;
+  positive_ratings: number;
+  negative_ratings: number;
+  reputation: number;
   roles: string[];
   subscription?: Subscription;
+  seller_request?: SellerRequest;
+  permissions?: string[];
+  suspended_until?: string;
+  banned?: boolean;
+  suspension_reason?: string;
+};
+
+export type Permission = {
+  id: string;
+  description: string;
+};
+
+export type Role = {
+  id: string;
+  description: string;
+  require_totp: boolean;
+  permissions: string[];
 };
 
 export type ProductImage = {
@@ -37,7 +78,9 @@ export interface SmallUser {
   name?: string;
   email?: string;
   avatar_url?: string;
-  average_rating: number;
+  positive_ratings: number;
+  negative_ratings: number;
+  reputation: number;
 }
 
 export type Question = {
@@ -124,3 +167,53 @@ export interface ChatMessage {
   image_url: string;
   sender: Profile;
 }
+
+export interface Session {
+  id: string;
+  user_agent: string;
+  ip_address: string;
+  signed_in_at?: string;
+  last_used_at?: string;
+  expired_at: string;
+  current: boolean;
+}
+
+export interface Passkey {
+  id: number;
+  name: string;
+  created_at: string;
+  last_used_at?: string;
+}
+
+export interface AuditLog {
+  id: number;
+  actor: SmallUser;
+  action: string;
+  target_user?: SmallUser;
+  reason?: string;
+  details?: Record<string, unknown>;
+  created_at: string;
+}
+
+export interface UserTransaction {
+  id: number;
+  product: { id: number; name: string; thumbnail_url: string };
+  counterparty: SmallUser;
+  final_price: number;
+  status: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface UserDossier {
+  user: Profile;
+  listings: { state: ProductState; data: Product[]; total: number }[];
+  bids: { state: ProductState; data: Product[]; total: number }[];
+  ratings: { data: Rating[]; total: number };
+  rated: { data: Rating[]; total: number };
+  purchases: { data: UserTransaction[]; total: number; total_amount: number };
+  sales: { data: UserTransaction[]; total: number; total_amount: number };
+  seller_requests: SellerRequest[];
+  subscriptions: Subscription[];
+  audit_logs: AuditLog[];
+}
@@ -3,3 +3,21 @@ declare const grecaptcha: {
   ready(cb: () => void): void;
   execute(siteKey: string, options: { action: string }): Promise<string>;
 };
+
+declare const hcaptcha: {
+  render(container: HTMLElement, options: { sitekey: string; size: "invisible" }): string;
+  execute(widget: string, options: { async: true }): Promise<{ response: string }>;
+  remove(widget: string): void;
+};
+
+declare const turnstile: {
+  render(
+    container: HTMLElement,
+    options: {
+      sitekey: string;
+      action: string;
+      callback: (token: string) => void;
+      "error-callback": (error: unknown) => void;
+    },
+  ): string;
+};
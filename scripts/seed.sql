@@ -46,9 +46,15 @@ INSERT INTO users (name, email, password, oauth_type, created_at, updated_at) VA
 
 INSERT INTO roles (id, description, created_at, updated_at) VALUES
   ('user', 'Default role for all users', now(), now()),
+  ('support', 'Customer support role', now(), now()),
   ('moderator', 'Moderation role', now(), now()),
   ('admin', 'Administrative role', now(), now());
 
+-- The server syncs the permissions and gives them all to admins on startup, restart it after this.
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+  ('support', 'users.read'), ('support', 'sellers.review'), ('support', 'subscriptions.write'), ('support', 'disputes.resolve'),
+  ('moderator', 'users.read'), ('moderator', 'users.ban'), ('moderator', 'disputes.resolve'), ('moderator', 'ratings.moderate');
+
 INSERT INTO user_roles VALUES (1, 'user'), (1, 'moderator'), (1, 'admin');
 INSERT INTO user_roles VALUES (2, 'user'), (2, 'moderator');
 INSERT INTO user_roles VALUES (3, 'user');